@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"movie-service/internal/app"
 	"movie-service/internal/config"
-	"movie-service/pkg/postgres"
 	"movie-service/pkg/sl"
 	"os"
 	"os/signal"
@@ -22,22 +21,31 @@ func main() {
 
 	log.Debug("example")
 
+	// Service binary doubles as migration tool: app migrate up|down|to|status|force
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(log, cfg, os.Args[2:]); err != nil {
+			log.Error("failed to migrate", sl.Err(err))
+			os.Exit(1)
+		}
+		return
+	}
+
 	log.Info(
 		"starting app",
 		slog.String("env", cfg.MovieService.Env),
 		slog.String("address", fmt.Sprintf("localhost:%d", cfg.MovieService.GRPCPort)),
 	)
 
-	log.Info("initializing connection to postgres")
-	db, err := postgres.New(cfg.Postgres)
+	log.Info("initializing storage", slog.String("storage", cfg.MovieService.Storage))
+	storage, err := app.NewStorage(log, cfg)
 	if err != nil {
-		log.Error("failed to connect to db(postgres)", sl.Err(err))
+		log.Error("failed to init storage", sl.Err(err))
 		os.Exit(1)
 	}
-	log.Info("connected to postgres")
+	log.Info("initialized storage")
 
 	log.Info("initializing app")
-	application, err := app.New(ctx, log, cfg.MovieService, db)
+	application, err := app.New(ctx, log, cfg, storage)
 	if err != nil {
 		log.Error("failed to init app", sl.Err(err))
 		os.Exit(1)
@@ -50,6 +58,10 @@ func main() {
 	log.Info("started app")
 	go application.GRPCServer.MustRun()
 	go application.GRPCGateway.MustRun()
+	if application.OutboxRelay != nil {
+		go application.OutboxRelay.Run()
+	}
+	go application.Dispatcher.Run()
 
 	<-stop
 	log.Info("stopping app")
@@ -60,8 +72,20 @@ func main() {
 	application.GRPCGateway.Stop()
 	log.Info("grpc gateway stopped")
 
-	postgres.MustClose(db)
-	log.Info("closed connection to postgres db")
+	if application.OutboxRelay != nil {
+		if err := application.OutboxRelay.Stop(); err != nil {
+			log.Error("failed to stop outbox relay", sl.Err(err))
+		}
+		log.Info("outbox relay stopped")
+	}
+
+	application.Dispatcher.Stop()
+	log.Info("webhook dispatcher stopped")
+
+	if err := application.Storage.Close(); err != nil {
+		log.Error("failed to close storage", sl.Err(err))
+	}
+	log.Info("closed storage")
 
 	log.Info("stopped app")
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"movie-service/internal/config"
+	"movie-service/migrations"
+	"movie-service/pkg/postgres"
+	"os"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+const migrateUsage = "usage: app migrate up|down|to <version>|status|force <version>"
+
+// runMigrate runs migrate command:
+//
+//	up                applies all migrations which are not applied yet
+//	down              rolls back the last applied migration
+//	to <version>      migrates up or down to the version
+//	status            shows applied version of schema
+//	force <version>   sets version without running migrations, it's used to
+//	                  clean dirty state after failed migration is fixed by hand
+func runMigrate(log *slog.Logger, cfg *config.Config, args []string) error {
+	if cfg.MovieService.Storage != config.StoragePostgres {
+		return fmt.Errorf("migrate command supports postgres storage only, got %q", cfg.MovieService.Storage)
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// Number of arguments of every subcommand
+	argc := map[string]int{"up": 0, "down": 0, "to": 1, "status": 0, "force": 1}
+	cmd, args := args[0], args[1:]
+	if n, ok := argc[cmd]; !ok || len(args) != n {
+		return errors.New(migrateUsage)
+	}
+
+	// Version of force may be -1, which means no migration is applied
+	var version int
+	if len(args) == 1 {
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v < -1 || (cmd == "to" && v < 0) {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		version = v
+	}
+
+	m, err := postgres.NewMigrate(cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch cmd {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Steps(-1)
+	case "to":
+		err = m.Migrate(uint(version))
+	case "force":
+		err = m.Force(version)
+	}
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Info("schema is up to date")
+	} else if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", cmd, err)
+	}
+
+	return logStatus(log, m)
+}
+
+// logStatus logs applied version of schema along with the latest one
+func logStatus(log *slog.Logger, m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	latest, err := latestVersion()
+	if err != nil {
+		return err
+	}
+
+	log.Info("schema status",
+		slog.Uint64("version", uint64(version)),
+		slog.Uint64("latest", uint64(latest)),
+		slog.Bool("dirty", dirty),
+	)
+
+	return nil
+}
+
+// latestVersion returns version of the last migration embedded into the binary
+func latestVersion() (uint, error) {
+	src, err := iofs.New(migrations.Postgres, ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read embedded migrations: %w", err)
+		}
+		version = next
+	}
+}
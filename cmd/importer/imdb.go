@@ -0,0 +1,155 @@
+package main
+
+import (
+	"log/slog"
+	"movie-service/internal/transport/dto"
+	"strconv"
+	"strings"
+)
+
+// imdbMovie is a movie from title.basics
+type imdbMovie struct {
+	tconst   string
+	title    string
+	genre    string
+	year     uint32
+	director string
+}
+
+// dataset joins movies of title.basics with their directors. Only the
+// join is kept in memory, movies themselves are streamed by eachMovie.
+type dataset struct {
+	log *slog.Logger
+
+	// directors maps tconst of movie to nconst of its first director
+	directors map[string]string
+	// names maps nconst of director to the name
+	names map[string]string
+	// movies is number of movies in title.basics
+	movies int
+}
+
+// parseMovie returns movie from row of title.basics, or false if the title
+// is not a movie. Only the first genre is kept, since movie has single genre.
+func parseMovie(row map[string]string) (*imdbMovie, bool) {
+	if row["titleType"] != "movie" || row["isAdult"] == "1" {
+		return nil, false
+	}
+
+	year, err := strconv.ParseUint(row["startYear"], 10, 32)
+	if err != nil {
+		return nil, false
+	}
+
+	genre, _, _ := strings.Cut(row["genres"], ",")
+
+	return &imdbMovie{
+		tconst: row["tconst"],
+		title:  row["primaryTitle"],
+		genre:  genre,
+		year:   uint32(year),
+	}, true
+}
+
+// readBasics finds movies, which directors are looked up for
+func (d *dataset) readBasics(path string) error {
+	d.directors = make(map[string]string)
+
+	rows := 0
+	err := readTSV(path, func(row map[string]string) error {
+		rows++
+		if movie, ok := parseMovie(row); ok {
+			d.directors[movie.tconst] = ""
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	d.movies = len(d.directors)
+
+	d.log.Info("read titles", slog.Int("titles", rows), slog.Int("movies", d.movies))
+
+	return nil
+}
+
+// readCrew finds the first director of every movie. Movies without
+// director are forgotten, since they can't be loaded anyway.
+func (d *dataset) readCrew(path string) error {
+	err := readTSV(path, func(row map[string]string) error {
+		if _, ok := d.directors[row["tconst"]]; !ok {
+			return nil
+		}
+
+		if nconst, _, _ := strings.Cut(row["directors"], ","); nconst != "" {
+			d.directors[row["tconst"]] = nconst
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for tconst, nconst := range d.directors {
+		if nconst == "" {
+			delete(d.directors, tconst)
+		}
+	}
+
+	d.log.Info("read crews", slog.Int("movies_with_director", len(d.directors)))
+
+	return nil
+}
+
+// readNames finds names of directors
+func (d *dataset) readNames(path string) error {
+	d.names = make(map[string]string)
+	for _, nconst := range d.directors {
+		d.names[nconst] = ""
+	}
+
+	resolved := 0
+	err := readTSV(path, func(row map[string]string) error {
+		if _, ok := d.names[row["nconst"]]; !ok {
+			return nil
+		}
+
+		d.names[row["nconst"]] = row["primaryName"]
+		resolved++
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Directors which are missing from names are left unknown
+	d.log.Info("read names", slog.Int("directors", resolved), slog.Int("unknown_directors", len(d.names)-resolved))
+
+	return nil
+}
+
+// eachMovie reads title.basics again and calls fn for every movie in order
+// of the file, with director resolved
+func (d *dataset) eachMovie(path string, fn func(movie *imdbMovie) error) error {
+	return readTSV(path, func(row map[string]string) error {
+		movie, ok := parseMovie(row)
+		if !ok {
+			return nil
+		}
+		movie.director = d.names[d.directors[movie.tconst]]
+
+		return fn(movie)
+	})
+}
+
+func (m *imdbMovie) toRequest() *dto.CreateMovieRequest {
+	return &dto.CreateMovieRequest{
+		Title:    m.title,
+		Genre:    m.genre,
+		Director: m.director,
+		Year:     m.year,
+	}
+}
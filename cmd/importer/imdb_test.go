@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestParseMovie(t *testing.T) {
+	movie := func(titleType, isAdult, startYear, genres string) map[string]string {
+		return map[string]string{
+			"tconst":       "tt0078748",
+			"titleType":    titleType,
+			"primaryTitle": "Alien",
+			"isAdult":      isAdult,
+			"startYear":    startYear,
+			"genres":       genres,
+		}
+	}
+
+	tests := []struct {
+		name string
+		row  map[string]string
+		want *imdbMovie
+	}{
+		{
+			name: "first genre is kept",
+			row:  movie("movie", "0", "1979", "Horror,Sci-Fi"),
+			want: &imdbMovie{tconst: "tt0078748", title: "Alien", genre: "Horror", year: 1979},
+		},
+		{
+			name: "missing genres",
+			row:  movie("movie", "0", "1979", ""),
+			want: &imdbMovie{tconst: "tt0078748", title: "Alien", year: 1979},
+		},
+		{name: "not a movie", row: movie("tvSeries", "0", "1979", "Horror")},
+		{name: "adult", row: movie("movie", "1", "1979", "Horror")},
+		{name: "missing year", row: movie("movie", "0", "", "Horror")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseMovie(tt.row)
+			if tt.want == nil {
+				if ok {
+					t.Fatalf("parseMovie = %+v, want no movie", got)
+				}
+				return
+			}
+
+			if !ok || *got != *tt.want {
+				t.Errorf("parseMovie = %+v, %v, want %+v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataset(t *testing.T) {
+	basics := writeFile(t, "title.basics.tsv.gz",
+		"tconst\ttitleType\tprimaryTitle\tisAdult\tstartYear\tgenres\n"+
+			"tt0078748\tmovie\tAlien\t0\t1979\tHorror,Sci-Fi\n"+
+			"tt0090605\tmovie\tAliens\t0\t1986\tAction,Adventure\n"+
+			"tt0108778\ttvSeries\tFriends\t0\t1994\tComedy\n"+
+			"tt0103644\tmovie\tAlien 3\t0\t1992\t\\N\n"+
+			"tt0118583\tmovie\tAlien Resurrection\t0\t1997\tAction\n"+
+			"tt0000001\tmovie\tNo Crew\t0\t1920\tDrama\n")
+	crew := writeFile(t, "title.crew.tsv",
+		"tconst\tdirectors\twriters\n"+
+			"tt0078748\tnm0000631\t\\N\n"+
+			"tt0090605\tnm0000116,nm0000117\t\\N\n"+
+			"tt0108778\tnm0000001\t\\N\n"+
+			"tt0103644\tnm0000399\t\\N\n"+
+			"tt0118583\tnm0000999\t\\N\n")
+	names := writeFile(t, "name.basics.tsv",
+		"nconst\tprimaryName\n"+
+			"nm0000001\tSomebody\n"+
+			"nm0000116\tJames Cameron\n"+
+			"nm0000117\tSomebody Else\n"+
+			"nm0000399\tDavid Fincher\n"+
+			"nm0000631\tRidley Scott\n")
+
+	d := &dataset{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	if err := d.readBasics(basics); err != nil {
+		t.Fatalf("readBasics error = %v", err)
+	}
+	if err := d.readCrew(crew); err != nil {
+		t.Fatalf("readCrew error = %v", err)
+	}
+	if err := d.readNames(names); err != nil {
+		t.Fatalf("readNames error = %v", err)
+	}
+
+	if d.movies != 5 {
+		t.Errorf("movies = %d, want 5", d.movies)
+	}
+
+	var movies []imdbMovie
+	err := d.eachMovie(basics, func(movie *imdbMovie) error {
+		movies = append(movies, *movie)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("eachMovie error = %v", err)
+	}
+
+	// Directors are resolved to the first one, missing ones are left unknown
+	want := []imdbMovie{
+		{tconst: "tt0078748", title: "Alien", genre: "Horror", year: 1979, director: "Ridley Scott"},
+		{tconst: "tt0090605", title: "Aliens", genre: "Action", year: 1986, director: "James Cameron"},
+		{tconst: "tt0103644", title: "Alien 3", year: 1992, director: "David Fincher"},
+		{tconst: "tt0118583", title: "Alien Resurrection", genre: "Action", year: 1997},
+		{tconst: "tt0000001", title: "No Crew", genre: "Drama", year: 1920},
+	}
+	if !slices.Equal(movies, want) {
+		t.Errorf("eachMovie movies = %+v, want %+v", movies, want)
+	}
+}
@@ -0,0 +1,207 @@
+// Command importer loads movies from IMDb dataset dumps (title.basics.tsv,
+// title.crew.tsv and name.basics.tsv, optionally gzipped) into the database.
+// Progress is saved in the database along with every batch, so rerunning the
+// command with the same files continues interrupted import.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"movie-service/internal/app"
+	"movie-service/internal/config"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository/postgres"
+	"movie-service/pkg/postgres"
+	"movie-service/pkg/sl"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// importSource is the name progress of import is saved under
+const importSource = "imdb"
+
+func main() {
+	basicsPath := flag.String("basics", "title.basics.tsv", "path to title.basics.tsv")
+	crewPath := flag.String("crew", "title.crew.tsv", "path to title.crew.tsv")
+	namesPath := flag.String("names", "name.basics.tsv", "path to name.basics.tsv")
+	batchSize := flag.Int("batch", 1000, "number of movies created at once")
+	fanOut := flag.Bool("fanout", false, "put events of imported movies to the outbox and deliver them to webhooks")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.MustLoad()
+	log := app.SetupLogger(cfg.MovieService.Env)
+
+	if err := run(ctx, log, cfg, *basicsPath, *crewPath, *namesPath, *batchSize, *fanOut); err != nil {
+		log.Error("import failed", sl.Err(err))
+		os.Exit(1)
+	}
+}
+
+func run(
+	ctx context.Context,
+	log *slog.Logger,
+	cfg *config.Config,
+	basicsPath, crewPath, namesPath string,
+	batchSize int,
+	fanOut bool,
+) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	if cfg.MovieService.Storage != config.StoragePostgres {
+		return fmt.Errorf("importer supports postgres storage only, got %q", cfg.MovieService.Storage)
+	}
+
+	// Join the dataset
+	d := &dataset{log: log}
+	if err := d.readBasics(basicsPath); err != nil {
+		return err
+	}
+	if err := d.readCrew(crewPath); err != nil {
+		return err
+	}
+	if err := d.readNames(namesPath); err != nil {
+		return err
+	}
+
+	log.Info("connecting to postgres")
+	db, err := postgres.New(cfg.Postgres, log)
+	if err != nil {
+		return fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	defer postgres.MustClose(db)
+
+	// Movie events are recorded anyway, but by default a bulk load doesn't
+	// fan them out to the outbox and webhooks event by event
+	opts := []repo.Option{
+		repo.WithCreateBatch(cfg.Postgres.CreateBatch),
+		repo.WithCopyThreshold(cfg.Postgres.CopyThreshold),
+	}
+	if !fanOut || cfg.Outbox.Disabled() {
+		opts = append(opts, repo.WithoutOutbox())
+	}
+	if !fanOut {
+		opts = append(opts, repo.WithoutWebhooks())
+	}
+	movieRepo := repo.New(db, opts...)
+
+	progress, err := movieRepo.GetImportProgress(ctx, importSource)
+	if err != nil {
+		return err
+	}
+	if progress.LastID != "" {
+		log.Info("resuming import", slog.Int("loaded", progress.Loaded), slog.String("last_id", progress.LastID))
+	}
+
+	l := &loader{
+		log:       log,
+		repo:      movieRepo,
+		validate:  validator.New(validator.WithRequiredStructEnabled()),
+		progress:  progress,
+		skipping:  progress.LastID != "",
+		total:     d.movies,
+		batchSize: batchSize,
+		began:     time.Now(),
+	}
+	err = d.eachMovie(basicsPath, func(movie *imdbMovie) error {
+		return l.add(ctx, movie)
+	})
+	if err == nil {
+		err = l.flush(ctx)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Info("import interrupted, rerun to continue", slog.Int("loaded", l.progress.Loaded))
+			return nil
+		}
+
+		return err
+	}
+
+	if l.skipping {
+		return fmt.Errorf("movie %s from saved progress is not found in dataset", progress.LastID)
+	}
+
+	log.Info("import finished", slog.Int("loaded", l.progress.Loaded))
+
+	return nil
+}
+
+// loader loads streamed movies by batches. Movies loaded by previous runs
+// are skipped until the last of them is met.
+type loader struct {
+	log      *slog.Logger
+	repo     *repo.Repository
+	validate *validator.Validate
+	progress *model.ImportProgress
+	skipping bool
+
+	// total and seen are numbers of movies in the dataset and read so far
+	total, seen int
+	batchSize   int
+	batch       []*imdbMovie
+	began       time.Time
+}
+
+func (l *loader) add(ctx context.Context, movie *imdbMovie) error {
+	l.seen++
+	if l.skipping {
+		l.skipping = movie.tconst != l.progress.LastID
+		return nil
+	}
+
+	if l.validate.Struct(movie.toRequest()) != nil {
+		return nil
+	}
+
+	l.batch = append(l.batch, movie)
+	if len(l.batch) < l.batchSize {
+		return nil
+	}
+
+	return l.flush(ctx)
+}
+
+// flush loads the batch and saves progress in the same transaction
+func (l *loader) flush(ctx context.Context) error {
+	if len(l.batch) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	models := make([]model.Movie, 0, len(l.batch))
+	for _, movie := range l.batch {
+		models = append(models, *movie.toRequest().ToModel())
+	}
+
+	first, last := l.batch[0].tconst, l.batch[len(l.batch)-1].tconst
+	progress := &model.ImportProgress{
+		Source: importSource,
+		Loaded: l.progress.Loaded + len(l.batch),
+		LastID: last,
+	}
+	if _, err := l.repo.ImportMovies(ctx, models, progress); err != nil {
+		return fmt.Errorf("failed to load movies %s..%s: %w", first, last, err)
+	}
+	l.progress = progress
+	l.batch = l.batch[:0]
+
+	l.log.Info("loaded batch",
+		slog.Int("loaded", l.progress.Loaded),
+		slog.String("progress", fmt.Sprintf("%.1f%%", float64(l.seen)*100/float64(max(l.total, 1)))),
+		slog.Duration("elapsed", time.Since(l.began).Round(time.Second)),
+	)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// tsvNull is a marker of missing value in IMDb dumps
+	tsvNull = `\N`
+
+	maxTSVLine = 1 << 20
+)
+
+// readTSV calls fn for every row of the TSV file with header, passing values
+// by column name. Files ending with .gz are decompressed on the fly.
+// IMDb dumps don't quote values, so rows are simply split by tabs.
+func readTSV(path string, fn func(row map[string]string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxTSVLine)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read header of %s: %w", path, err)
+		}
+
+		return fmt.Errorf("%s is empty", path)
+	}
+	header := strings.Split(scanner.Text(), "\t")
+
+	row := make(map[string]string, len(header))
+	for line := 2; scanner.Scan(); line++ {
+		values := strings.Split(scanner.Text(), "\t")
+		if len(values) != len(header) {
+			return fmt.Errorf("%s:%d: expected %d values, got %d", path, line, len(header), len(values))
+		}
+
+		for i, name := range header {
+			if values[i] == tsvNull {
+				row[name] = ""
+			} else {
+				row[name] = values[i]
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFile writes data to file in temporary directory, gzipped if name
+// ends with .gz
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(name, ".gz") {
+		gz := gzip.NewWriter(f)
+		defer gz.Close()
+		_, err = gz.Write([]byte(data))
+	} else {
+		_, err = f.WriteString(data)
+	}
+	if err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+
+	return path
+}
+
+func TestReadTSV(t *testing.T) {
+	const data = "tconst\tprimaryTitle\tstartYear\n" +
+		"tt0078748\tAlien\t1979\n" +
+		"tt0000001\t\\N\t\\N\n"
+
+	want := []map[string]string{
+		{"tconst": "tt0078748", "primaryTitle": "Alien", "startYear": "1979"},
+		{"tconst": "tt0000001", "primaryTitle": "", "startYear": ""},
+	}
+
+	for _, name := range []string{"title.basics.tsv", "title.basics.tsv.gz"} {
+		t.Run(name, func(t *testing.T) {
+			var rows []map[string]string
+			err := readTSV(writeFile(t, name, data), func(row map[string]string) error {
+				rows = append(rows, maps.Clone(row))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("readTSV error = %v", err)
+			}
+
+			if !slices.EqualFunc(rows, want, maps.Equal) {
+				t.Errorf("readTSV rows = %v, want %v", rows, want)
+			}
+		})
+	}
+}
+
+func TestReadTSVErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "empty file", data: "", wantErr: "is empty"},
+		{name: "missing values", data: "tconst\tprimaryTitle\ntt0078748\n", wantErr: ":2: expected 2 values, got 1"},
+		{name: "extra values", data: "tconst\ntt0078748\n\\N\tAlien\n", wantErr: ":3: expected 1 values, got 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readTSV(writeFile(t, "data.tsv", tt.data), func(row map[string]string) error { return nil })
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("readTSV error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadTSVStopsOnError(t *testing.T) {
+	errStop := errors.New("stop")
+
+	rows := 0
+	err := readTSV(writeFile(t, "data.tsv", "tconst\ntt1\ntt2\n"), func(row map[string]string) error {
+		rows++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || rows != 1 {
+		t.Fatalf("readTSV error = %v after %d rows, want %v after 1 row", err, rows, errStop)
+	}
+}
@@ -2,15 +2,18 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	grpcapp "movie-service/internal/app/grpc"
 	"movie-service/internal/app/grpcgateway"
 	"movie-service/internal/config"
-	repo "movie-service/internal/repository/postgres"
+	"movie-service/internal/outbox"
 	"movie-service/internal/service/movieservice"
-
-	"github.com/jmoiron/sqlx"
+	"movie-service/internal/service/watchlistservice"
+	"movie-service/internal/service/webhookservice"
+	"net/http"
+	"os"
 )
 
 type App struct {
@@ -18,26 +21,85 @@ type App struct {
 	log         *slog.Logger
 	GRPCServer  *grpcapp.App
 	GRPCGateway *grpcgateway.Gateway
-	DB          *sqlx.DB
+	Storage     *Storage
+	// OutboxRelay is nil if outbox sink is not configured and movie events
+	// are kept forever
+	OutboxRelay *outbox.Relay
+	Dispatcher  *webhookservice.Dispatcher
 }
 
-func New(ctx context.Context, log *slog.Logger, cfg config.MovieService, db *sqlx.DB) (*App, error) {
+func New(ctx context.Context, log *slog.Logger, cfg *config.Config, storage *Storage) (*App, error) {
 	const op = "app.New"
 
-	movieRepo := repo.New(db)
-	movieService := movieservice.New(movieRepo)
+	movieRepo, notifier := storage.Repo, storage.Notifier
+	movieService := movieservice.New(movieRepo, notifier)
+	watchlistService := watchlistservice.New(movieRepo)
+	webhookService := webhookservice.New(movieRepo)
 
-	grpcApp := grpcapp.New(ctx, log, movieService, cfg.GRPCPort)
-	grpcGateway, err := grpcgateway.New(ctx, log, cfg.HTTPPort, cfg.GRPCPort)
+	grpcApp := grpcapp.New(ctx, log, movieService, watchlistService, webhookService, cfg.MovieService.GRPCPort, storage.Session)
+	grpcGateway, err := grpcgateway.New(ctx, log, cfg.MovieService.HTTPPort, cfg.MovieService.GRPCPort)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to create grpc gateway server: %w", op, err)
 	}
 
+	var relay *outbox.Relay
+	sink, err := newOutboxSink(cfg.Outbox)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create outbox sink: %w", op, err)
+	}
+	// Relay without sink is still needed to purge movie events
+	if sink != nil || cfg.Outbox.EventRetention > 0 {
+		relay = outbox.NewRelay(log, movieRepo, notifier, sink, outbox.Config{
+			BatchSize:      cfg.Outbox.BatchSize,
+			PollInterval:   cfg.Outbox.PollInterval,
+			Lease:          cfg.Outbox.Lease,
+			MinBackoff:     cfg.Outbox.MinBackoff,
+			MaxBackoff:     cfg.Outbox.MaxBackoff,
+			Retention:      cfg.Outbox.Retention,
+			EventRetention: cfg.Outbox.EventRetention,
+		})
+	}
+
+	dispatcher := webhookservice.NewDispatcher(log, movieRepo, notifier,
+		&http.Client{Timeout: cfg.Webhooks.Timeout},
+		webhookservice.DispatcherConfig{
+			BatchSize:    cfg.Webhooks.BatchSize,
+			PollInterval: cfg.Webhooks.PollInterval,
+			Lease:        cfg.Webhooks.Lease,
+			MinBackoff:   cfg.Webhooks.MinBackoff,
+			MaxBackoff:   cfg.Webhooks.MaxBackoff,
+			MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		},
+	)
+
 	return &App{
 		ctx:         ctx,
 		log:         log,
 		GRPCServer:  grpcApp,
 		GRPCGateway: grpcGateway,
-		DB:          db,
+		Storage:     storage,
+		OutboxRelay: relay,
+		Dispatcher:  dispatcher,
 	}, nil
 }
+
+// newOutboxSink creates sink of outbox events by config. Nil sink means
+// events aren't relayed anywhere.
+func newOutboxSink(cfg config.Outbox) (outbox.Sink, error) {
+	switch cfg.Sink {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		return outbox.NewWriterSink(os.Stdout), nil
+	case "file":
+		return outbox.NewFileSink(cfg.FilePath)
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, errors.New("webhook url is not set")
+		}
+
+		return outbox.NewWebhookSink(cfg.WebhookURL, cfg.WebhookTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown sink %q", cfg.Sink)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
+const maxRecvMsgSize = 64 << 20
+
 type App struct {
 	ctx        context.Context
 	log        *slog.Logger
@@ -19,9 +21,24 @@ type App struct {
 	port       uint16
 }
 
-func New(ctx context.Context, log *slog.Logger, movieService moviegrpc.Service, port uint16) *App {
+func New(
+	ctx context.Context,
+	log *slog.Logger,
+	movieService moviegrpc.Service,
+	watchlistService moviegrpc.WatchlistService,
+	webhookService moviegrpc.WebhookService,
+	port uint16,
+	session func(ctx context.Context) context.Context,
+) *App {
+	// Requests need no session if storage doesn't ask for it
+	if session == nil {
+		session = func(ctx context.Context) context.Context { return ctx }
+	}
+
 	gRPCServer := grpc.NewServer(
-		grpc.UnaryInterceptor(
+		// Leave room for files uploaded for import
+		grpc.MaxRecvMsgSize(maxRecvMsgSize),
+		grpc.ChainUnaryInterceptor(
 			func(
 				ctx context.Context,
 				req any,
@@ -30,8 +47,10 @@ func New(ctx context.Context, log *slog.Logger, movieService moviegrpc.Service,
 			) (resp any, err error) {
 				return moviegrpc.LoggingUnaryInterceptor(log)(ctx, req, info, handler)
 			},
+			moviegrpc.ContextUnaryInterceptor(),
+			moviegrpc.SessionUnaryInterceptor(session),
 		),
-		grpc.StreamInterceptor(
+		grpc.ChainStreamInterceptor(
 			func(
 				srv any, 
 				ss grpc.ServerStream, 
@@ -40,12 +59,20 @@ func New(ctx context.Context, log *slog.Logger, movieService moviegrpc.Service,
 			) error {
 				return moviegrpc.LoggingStreamInterceptor(log)(srv, ss, info, handler)
 			},
+			moviegrpc.ContextStreamInterceptor(),
+			moviegrpc.SessionStreamInterceptor(session),
 		),
 	)
 
 	// Register movie service
 	moviegrpc.Register(gRPCServer, log, movieService)
 
+	// Register watchlist service
+	moviegrpc.RegisterWatchlist(gRPCServer, log, watchlistService)
+
+	// Register webhook service
+	moviegrpc.RegisterWebhook(gRPCServer, log, webhookService)
+
 	// Register health check service
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(gRPCServer, healthServer)
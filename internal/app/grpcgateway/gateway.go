@@ -25,15 +25,32 @@ type Gateway struct {
 func New(ctx context.Context, log *slog.Logger, httpPort, grpcPort uint16) (*Gateway, error) {
 	const op = "grpcgateway.New"
 
-	mux := runtime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
-	err := pb.RegisterMovieServiceHandlerFromEndpoint(
-		ctx, mux, fmt.Sprintf("localhost:%d", grpcPort), opts,
+	mux := runtime.NewServeMux(
+		// Files for import are uploaded as raw body
+		runtime.WithMarshalerOption("text/csv", newRawBodyMarshaler("text/csv")),
+		runtime.WithMarshalerOption("application/x-ndjson", newRawBodyMarshaler("application/x-ndjson")),
 	)
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(maxRawBodySize + 1<<20)),
+	}
+	endpoint := fmt.Sprintf("localhost:%d", grpcPort)
+
+	err := pb.RegisterMovieServiceHandlerFromEndpoint(ctx, mux, endpoint, opts)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to register handler for grpc gateway: %w", op, err)
 	}
 
+	err = pb.RegisterWatchlistServiceHandlerFromEndpoint(ctx, mux, endpoint, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to register watchlist handler for grpc gateway: %w", op, err)
+	}
+
+	err = pb.RegisterWebhookServiceHandlerFromEndpoint(ctx, mux, endpoint, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to register webhook handler for grpc gateway: %w", op, err)
+	}
+
 	return &Gateway{
 		ctx: ctx,
 		httpServer: &http.Server{
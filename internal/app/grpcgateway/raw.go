@@ -0,0 +1,65 @@
+package grpcgateway
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// maxRawBodySize limits size of files uploaded as raw request body
+const maxRawBodySize = 32 << 20
+
+// rawBodyMarshaler passes request body as is into google.api.HttpBody field
+// of the request, so that gRPC handler gets the file together with its content type.
+// Responses are encoded by the default marshaler.
+type rawBodyMarshaler struct {
+	runtime.Marshaler
+	contentType string
+}
+
+func newRawBodyMarshaler(contentType string) *rawBodyMarshaler {
+	return &rawBodyMarshaler{
+		// Same as default marshaler of the gateway
+		Marshaler: &runtime.HTTPBodyMarshaler{
+			Marshaler: &runtime.JSONPb{
+				MarshalOptions:   protojson.MarshalOptions{EmitUnpopulated: true},
+				UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+			},
+		},
+		contentType: contentType,
+	}
+}
+
+func (m *rawBodyMarshaler) NewDecoder(r io.Reader) runtime.Decoder {
+	return runtime.DecoderFunc(func(v any) error {
+		var body *httpbody.HttpBody
+		switch dst := v.(type) {
+		case *httpbody.HttpBody:
+			body = dst
+		case **httpbody.HttpBody:
+			if *dst == nil {
+				*dst = &httpbody.HttpBody{}
+			}
+			body = *dst
+		default:
+			return fmt.Errorf("%s body is not accepted by this method", m.contentType)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(r, maxRawBodySize+1))
+		if err != nil {
+			return fmt.Errorf("failed to read body: %w", err)
+		}
+		if len(data) > maxRawBodySize {
+			return errors.New("body is too large")
+		}
+
+		body.ContentType = m.contentType
+		body.Data = data
+
+		return nil
+	})
+}
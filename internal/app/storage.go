@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"movie-service/internal/config"
+	"movie-service/internal/repository"
+	memoryrepo "movie-service/internal/repository/memory"
+	postgresrepo "movie-service/internal/repository/postgres"
+	sqliterepo "movie-service/internal/repository/sqlite"
+	"movie-service/pkg/postgres"
+	"movie-service/pkg/sqlite"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Storage is a repository of the service along with notifier about its movie events
+type Storage struct {
+	Repo     repository.Repository
+	Notifier repository.Notifier
+	// DB is nil for memory storage
+	DB *sqlx.DB
+	// Replicas is nil unless postgres has read replicas
+	Replicas *postgres.Replicas
+	// Session prepares context of request for the storage, nil if it's not needed
+	Session func(ctx context.Context) context.Context
+}
+
+// NewStorage opens storage chosen by config
+func NewStorage(log *slog.Logger, cfg *config.Config) (*Storage, error) {
+	const op = "app.NewStorage"
+
+	switch cfg.MovieService.Storage {
+	case config.StorageMemory:
+		var opts []memoryrepo.Option
+		if cfg.Outbox.Disabled() {
+			opts = append(opts, memoryrepo.WithoutOutbox())
+		}
+		repo := memoryrepo.New(opts...)
+
+		return &Storage{Repo: repo, Notifier: repo.Notifier()}, nil
+	case config.StoragePostgres:
+		db, err := postgres.New(cfg.Postgres, log)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to connect to postgres: %w", op, err)
+		}
+
+		notifier, err := postgresrepo.NewNotifier(postgres.NewListener(cfg.Postgres, log))
+		if err != nil {
+			postgres.MustClose(db)
+			return nil, fmt.Errorf("%s: failed to create movie events notifier: %w", op, err)
+		}
+
+		st := &Storage{Notifier: notifier, DB: db}
+		opts := []postgresrepo.Option{
+			postgresrepo.WithCreateBatch(cfg.Postgres.CreateBatch),
+			postgresrepo.WithCopyThreshold(cfg.Postgres.CopyThreshold),
+		}
+		if cfg.Outbox.Disabled() {
+			opts = append(opts, postgresrepo.WithoutOutbox())
+		}
+		if len(cfg.Postgres.Replicas) > 0 {
+			st.Replicas, err = postgres.NewReplicas(cfg.Postgres, log)
+			if err != nil {
+				notifier.Close()
+				postgres.MustClose(db)
+				return nil, fmt.Errorf("%s: failed to connect to postgres replicas: %w", op, err)
+			}
+			opts = append(opts, postgresrepo.WithReplicas(st.Replicas))
+
+			if cfg.Postgres.ReadYourWrites {
+				st.Session = postgresrepo.WithSession
+			}
+		}
+		st.Repo = postgresrepo.New(db, opts...)
+
+		return st, nil
+	case config.StorageSQLite:
+		db, err := sqlite.New(cfg.SQLite)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to open sqlite database: %w", op, err)
+		}
+		var opts []sqliterepo.Option
+		if cfg.Outbox.Disabled() {
+			opts = append(opts, sqliterepo.WithoutOutbox())
+		}
+		repo := sqliterepo.New(db, opts...)
+
+		return &Storage{Repo: repo, Notifier: repo.Notifier(), DB: db}, nil
+	default:
+		return nil, fmt.Errorf("%s: unknown storage %q", op, cfg.MovieService.Storage)
+	}
+}
+
+// Close closes notifier and connection to the database
+func (s *Storage) Close() error {
+	const op = "app.Storage.Close"
+
+	var errs []error
+	if err := s.Notifier.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close movie events notifier: %w", err))
+	}
+
+	if s.Replicas != nil {
+		if err := s.Replicas.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close connections to replicas: %w", err))
+		}
+	}
+
+	if s.DB != nil {
+		if err := s.DB.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close connection to database: %w", err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -10,20 +11,116 @@ import (
 type Config struct {
 	MovieService MovieService `yaml:"movie_service"`
 	Postgres     Postgres     `yaml:"postgres"`
+	SQLite       SQLite       `yaml:"sqlite"`
+	Outbox       Outbox       `yaml:"outbox"`
+	Webhooks     Webhooks     `yaml:"webhooks"`
 }
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
+)
+
 type MovieService struct {
 	Env      string `yaml:"env" env:"ENV" env-default:"prod"`
 	HTTPPort uint16 `yaml:"http_port" env:"HTTP_PORT" env-default:"8088"`
 	GRPCPort uint16 `yaml:"grpc_port" env:"GRPC_PORT" env-default:"50051"`
+	// Storage is one of postgres, sqlite or memory. Memory storage loses all
+	// data on restart and is meant for local development and tests.
+	Storage string `yaml:"storage" env:"STORAGE" env-default:"postgres"`
 }
 
+// Postgres is used if storage is postgres. Username and password are
+// required then.
 type Postgres struct {
 	Host     string `env:"POSTGRES_HOST" env-default:"localhost"`
 	Port     uint16 `env:"POSTGRES_PORT" env-default:"5432"`
-	Username string `env:"POSTGRES_USER" env-required:"true"`
-	Password string `env:"POSTGRES_PASSWORD" env-required:"true"`
+	Username string `env:"POSTGRES_USER"`
+	Password string `env:"POSTGRES_PASSWORD"`
 	Database string `env:"POSTGRES_DB" env-default:"postgres"`
+	// SSLMode is one of sslmode values of libpq. SSLRootCert is a file of
+	// certificate authority checking certificate of server with verify-ca
+	// and verify-full modes.
+	SSLMode     string `env:"POSTGRES_SSLMODE" env-default:"disable"`
+	SSLRootCert string `env:"POSTGRES_SSLROOTCERT"`
+	// StatementTimeout aborts statements running longer, zero means no limit
+	StatementTimeout time.Duration `env:"POSTGRES_STATEMENT_TIMEOUT" env-default:"0s"`
+	ApplicationName  string        `env:"POSTGRES_APPLICATION_NAME" env-default:"movie-service"`
+	// Connection pool, zero values mean no limit
+	MaxOpenConns    int           `env:"POSTGRES_MAX_OPEN_CONNS" env-default:"25"`
+	MaxIdleConns    int           `env:"POSTGRES_MAX_IDLE_CONNS" env-default:"25"`
+	ConnMaxLifetime time.Duration `env:"POSTGRES_CONN_MAX_LIFETIME" env-default:"30m"`
+	ConnMaxIdleTime time.Duration `env:"POSTGRES_CONN_MAX_IDLE_TIME" env-default:"5m"`
+	// ConnectRetries is how many times connection is retried at startup.
+	// Pause between attempts starts from ConnectBackoff and doubles each time.
+	ConnectRetries int           `env:"POSTGRES_CONNECT_RETRIES" env-default:"10"`
+	ConnectBackoff time.Duration `env:"POSTGRES_CONNECT_BACKOFF" env-default:"1s"`
+	// AutoMigrate applies migrations on startup. Turned off, migrations are
+	// run with migrate command of the service before it's started.
+	AutoMigrate bool `env:"POSTGRES_AUTO_MIGRATE" env-default:"true"`
+	// MigrateLockTimeout is how long migrations wait for each other
+	MigrateLockTimeout time.Duration `env:"POSTGRES_MIGRATE_LOCK_TIMEOUT" env-default:"5m"`
+	// CreateBatch is a number of movies inserted by a single statement when
+	// many movies are created at once
+	CreateBatch int `env:"POSTGRES_CREATE_BATCH" env-default:"50"`
+	// CopyThreshold is the least number of movies created at once which are
+	// loaded with COPY instead of INSERT statements. Zero disables COPY.
+	CopyThreshold int `env:"POSTGRES_COPY_THRESHOLD" env-default:"1000"`
+	// Replicas are connection strings of read replicas separated by commas.
+	// Read-only queries go to healthy replicas, or to the primary if there
+	// is none. SSL settings, statement timeout and application name above
+	// are added to them unless set in the connection string.
+	Replicas             []string      `env:"POSTGRES_REPLICAS" env-separator:","`
+	ReplicaCheckInterval time.Duration `env:"POSTGRES_REPLICA_CHECK_INTERVAL" env-default:"5s"`
+	// ReadYourWrites makes request read from the primary once it has changed
+	// anything, so it doesn't miss its own changes on lagging replica. It
+	// covers a single request only, the next one may read from the replica.
+	ReadYourWrites bool `env:"POSTGRES_READ_YOUR_WRITES" env-default:"true"`
+}
+
+// SQLite is used if storage is sqlite. Database file is created on first start.
+type SQLite struct {
+	Path        string        `env:"SQLITE_PATH" env-default:"movies.db"`
+	BusyTimeout time.Duration `env:"SQLITE_BUSY_TIMEOUT" env-default:"5s"`
+}
+
+// Outbox configures relay of movie events to external systems.
+// Sink is one of none, stdout, file or webhook. Events are not put to the
+// outbox at all if sink is none.
+type Outbox struct {
+	Sink           string        `env:"OUTBOX_SINK" env-default:"none"`
+	FilePath       string        `env:"OUTBOX_FILE_PATH" env-default:"outbox.jsonl"`
+	WebhookURL     string        `env:"OUTBOX_WEBHOOK_URL"`
+	WebhookTimeout time.Duration `env:"OUTBOX_WEBHOOK_TIMEOUT" env-default:"10s"`
+	BatchSize      uint64        `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	PollInterval   time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"5s"`
+	Lease          time.Duration `env:"OUTBOX_LEASE" env-default:"1m"`
+	MinBackoff     time.Duration `env:"OUTBOX_MIN_BACKOFF" env-default:"1s"`
+	MaxBackoff     time.Duration `env:"OUTBOX_MAX_BACKOFF" env-default:"10m"`
+	// Retention is how long delivered events are kept in the outbox, zero keeps them forever
+	Retention time.Duration `env:"OUTBOX_RETENTION" env-default:"24h"`
+	// EventRetention is how long movie events are kept for WatchMovies and
+	// webhooks, zero keeps them forever. Events waiting for delivery are
+	// kept regardless. They are purged even if sink is none.
+	EventRetention time.Duration `env:"MOVIE_EVENTS_RETENTION" env-default:"168h"`
+}
+
+// Disabled reports whether outbox sink is not configured. Events aren't
+// put to the outbox then, since nothing would ever take them out.
+func (o *Outbox) Disabled() bool {
+	return o.Sink == "" || o.Sink == "none"
+}
+
+// Webhooks configures delivery of movie events to registered webhooks
+type Webhooks struct {
+	Timeout      time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	MaxAttempts  uint32        `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+	BatchSize    uint64        `env:"WEBHOOK_BATCH_SIZE" env-default:"100"`
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"5s"`
+	Lease        time.Duration `env:"WEBHOOK_LEASE" env-default:"1m"`
+	MinBackoff   time.Duration `env:"WEBHOOK_MIN_BACKOFF" env-default:"5s"`
+	MaxBackoff   time.Duration `env:"WEBHOOK_MAX_BACKOFF" env-default:"1h"`
 }
 
 func MustLoad() *Config {
@@ -39,5 +136,42 @@ func MustLoad() *Config {
 		panic(fmt.Errorf("%s: failed to read config from env vars: %w", op, err))
 	}
 
+	switch cfg.MovieService.Storage {
+	case StoragePostgres:
+		if cfg.Postgres.Username == "" || cfg.Postgres.Password == "" {
+			panic(fmt.Errorf("%s: POSTGRES_USER and POSTGRES_PASSWORD are required for postgres storage", op))
+		}
+		if len(cfg.Postgres.Replicas) > 0 && cfg.Postgres.ReplicaCheckInterval <= 0 {
+			panic(fmt.Errorf("%s: POSTGRES_REPLICA_CHECK_INTERVAL must be positive, got %s", op, cfg.Postgres.ReplicaCheckInterval))
+		}
+	case StorageMemory, StorageSQLite:
+	default:
+		panic(fmt.Errorf("%s: unknown storage %q", op, cfg.MovieService.Storage))
+	}
+
+	if !cfg.Outbox.Disabled() {
+		if cfg.Outbox.PollInterval <= 0 {
+			panic(fmt.Errorf("%s: OUTBOX_POLL_INTERVAL must be positive, got %s", op, cfg.Outbox.PollInterval))
+		}
+		if cfg.Outbox.BatchSize == 0 {
+			panic(fmt.Errorf("%s: OUTBOX_BATCH_SIZE must be positive", op))
+		}
+		if cfg.Outbox.Retention < 0 {
+			panic(fmt.Errorf("%s: OUTBOX_RETENTION must not be negative, got %s", op, cfg.Outbox.Retention))
+		}
+	}
+
+	if cfg.Outbox.EventRetention < 0 {
+		panic(fmt.Errorf("%s: MOVIE_EVENTS_RETENTION must not be negative, got %s", op, cfg.Outbox.EventRetention))
+	}
+
+	// Webhook dispatcher runs always
+	if cfg.Webhooks.PollInterval <= 0 {
+		panic(fmt.Errorf("%s: WEBHOOK_POLL_INTERVAL must be positive, got %s", op, cfg.Webhooks.PollInterval))
+	}
+	if cfg.Webhooks.BatchSize == 0 {
+		panic(fmt.Errorf("%s: WEBHOOK_BATCH_SIZE must be positive", op))
+	}
+
 	return &cfg
 }
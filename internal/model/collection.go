@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type CollectionKind string
+
+const (
+	CollectionKindFranchise CollectionKind = "franchise"
+	CollectionKindCurated   CollectionKind = "curated"
+)
+
+// Collection is an ordered group of movies, e.g. franchise or curated list.
+// MovieIDs are ordered by position of movies in the collection.
+type Collection struct {
+	ID          string         `db:"collection_id"`
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Kind        CollectionKind `db:"kind"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+
+	MovieIDs []string `db:"-"`
+}
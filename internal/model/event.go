@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type MovieEventType string
+
+const (
+	MovieEventCreated MovieEventType = "created"
+	MovieEventUpdated MovieEventType = "updated"
+	MovieEventDeleted MovieEventType = "deleted"
+)
+
+// MovieEvent is a change of the movie. Seq increases monotonically in order
+// in which changes were committed. Movie holds state of the movie right after
+// the change (right before it for deletion).
+type MovieEvent struct {
+	Seq        uint64         `db:"seq"`
+	Type       MovieEventType `db:"event_type"`
+	MovieID    string         `db:"movie_id"`
+	Movie      Movie          `db:"-"`
+	OccurredAt time.Time      `db:"occurred_at"`
+}
+
+// OutboxEvent is a movie event waiting for delivery to external systems
+type OutboxEvent struct {
+	MovieEvent
+	Attempts uint32 `db:"attempts"`
+}
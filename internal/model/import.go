@@ -0,0 +1,12 @@
+package model
+
+// ImportProgress is a progress of bulk import from an external source,
+// e.g. IMDb datasets. It is saved along with every loaded batch, so that
+// interrupted import continues from where it stopped.
+type ImportProgress struct {
+	Source string `db:"source"`
+	// Loaded is number of movies which are already loaded
+	Loaded int `db:"loaded"`
+	// LastID is ID of the last loaded movie in the source
+	LastID string `db:"last_id"`
+}
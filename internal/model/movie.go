@@ -1,9 +1,38 @@
 package model
 
 type Movie struct {
-	ID       string `db:"movie_id"`
-	Title    string `db:"title"`
-	Genre    string `db:"genre"`
-	Director string `db:"director"`
-	Year     uint32 `db:"year"`
+	ID          string `db:"movie_id"`
+	Title       string `db:"title"`
+	Genre       string `db:"genre"`
+	Director    string `db:"director"`
+	Year        uint32 `db:"year"`
+	RatingSum   uint64 `db:"rating_sum"`
+	RatingCount uint32 `db:"rating_count"`
+	ReviewCount uint32 `db:"review_count"`
+
+	CollectionIDs []string `db:"-"`
+	Tags          []string `db:"-"`
+}
+
+// AverageRating returns mean user score or 0 if movie has not been rated yet
+func (m *Movie) AverageRating() float64 {
+	if m.RatingCount == 0 {
+		return 0
+	}
+
+	return float64(m.RatingSum) / float64(m.RatingCount)
+}
+
+// MovieFilter narrows down list of movies. Zero value matches all movies.
+type MovieFilter struct {
+	// Movie must have all of these tags
+	TagsAll []string
+	// Movie must have at least one of these tags
+	TagsAny []string
+	// Case-insensitive exact match, empty matches all
+	Genre    string
+	Director string
+	// Inclusive bounds, zero means unbounded
+	YearFrom uint32
+	YearTo   uint32
 }
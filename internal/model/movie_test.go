@@ -0,0 +1,23 @@
+package model
+
+import "testing"
+
+func TestAverageRating(t *testing.T) {
+	tests := []struct {
+		name  string
+		movie Movie
+		want  float64
+	}{
+		{name: "not rated", movie: Movie{}, want: 0},
+		{name: "single score", movie: Movie{RatingSum: 7, RatingCount: 1}, want: 7},
+		{name: "fractional mean", movie: Movie{RatingSum: 17, RatingCount: 2}, want: 8.5},
+		{name: "many scores", movie: Movie{RatingSum: 10 * 1_000_000, RatingCount: 1_000_000}, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.movie.AverageRating(); got != tt.want {
+				t.Errorf("AverageRating = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
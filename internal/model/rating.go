@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type Rating struct {
+	MovieID   string    `db:"movie_id"`
+	UserID    string    `db:"user_id"`
+	Score     uint32    `db:"score"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusRejected ReviewStatus = "rejected"
+)
+
+type Review struct {
+	ID        string       `db:"review_id"`
+	MovieID   string       `db:"movie_id"`
+	UserID    string       `db:"user_id"`
+	Text      string       `db:"text"`
+	Status    ReviewStatus `db:"status"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package model
+
+// SimilarMovie is a movie scored by similarity to another one along with
+// features which contributed to the score
+type SimilarMovie struct {
+	Movie
+	Score           float64 `db:"score"`
+	SameGenre       bool    `db:"same_genre"`
+	SameDirector    bool    `db:"same_director"`
+	YearDiff        uint32  `db:"year_diff"`
+	TitleSimilarity float64 `db:"title_similarity"`
+
+	Reasons []string `db:"-"`
+}
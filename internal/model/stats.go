@@ -0,0 +1,44 @@
+package model
+
+type Facet string
+
+const (
+	FacetGenre    Facet = "genre"
+	FacetDirector Facet = "director"
+	FacetTag      Facet = "tag"
+	FacetYear     Facet = "year"
+	FacetRating   Facet = "rating"
+)
+
+// AllFacets lists facets computed when none are requested explicitly
+var AllFacets = []Facet{FacetGenre, FacetDirector, FacetTag, FacetYear, FacetRating}
+
+// CatalogStatsQuery describes which statistics to compute over movies
+// matching the filter
+type CatalogStatsQuery struct {
+	Filter MovieFilter
+	Facets []Facet
+	// Max number of buckets of genre, director and tag facets
+	TopLimit uint64
+	// Width of year histogram bucket in years
+	YearBucket uint32
+}
+
+type CatalogStats struct {
+	TotalMovies uint64
+	Facets      []FacetCounts
+}
+
+type FacetCounts struct {
+	Facet   Facet
+	Buckets []FacetBucket
+}
+
+// FacetBucket is a number of movies having the value. For histograms
+// (year, rating) bucket also holds inclusive bounds of the value.
+type FacetBucket struct {
+	Value string `db:"value"`
+	From  uint32 `db:"bucket_from"`
+	To    uint32 `db:"bucket_to"`
+	Count uint64 `db:"count"`
+}
@@ -0,0 +1,6 @@
+package model
+
+type Tag struct {
+	Name       string `db:"name"`
+	UsageCount uint32 `db:"usage_count"`
+}
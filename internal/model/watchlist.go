@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// WatchlistEntry is a movie the user is going to watch. Movie is nil
+// if it was deleted from catalog after being added to the list.
+type WatchlistEntry struct {
+	UserID  string    `db:"user_id"`
+	MovieID string    `db:"movie_id"`
+	AddedAt time.Time `db:"added_at"`
+	Movie   *Movie    `db:"movie"`
+}
+
+// WatchHistoryEntry is a record of the user watching a movie. Progress
+// is a percentage of the movie watched. Movie is nil if it was deleted
+// from catalog after being watched.
+type WatchHistoryEntry struct {
+	ID        string    `db:"entry_id"`
+	UserID    string    `db:"user_id"`
+	MovieID   string    `db:"movie_id"`
+	WatchedAt time.Time `db:"watched_at"`
+	Progress  uint32    `db:"progress"`
+	Movie     *Movie    `db:"movie"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	// DeliveryStatusDead means that delivery ran out of attempts
+	DeliveryStatusDead DeliveryStatus = "dead"
+)
+
+// Webhook is an endpoint which is notified about movie events.
+// Empty EventTypes means all types of events.
+type Webhook struct {
+	ID         string           `db:"webhook_id"`
+	URL        string           `db:"url"`
+	Secret     string           `db:"secret"`
+	EventTypes []MovieEventType `db:"-"`
+	CreatedAt  time.Time        `db:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             string         `db:"delivery_id"`
+	WebhookID      string         `db:"webhook_id"`
+	EventSeq       uint64         `db:"event_seq"`
+	EventType      MovieEventType `db:"event_type"`
+	Status         DeliveryStatus `db:"status"`
+	Attempts       uint32         `db:"attempts"`
+	LastError      string         `db:"last_error"`
+	ResponseStatus int            `db:"response_status"`
+	CreatedAt      time.Time      `db:"created_at"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at"`
+	DeliveredAt    *time.Time     `db:"delivered_at"`
+}
+
+// WebhookDispatch is a delivery claimed for sending along with everything
+// needed to send it
+type WebhookDispatch struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+	Event    MovieEvent
+}
@@ -0,0 +1,58 @@
+// Package outbox publishes movie events saved to the transactional outbox
+// to external systems.
+package outbox
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"time"
+)
+
+// Sink is a destination of events. Publish must be idempotent-friendly:
+// event may be published more than once, Sequence identifies it.
+type Sink interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// Message is JSON representation of movie event published to sinks
+type Message struct {
+	Sequence   uint64    `json:"sequence"`
+	Type       string    `json:"type"`
+	MovieID    string    `json:"movie_id"`
+	Movie      Movie     `json:"movie"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type Movie struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Genre         string   `json:"genre"`
+	Director      string   `json:"director"`
+	Year          uint32   `json:"year"`
+	AverageRating float64  `json:"average_rating"`
+	RatingCount   uint32   `json:"rating_count"`
+	ReviewCount   uint32   `json:"review_count"`
+	CollectionIDs []string `json:"collection_ids"`
+	Tags          []string `json:"tags"`
+}
+
+func NewMessage(event *model.MovieEvent) *Message {
+	return &Message{
+		Sequence: event.Seq,
+		Type:     string(event.Type),
+		MovieID:  event.MovieID,
+		Movie: Movie{
+			ID:            event.Movie.ID,
+			Title:         event.Movie.Title,
+			Genre:         event.Movie.Genre,
+			Director:      event.Movie.Director,
+			Year:          event.Movie.Year,
+			AverageRating: event.Movie.AverageRating(),
+			RatingCount:   event.Movie.RatingCount,
+			ReviewCount:   event.Movie.ReviewCount,
+			CollectionIDs: event.Movie.CollectionIDs,
+			Tags:          event.Movie.Tags,
+		},
+		OccurredAt: event.OccurredAt,
+	}
+}
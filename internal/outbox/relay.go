@@ -0,0 +1,201 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"movie-service/internal/model"
+	"movie-service/pkg/sl"
+	"time"
+)
+
+type outboxRepo interface {
+	ClaimOutboxEvents(ctx context.Context, limit uint64, lease time.Duration) ([]model.OutboxEvent, error)
+	MarkOutboxDelivered(ctx context.Context, seq uint64) error
+	MarkOutboxFailed(ctx context.Context, seq uint64, retryAt time.Time, reason string) error
+	PurgeOutbox(ctx context.Context, deliveredBefore time.Time) (uint64, error)
+	PurgeMovieEvents(ctx context.Context, occurredBefore time.Time) (uint64, error)
+}
+
+type eventNotifier interface {
+	Subscribe() (<-chan struct{}, func())
+}
+
+type Config struct {
+	BatchSize    uint64
+	PollInterval time.Duration
+	// Lease is how long claimed events are hidden from other relays
+	Lease time.Duration
+	// MinBackoff and MaxBackoff bound delay before next attempt, which is
+	// doubled after every failed attempt
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retention is how long delivered events are kept in the outbox,
+	// zero keeps them forever
+	Retention time.Duration
+	// EventRetention is how long movie events are kept for readers of the
+	// change feed, zero keeps them forever
+	EventRetention time.Duration
+}
+
+// purgeInterval is how often events older than retention are deleted
+const purgeInterval = time.Hour
+
+// Relay moves events from the outbox to the sink. Events are delivered
+// at least once; failed deliveries are retried with exponential backoff.
+// Relay also purges old events. Relay without sink only purges them.
+type Relay struct {
+	log      *slog.Logger
+	repo     outboxRepo
+	notifier eventNotifier
+	sink     Sink
+	cfg      Config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRelay(log *slog.Logger, repo outboxRepo, notifier eventNotifier, sink Sink, cfg Config) *Relay {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Relay{
+		log:      log.With(slog.String("component", "outbox.Relay")),
+		repo:     repo,
+		notifier: notifier,
+		sink:     sink,
+		cfg:      cfg,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Run relays events until Stop is called
+func (r *Relay) Run() {
+	defer close(r.done)
+
+	r.purge()
+	purgeTicker := time.NewTicker(purgeInterval)
+	defer purgeTicker.Stop()
+
+	// Relay without sink is never woken up to relay
+	var wakeup <-chan struct{}
+	var poll <-chan time.Time
+	if r.sink != nil {
+		var unsubscribe func()
+		wakeup, unsubscribe = r.notifier.Subscribe()
+		defer unsubscribe()
+
+		ticker := time.NewTicker(r.cfg.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		if r.sink != nil {
+			r.relay()
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-wakeup:
+		case <-poll:
+		case <-purgeTicker.C:
+			r.purge()
+		}
+	}
+}
+
+// Stop stops relaying, waits for deliveries in progress to finish
+// and closes the sink if it needs closing
+func (r *Relay) Stop() error {
+	const op = "outbox.Relay.Stop"
+
+	r.cancel()
+	<-r.done
+
+	if c, ok := r.sink.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			return fmt.Errorf("%s: failed to close sink: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// relay delivers all events which are due
+func (r *Relay) relay() {
+	for r.ctx.Err() == nil {
+		events, err := r.repo.ClaimOutboxEvents(r.ctx, r.cfg.BatchSize, r.cfg.Lease)
+		if err != nil {
+			r.log.Error("Failed to claim outbox events", sl.Err(err))
+			return
+		}
+
+		for i := range events {
+			r.deliver(&events[i])
+		}
+
+		if uint64(len(events)) < r.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// purge deletes events delivered longer than retention ago and movie
+// events older than event retention
+func (r *Relay) purge() {
+	if r.cfg.Retention > 0 {
+		num, err := r.repo.PurgeOutbox(r.ctx, time.Now().Add(-r.cfg.Retention))
+		if err != nil {
+			r.log.Error("Failed to purge outbox", sl.Err(err))
+		} else if num > 0 {
+			r.log.Info("Purged delivered events from outbox", slog.Uint64("count", num))
+		}
+	}
+
+	if r.cfg.EventRetention > 0 {
+		num, err := r.repo.PurgeMovieEvents(r.ctx, time.Now().Add(-r.cfg.EventRetention))
+		if err != nil {
+			r.log.Error("Failed to purge movie events", sl.Err(err))
+		} else if num > 0 {
+			r.log.Info("Purged movie events", slog.Uint64("count", num))
+		}
+	}
+}
+
+func (r *Relay) deliver(event *model.OutboxEvent) {
+	// Result of the attempt is saved even if relay is being stopped
+	ctx := context.WithoutCancel(r.ctx)
+	log := r.log.With(slog.Uint64("seq", event.Seq), slog.Any("attempt", event.Attempts))
+
+	if err := r.sink.Publish(r.ctx, NewMessage(&event.MovieEvent)); err != nil {
+		retryAt := time.Now().Add(Backoff(event.Attempts, r.cfg.MinBackoff, r.cfg.MaxBackoff))
+		log.Warn("Failed to publish event", sl.Err(err), slog.Time("retry_at", retryAt))
+
+		if err := r.repo.MarkOutboxFailed(ctx, event.Seq, retryAt, err.Error()); err != nil {
+			log.Error("Failed to reschedule event", sl.Err(err))
+		}
+
+		return
+	}
+
+	if err := r.repo.MarkOutboxDelivered(ctx, event.Seq); err != nil {
+		// Event will be published again once lease expires
+		log.Error("Failed to mark event delivered", sl.Err(err))
+	}
+}
+
+// Backoff returns delay before the next attempt after given number of attempts.
+// Delay starts at minDelay and doubles after every attempt up to maxDelay.
+func Backoff(attempts uint32, minDelay, maxDelay time.Duration) time.Duration {
+	delay := minDelay
+	for i := uint32(1); i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxDelay)
+}
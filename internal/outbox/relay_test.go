@@ -0,0 +1,232 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"movie-service/internal/model"
+	"movie-service/internal/outbox"
+	"movie-service/internal/repository"
+	memoryrepo "movie-service/internal/repository/memory"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink remembers published events. First failures attempts to
+// publish every event fail.
+type recordingSink struct {
+	failures int
+
+	mu        sync.Mutex
+	attempts  map[uint64]int
+	published []uint64
+}
+
+func (s *recordingSink) Publish(ctx context.Context, msg *outbox.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts[msg.Sequence]++
+	if s.attempts[msg.Sequence] <= s.failures {
+		return errors.New("sink is unavailable")
+	}
+	s.published = append(s.published, msg.Sequence)
+
+	return nil
+}
+
+// wait waits until n events are published and returns them
+func (s *recordingSink) wait(t *testing.T, n int) []uint64 {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		published := len(s.published)
+		s.mu.Unlock()
+
+		if published >= n {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d events are published, want %d", published, n)
+		}
+	}
+
+	// Give relays time to publish duplicates, if they would
+	time.Sleep(50 * time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]uint64(nil), s.published...)
+}
+
+func newRelay(t *testing.T, repo *memoryrepo.Repository, sink outbox.Sink, cfg outbox.Config) *outbox.Relay {
+	t.Helper()
+
+	relay := outbox.NewRelay(slog.New(slog.NewTextHandler(io.Discard, nil)), repo, repo.Notifier(), sink, cfg)
+	go relay.Run()
+	t.Cleanup(func() {
+		if err := relay.Stop(); err != nil {
+			t.Errorf("Stop error = %v", err)
+		}
+	})
+
+	return relay
+}
+
+func createMovies(t *testing.T, repo *memoryrepo.Repository, n int) {
+	t.Helper()
+
+	for i := range n {
+		movie := &model.Movie{Title: fmt.Sprintf("Movie %02d", i), Genre: "Drama", Director: "Director", Year: 2000}
+		if _, err := repo.CreateMovie(context.Background(), movie); err != nil {
+			t.Fatalf("CreateMovie error = %v", err)
+		}
+	}
+}
+
+var testConfig = outbox.Config{
+	BatchSize:    3,
+	PollInterval: time.Millisecond,
+	Lease:        time.Minute,
+	MinBackoff:   time.Millisecond,
+	MaxBackoff:   time.Millisecond,
+}
+
+func TestRelayRedelivers(t *testing.T) {
+	repo := memoryrepo.New()
+	createMovies(t, repo, 5)
+
+	sink := &recordingSink{failures: 2, attempts: make(map[uint64]int)}
+	newRelay(t, repo, sink, testConfig)
+
+	published := sink.wait(t, 5)
+	if len(published) != 5 {
+		t.Fatalf("published %v, want every event once", published)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	for seq := range uint64(5) {
+		if got := sink.attempts[seq+1]; got != 3 {
+			t.Errorf("event %d is attempted %d times, want 3", seq+1, got)
+		}
+	}
+
+	events, err := repo.ClaimOutboxEvents(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEvents error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("ClaimOutboxEvents = %+v, want no events left after delivery", events)
+	}
+}
+
+func TestRelaysShareOutbox(t *testing.T) {
+	const movies = 30
+
+	repo := memoryrepo.New()
+	sink := &recordingSink{attempts: make(map[uint64]int)}
+	for range 4 {
+		newRelay(t, repo, sink, testConfig)
+	}
+	createMovies(t, repo, movies)
+
+	// Claimed events are leased to a single relay, so none is published twice
+	published := sink.wait(t, movies)
+	if len(published) != movies {
+		t.Fatalf("published %d events, want %d", len(published), movies)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	for seq, attempts := range sink.attempts {
+		if attempts != 1 {
+			t.Errorf("event %d is published %d times, want once", seq, attempts)
+		}
+	}
+}
+
+func TestRelayLeaseExpires(t *testing.T) {
+	repo := memoryrepo.New()
+	createMovies(t, repo, 1)
+
+	// Relay claiming the event dies without reporting the result
+	lease := 50 * time.Millisecond
+	claimed, err := repo.ClaimOutboxEvents(context.Background(), 10, lease)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimOutboxEvents = %+v, %v, want single event", claimed, err)
+	}
+	claimedAt := time.Now()
+
+	sink := &recordingSink{attempts: make(map[uint64]int)}
+	newRelay(t, repo, sink, testConfig)
+
+	sink.wait(t, 1)
+	if elapsed := time.Since(claimedAt); elapsed < lease {
+		t.Errorf("event is published again %v after claim, before lease of %v expires", elapsed, lease)
+	}
+}
+
+func TestRelayPurgesDelivered(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryrepo.New()
+	createMovies(t, repo, 2)
+
+	events, err := repo.ClaimOutboxEvents(ctx, 1, time.Minute)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("ClaimOutboxEvents = %+v, %v, want single event", events, err)
+	}
+	if err := repo.MarkOutboxDelivered(ctx, events[0].Seq); err != nil {
+		t.Fatalf("MarkOutboxDelivered error = %v", err)
+	}
+
+	// Relay purges delivered events once it starts
+	cfg := testConfig
+	cfg.Retention = time.Nanosecond
+	sink := &recordingSink{attempts: make(map[uint64]int)}
+	relay := outbox.NewRelay(slog.New(slog.NewTextHandler(io.Discard, nil)), repo, repo.Notifier(), sink, cfg)
+	go relay.Run()
+	sink.wait(t, 1)
+	if err := relay.Stop(); err != nil {
+		t.Fatalf("Stop error = %v", err)
+	}
+
+	// Event delivered by the relay is purged only on the next run, so just
+	// it is left in the outbox
+	purged, err := repo.PurgeOutbox(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeOutbox error = %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("PurgeOutbox after relay purged %d events, want 1", purged)
+	}
+}
+
+func TestRelayWithoutSinkPurgesEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryrepo.New(memoryrepo.WithoutOutbox())
+	createMovies(t, repo, 3)
+
+	// Relay without sink doesn't poll, so it needs no poll interval
+	cfg := outbox.Config{EventRetention: time.Nanosecond}
+	relay := outbox.NewRelay(slog.New(slog.NewTextHandler(io.Discard, nil)), repo, repo.Notifier(), nil, cfg)
+	go relay.Run()
+	// Relay purges once it starts, before it's stopped
+	if err := relay.Stop(); err != nil {
+		t.Fatalf("Stop error = %v", err)
+	}
+
+	// Every event but the latest one is purged
+	if _, err := repo.GetMovieEvents(ctx, 0, 10); !errors.Is(err, repository.ErrEventsPurged) {
+		t.Errorf("GetMovieEvents error = %v, want %v", err, repository.ErrEventsPurged)
+	}
+	events, err := repo.GetMovieEvents(ctx, 2, 10)
+	if err != nil || len(events) != 1 || events[0].Seq != 3 {
+		t.Errorf("GetMovieEvents = %+v, %v, want the latest event", events, err)
+	}
+}
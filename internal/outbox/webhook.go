@@ -0,0 +1,57 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WebhookSink posts events as JSON to the URL. Any response status
+// other than 2xx is treated as failure.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, msg *Message) error {
+	const op = "outbox.WebhookSink.Publish"
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode event: %w", op, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: failed to create request: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Sequence", strconv.FormatUint(msg.Sequence, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: failed to send event: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	// Drain body so that connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: unexpected response status: %s", op, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterSink writes events as JSON lines
+type WriterSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	c   io.Closer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{enc: json.NewEncoder(w)}
+}
+
+// NewFileSink appends events to the file, creating it if needed
+func NewFileSink(path string) (*WriterSink, error) {
+	const op = "outbox.NewFileSink"
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to open file: %w", op, err)
+	}
+
+	return &WriterSink{enc: json.NewEncoder(f), c: f}, nil
+}
+
+func (s *WriterSink) Publish(_ context.Context, msg *Message) error {
+	const op = "outbox.WriterSink.Publish"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enc.Encode(msg); err != nil {
+		return fmt.Errorf("%s: failed to write event: %w", op, err)
+	}
+
+	return nil
+}
+
+// Close closes underlying file if the sink was created by NewFileSink
+func (s *WriterSink) Close() error {
+	if s.c == nil {
+		return nil
+	}
+
+	return s.c.Close()
+}
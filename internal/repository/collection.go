@@ -0,0 +1,22 @@
+package repository
+
+import "slices"
+
+// ChangedMembers returns sorted IDs of movies which are members of only one
+// of before and after, that is movies which joined or left the collection
+func ChangedMembers(before, after []string) []string {
+	ids := make([]string, 0)
+	for _, id := range before {
+		if !slices.Contains(after, id) && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range after {
+		if !slices.Contains(before, id) && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	return ids
+}
@@ -3,5 +3,11 @@ package repository
 import "errors"
 
 var (
-	ErrMovieNotExists = errors.New("movie info does not exist")
+	ErrMovieNotExists      = errors.New("movie info does not exist")
+	ErrReviewNotExists     = errors.New("review does not exist")
+	ErrReviewNotOwned      = errors.New("review is written by another user")
+	ErrCollectionNotExists = errors.New("collection does not exist")
+	ErrWebhookNotExists    = errors.New("webhook does not exist")
+	ErrEventNotExists      = errors.New("movie event does not exist")
+	ErrEventsPurged        = errors.New("movie events are purged")
 )
@@ -0,0 +1,179 @@
+package memoryrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) CreateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error) {
+	const op = "repository.memory.CreateCollection"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkCollectionMovies(collection.MovieIDs); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+	created := &model.Collection{
+		ID:          uuid.NewString(),
+		Name:        collection.Name,
+		Description: collection.Description,
+		Kind:        collection.Kind,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		MovieIDs:    append(make([]string, 0, len(collection.MovieIDs)), collection.MovieIDs...),
+	}
+	r.collections[created.ID] = created
+	r.recordMembershipChanges(nil, created.MovieIDs)
+
+	return cloneCollection(created), nil
+}
+
+func (r *Repository) GetCollection(ctx context.Context, id string) (*model.Collection, error) {
+	const op = "repository.memory.GetCollection"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collection, ok := r.collections[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: failed to get collection: %w", op, repo.ErrCollectionNotExists)
+	}
+
+	return cloneCollection(collection), nil
+}
+
+// UpdateCollection changes non-empty fields of the collection. Membership
+// isn't affected, use SetCollectionMovies for that.
+func (r *Repository) UpdateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error) {
+	const op = "repository.memory.UpdateCollection"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.collections[collection.ID]
+	if !ok {
+		return nil, fmt.Errorf("%s: failed to update collection: %w", op, repo.ErrCollectionNotExists)
+	}
+
+	if collection.Name != "" {
+		stored.Name = collection.Name
+	}
+	if collection.Description != "" {
+		stored.Description = collection.Description
+	}
+	if collection.Kind != "" {
+		stored.Kind = collection.Kind
+	}
+	stored.UpdatedAt = time.Now()
+
+	return cloneCollection(stored), nil
+}
+
+// SetCollectionMovies replaces movies of the collection. Order of movieIDs
+// defines order of movies in the collection.
+func (r *Repository) SetCollectionMovies(ctx context.Context, id string, movieIDs []string) (*model.Collection, error) {
+	const op = "repository.memory.SetCollectionMovies"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.collections[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: failed to update collection: %w", op, repo.ErrCollectionNotExists)
+	}
+
+	if err := r.checkCollectionMovies(movieIDs); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	before := stored.MovieIDs
+	stored.MovieIDs = append(make([]string, 0, len(movieIDs)), movieIDs...)
+	stored.UpdatedAt = time.Now()
+	r.recordMembershipChanges(before, stored.MovieIDs)
+
+	return cloneCollection(stored), nil
+}
+
+// Returning bool val indicates whether collection was deleted or not
+func (r *Repository) DeleteCollection(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	collection, ok := r.collections[id]
+	if !ok {
+		return false, nil
+	}
+	delete(r.collections, id)
+	r.recordMembershipChanges(collection.MovieIDs, nil)
+
+	return true, nil
+}
+
+// GetCollectionMovies returns movies of the collection in collection order
+func (r *Repository) GetCollectionMovies(ctx context.Context, id string) ([]model.Movie, error) {
+	const op = "repository.memory.GetCollectionMovies"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collection, ok := r.collections[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: failed to get collection: %w", op, repo.ErrCollectionNotExists)
+	}
+
+	movies := make([]model.Movie, 0, len(collection.MovieIDs))
+	for _, movieID := range collection.MovieIDs {
+		movie, err := r.getMovie(movieID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		movies = append(movies, *movie)
+	}
+
+	return movies, nil
+}
+
+// checkCollectionMovies makes sure that all movies exist and none of them
+// is listed twice
+func (r *Repository) checkCollectionMovies(movieIDs []string) error {
+	seen := make(map[string]struct{}, len(movieIDs))
+	for _, movieID := range movieIDs {
+		if _, ok := r.movies[movieID]; !ok {
+			return fmt.Errorf("failed to add movies to collection: %w", repo.ErrMovieNotExists)
+		}
+
+		if _, ok := seen[movieID]; ok {
+			return fmt.Errorf("failed to add movies to collection: movie %s is listed twice", movieID)
+		}
+		seen[movieID] = struct{}{}
+	}
+
+	return nil
+}
+
+func cloneCollection(collection *model.Collection) *model.Collection {
+	clone := *collection
+	clone.MovieIDs = slices.Clone(collection.MovieIDs)
+
+	return &clone
+}
+
+// recordMembershipChanges records updated events of movies which joined or
+// left the collection, since collection IDs are part of the movie. Must be
+// called with the lock held after the change.
+func (r *Repository) recordMembershipChanges(before, after []string) {
+	for _, movieID := range repo.ChangedMembers(before, after) {
+		if movie, err := r.getMovie(movieID); err == nil {
+			r.recordMovieEvent(model.MovieEventUpdated, movie)
+		}
+	}
+}
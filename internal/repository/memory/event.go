@@ -0,0 +1,140 @@
+package memoryrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+	"time"
+)
+
+// GetMovieEvents returns events which happened after the one with afterSeq
+// sequence number, oldest first. ErrEventsPurged is returned if some of
+// these events are already purged.
+func (r *Repository) GetMovieEvents(ctx context.Context, afterSeq uint64, limit uint64) ([]model.MovieEvent, error) {
+	const op = "repository.memory.GetMovieEvents"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if afterSeq < r.purgedSeq {
+		return nil, fmt.Errorf("%s: events up to %d: %w", op, r.purgedSeq, repo.ErrEventsPurged)
+	}
+
+	events := make([]model.MovieEvent, 0)
+	for _, event := range page(r.events, limit, afterSeq-r.purgedSeq) {
+		events = append(events, cloneEvent(&event))
+	}
+
+	return events, nil
+}
+
+// GetLastMovieEventSeq returns sequence number of the latest movie event
+// or 0 if there are no events yet
+func (r *Repository) GetLastMovieEventSeq(ctx context.Context) (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.purgedSeq + uint64(len(r.events)), nil
+}
+
+// PurgeMovieEvents deletes events which occurred before occurredBefore and
+// returns their number. Events are purged from the oldest one up to the
+// first event still waiting in the outbox or for webhook delivery, and the
+// latest event is always kept, so purged events are never needed again.
+func (r *Repository) PurgeMovieEvents(ctx context.Context, occurredBefore time.Time) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending := make(map[uint64]struct{})
+	for seq, entry := range r.outbox {
+		if entry.deliveredAt.IsZero() {
+			pending[seq] = struct{}{}
+		}
+	}
+	for _, delivery := range r.deliveries {
+		if delivery.Status == model.DeliveryStatusPending {
+			pending[delivery.EventSeq] = struct{}{}
+		}
+	}
+
+	num := 0
+	for num < len(r.events)-1 && r.events[num].OccurredAt.Before(occurredBefore) {
+		if _, ok := pending[r.events[num].Seq]; ok {
+			break
+		}
+		num++
+	}
+	if num == 0 {
+		return 0, nil
+	}
+
+	// Outbox entries and deliveries of purged events go along with them
+	r.purgedSeq += uint64(num)
+	for seq := range r.outbox {
+		if seq <= r.purgedSeq {
+			delete(r.outbox, seq)
+		}
+	}
+	for id, delivery := range r.deliveries {
+		if delivery.EventSeq <= r.purgedSeq {
+			delete(r.deliveries, id)
+		}
+	}
+	r.events = slices.Clone(r.events[num:])
+
+	return uint64(num), nil
+}
+
+// recordMovieEvent saves event about the movie change, puts it to the outbox
+// unless disabled by WithoutOutbox, schedules webhook deliveries and wakes up
+// subscribers. Must be called with the lock held.
+func (r *Repository) recordMovieEvent(eventType model.MovieEventType, movie *model.Movie) {
+	now := time.Now()
+	event := model.MovieEvent{
+		Seq:        r.purgedSeq + uint64(len(r.events)) + 1,
+		Type:       eventType,
+		MovieID:    movie.ID,
+		Movie:      cloneMovie(movie),
+		OccurredAt: now,
+	}
+	r.events = append(r.events, event)
+
+	if !r.skipOutbox {
+		r.outbox[event.Seq] = &outboxEntry{nextAttemptAt: now}
+	}
+
+	for _, webhook := range r.webhooks {
+		if len(webhook.EventTypes) == 0 || slices.Contains(webhook.EventTypes, eventType) {
+			r.addDelivery(webhook.ID, &event)
+		}
+	}
+
+	r.notifier.Broadcast()
+}
+
+// event returns event by sequence number
+func (r *Repository) event(seq uint64) (*model.MovieEvent, bool) {
+	if seq <= r.purgedSeq || seq > r.purgedSeq+uint64(len(r.events)) {
+		return nil, false
+	}
+
+	return &r.events[seq-r.purgedSeq-1], true
+}
+
+// cloneEvent returns copy of the event which doesn't share memory with it
+func cloneEvent(event *model.MovieEvent) model.MovieEvent {
+	clone := *event
+	clone.Movie = cloneMovie(&event.Movie)
+
+	return clone
+}
+
+func cloneMovie(movie *model.Movie) model.Movie {
+	clone := *movie
+	clone.Tags = slices.Clone(movie.Tags)
+	clone.CollectionIDs = slices.Clone(movie.CollectionIDs)
+
+	return clone
+}
@@ -0,0 +1,30 @@
+package memoryrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+)
+
+// ExportMovies passes all movies matching the filter to fn in batches of up to
+// batch movies, ordered as in ListMovies. Movies are taken at once, so export
+// is consistent, and the lock is released before fn is called.
+func (r *Repository) ExportMovies(ctx context.Context, filter *model.MovieFilter, batch uint64, fn func([]model.Movie) error) error {
+	const op = "repository.memory.ExportMovies"
+
+	r.mu.RLock()
+	movies := r.findMovies(filter)
+	r.mu.RUnlock()
+
+	for offset := uint64(0); batch > 0 && offset < uint64(len(movies)); offset += batch {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := fn(page(movies, batch, offset)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package memoryrepo
+
+import (
+	"cmp"
+	"context"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+)
+
+// GetFilmography returns movies of the director ordered by release year.
+// Director names are compared ignoring case, diacritics and extra whitespace,
+// so "Pedro Almodóvar" matches " pedro  almodovar".
+func (r *Repository) GetFilmography(ctx context.Context, director string) ([]model.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	director = repo.NormalizeName(director)
+
+	movies := make([]model.Movie, 0)
+	for id, stored := range r.movies {
+		if repo.NormalizeName(stored.Director) == director {
+			movie, _ := r.getMovie(id)
+			movies = append(movies, *movie)
+		}
+	}
+
+	slices.SortFunc(movies, func(a, b model.Movie) int {
+		return cmp.Or(cmp.Compare(a.Year, b.Year), compareByTitle(a, b))
+	})
+
+	return movies, nil
+}
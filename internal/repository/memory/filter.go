@@ -0,0 +1,44 @@
+package memoryrepo
+
+import (
+	"movie-service/internal/model"
+	"slices"
+	"strings"
+)
+
+// matchMovieFilter reports whether the movie (with its tags) matches the filter
+func matchMovieFilter(movie *model.Movie, filter *model.MovieFilter) bool {
+	if filter == nil {
+		return true
+	}
+
+	for _, tag := range filter.TagsAll {
+		if !slices.Contains(movie.Tags, tag) {
+			return false
+		}
+	}
+
+	if len(filter.TagsAny) > 0 && !slices.ContainsFunc(filter.TagsAny, func(tag string) bool {
+		return slices.Contains(movie.Tags, tag)
+	}) {
+		return false
+	}
+
+	if filter.Genre != "" && strings.ToLower(movie.Genre) != strings.ToLower(filter.Genre) {
+		return false
+	}
+
+	if filter.Director != "" && strings.ToLower(movie.Director) != strings.ToLower(filter.Director) {
+		return false
+	}
+
+	if filter.YearFrom != 0 && movie.Year < filter.YearFrom {
+		return false
+	}
+
+	if filter.YearTo != 0 && movie.Year > filter.YearTo {
+		return false
+	}
+
+	return true
+}
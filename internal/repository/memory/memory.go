@@ -0,0 +1,296 @@
+// Package memoryrepo keeps all data of the service in process memory. It is
+// meant for local development and tests: nothing survives restart.
+package memoryrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository is safe for concurrent use. Every method holds the lock for its
+// whole duration, so changes are atomic just like transactions of postgres.
+type Repository struct {
+	mu       locker
+	notifier *repo.LocalNotifier
+	*data
+
+	skipOutbox bool
+}
+
+// Option configures Repository
+type Option func(r *Repository)
+
+// WithoutOutbox stops putting movie events to the outbox. It is meant for
+// setups where no relay drains the outbox, which would grow without bound.
+func WithoutOutbox() Option {
+	return func(r *Repository) {
+		r.skipOutbox = true
+	}
+}
+
+// data is all the data of repository. Transactions work on its copy, which
+// replaces the original on commit.
+type data struct {
+	// Movies are stored without relations, which are kept in separate maps
+	movies    map[string]*model.Movie
+	movieTags map[string]map[string]struct{}
+	tagUsage  map[string]uint32
+
+	ratings     map[ratingKey]*model.Rating
+	reviews     map[string]*model.Review
+	collections map[string]*model.Collection
+
+	watchlist map[string]map[string]time.Time
+	history   map[string]*model.WatchHistoryEntry
+
+	// Sequence number of event is its index in events plus purgedSeq plus
+	// one, since events up to purgedSeq are purged
+	events     []model.MovieEvent
+	purgedSeq  uint64
+	outbox     map[uint64]*outboxEntry
+	webhooks   map[string]*model.Webhook
+	deliveries map[string]*model.WebhookDelivery
+}
+
+type ratingKey struct {
+	movieID string
+	userID  string
+}
+
+func New(opts ...Option) *Repository {
+	r := &Repository{
+		mu:       new(sync.RWMutex),
+		notifier: repo.NewLocalNotifier(),
+		data: &data{
+			movies:      make(map[string]*model.Movie),
+			movieTags:   make(map[string]map[string]struct{}),
+			tagUsage:    make(map[string]uint32),
+			ratings:     make(map[ratingKey]*model.Rating),
+			reviews:     make(map[string]*model.Review),
+			collections: make(map[string]*model.Collection),
+			watchlist:   make(map[string]map[string]time.Time),
+			history:     make(map[string]*model.WatchHistoryEntry),
+			outbox:      make(map[uint64]*outboxEntry),
+			webhooks:    make(map[string]*model.Webhook),
+			deliveries:  make(map[string]*model.WebhookDelivery),
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Notifier returns notifier which is woken up by events of the repository
+func (r *Repository) Notifier() *repo.LocalNotifier {
+	return r.notifier
+}
+
+func (r *Repository) GetMovie(ctx context.Context, id string) (*model.Movie, error) {
+	const op = "repository.memory.GetMovie"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	movie, err := r.getMovie(id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return movie, nil
+}
+
+// getMovie returns copy of the movie along with its relations
+func (r *Repository) getMovie(id string) (*model.Movie, error) {
+	stored, ok := r.movies[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get movie info by id: %w", repo.ErrMovieNotExists)
+	}
+
+	movie := *stored
+	movie.Tags = sortedKeys(r.movieTags[id])
+	movie.CollectionIDs = make([]string, 0)
+	for collectionID, collection := range r.collections {
+		if slices.Contains(collection.MovieIDs, id) {
+			movie.CollectionIDs = append(movie.CollectionIDs, collectionID)
+		}
+	}
+	slices.Sort(movie.CollectionIDs)
+
+	return &movie, nil
+}
+
+// GetMovies returns all movies matching the filter
+func (r *Repository) GetMovies(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.findMovies(filter), nil
+}
+
+// ListMovies returns page of movies matching the filter ordered by title
+func (r *Repository) ListMovies(ctx context.Context, filter *model.MovieFilter, limit, offset uint64) ([]model.Movie, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return page(r.findMovies(filter), limit, offset), nil
+}
+
+// findMovies returns movies matching the filter ordered by title
+func (r *Repository) findMovies(filter *model.MovieFilter) []model.Movie {
+	movies := make([]model.Movie, 0)
+	for id := range r.movies {
+		movie, _ := r.getMovie(id)
+		if matchMovieFilter(movie, filter) {
+			movies = append(movies, *movie)
+		}
+	}
+
+	slices.SortFunc(movies, compareByTitle)
+
+	return movies
+}
+
+func (r *Repository) CreateMovie(ctx context.Context, movie *model.Movie) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.createMovie(movie), nil
+}
+
+// CreateMovies adds all movies at once. IDs are returned in order of movies.
+func (r *Repository) CreateMovies(ctx context.Context, movies []model.Movie) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(movies))
+	for i := range movies {
+		ids = append(ids, r.createMovie(&movies[i]))
+	}
+
+	return ids, nil
+}
+
+func (r *Repository) createMovie(movie *model.Movie) string {
+	created := &model.Movie{
+		ID:       uuid.NewString(),
+		Title:    movie.Title,
+		Genre:    movie.Genre,
+		Director: movie.Director,
+		Year:     movie.Year,
+	}
+	r.movies[created.ID] = created
+
+	snapshot, _ := r.getMovie(created.ID)
+	r.recordMovieEvent(model.MovieEventCreated, snapshot)
+
+	return created.ID
+}
+
+func (r *Repository) UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
+	const op = "repository.memory.UpdateMovie"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.movies[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: failed to update movie info: %w", op, repo.ErrMovieNotExists)
+	}
+
+	if movie.Title != "" {
+		stored.Title = movie.Title
+	}
+	if movie.Genre != "" {
+		stored.Genre = movie.Genre
+	}
+	if movie.Director != "" {
+		stored.Director = movie.Director
+	}
+	if movie.Year != 0 {
+		stored.Year = movie.Year
+	}
+
+	updated, _ := r.getMovie(id)
+	r.recordMovieEvent(model.MovieEventUpdated, updated)
+
+	return updated, nil
+}
+
+// Returning bool val indicates whether movie info was deleted or not
+func (r *Repository) DeleteMovie(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movie, err := r.getMovie(id)
+	if err != nil {
+		return false, nil
+	}
+
+	// Relations are deleted along with the movie, while watchlist and
+	// watch history keep referencing it
+	r.adjustTagUsage(movie.Tags, -1)
+	delete(r.movieTags, id)
+
+	for key := range r.ratings {
+		if key.movieID == id {
+			delete(r.ratings, key)
+		}
+	}
+
+	for reviewID, review := range r.reviews {
+		if review.MovieID == id {
+			delete(r.reviews, reviewID)
+		}
+	}
+
+	for _, collection := range r.collections {
+		collection.MovieIDs = slices.DeleteFunc(collection.MovieIDs, func(movieID string) bool {
+			return movieID == id
+		})
+	}
+
+	delete(r.movies, id)
+
+	r.recordMovieEvent(model.MovieEventDeleted, movie)
+
+	return true, nil
+}
+
+func compareByTitle(a, b model.Movie) int {
+	return cmp.Or(strings.Compare(a.Title, b.Title), strings.Compare(a.ID, b.ID))
+}
+
+// page returns part of items as LIMIT and OFFSET would do
+func page[T any](items []T, limit, offset uint64) []T {
+	if offset >= uint64(len(items)) {
+		return items[:0]
+	}
+	items = items[offset:]
+
+	if limit < uint64(len(items)) {
+		items = items[:limit]
+	}
+
+	return items
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
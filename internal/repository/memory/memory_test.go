@@ -0,0 +1,47 @@
+package memoryrepo_test
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	memoryrepo "movie-service/internal/repository/memory"
+	"movie-service/internal/repository/repotest"
+	"testing"
+	"time"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return memoryrepo.New()
+	})
+}
+
+func TestWithoutOutbox(t *testing.T) {
+	ctx := context.Background()
+	r := memoryrepo.New(memoryrepo.WithoutOutbox())
+
+	movie := &model.Movie{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979}
+	if _, err := r.CreateMovie(ctx, movie); err != nil {
+		t.Fatalf("CreateMovie error = %v", err)
+	}
+	err := r.InTx(ctx, func(r repository.Repository) error {
+		_, err := r.CreateMovie(ctx, movie)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("InTx error = %v", err)
+	}
+
+	events, err := r.GetMovieEvents(ctx, 0, 10)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("GetMovieEvents = %+v, %v, want 2 events", events, err)
+	}
+
+	claimed, err := r.ClaimOutboxEvents(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEvents error = %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Errorf("ClaimOutboxEvents = %+v, want no events in the outbox", claimed)
+	}
+}
@@ -0,0 +1,86 @@
+package memoryrepo
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"slices"
+	"time"
+)
+
+type outboxEntry struct {
+	attempts      uint32
+	nextAttemptAt time.Time
+	lastError     string
+	deliveredAt   time.Time
+}
+
+// ClaimOutboxEvents takes up to limit undelivered events which are due, oldest first.
+// Claimed events are hidden from other relays for lease.
+func (r *Repository) ClaimOutboxEvents(ctx context.Context, limit uint64, lease time.Duration) ([]model.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	due := make([]uint64, 0)
+	for seq, entry := range r.outbox {
+		if entry.deliveredAt.IsZero() && !entry.nextAttemptAt.After(now) {
+			due = append(due, seq)
+		}
+	}
+	slices.Sort(due)
+
+	events := make([]model.OutboxEvent, 0)
+	for _, seq := range page(due, limit, 0) {
+		entry := r.outbox[seq]
+		entry.attempts++
+		entry.nextAttemptAt = now.Add(lease)
+
+		event, _ := r.event(seq)
+		events = append(events, model.OutboxEvent{MovieEvent: cloneEvent(event), Attempts: entry.attempts})
+	}
+
+	return events, nil
+}
+
+// MarkOutboxDelivered marks event as delivered, so it is never claimed again
+func (r *Repository) MarkOutboxDelivered(ctx context.Context, seq uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.outbox[seq]; ok {
+		entry.deliveredAt = time.Now()
+		entry.lastError = ""
+	}
+
+	return nil
+}
+
+// MarkOutboxFailed schedules next delivery attempt of the event
+func (r *Repository) MarkOutboxFailed(ctx context.Context, seq uint64, retryAt time.Time, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.outbox[seq]; ok {
+		entry.nextAttemptAt = retryAt
+		entry.lastError = reason
+	}
+
+	return nil
+}
+
+// PurgeOutbox deletes events delivered before deliveredBefore and returns
+// their number. Events themselves are kept.
+func (r *Repository) PurgeOutbox(ctx context.Context, deliveredBefore time.Time) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var num uint64
+	for seq, entry := range r.outbox {
+		if !entry.deliveredAt.IsZero() && entry.deliveredAt.Before(deliveredBefore) {
+			delete(r.outbox, seq)
+			num++
+		}
+	}
+
+	return num, nil
+}
@@ -0,0 +1,41 @@
+package memoryrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+)
+
+// GetMovieAfter returns movie matching the filter with the smallest ID which is
+// not less than pivot, wrapping around to the smallest ID overall
+func (r *Repository) GetMovieAfter(ctx context.Context, pivot string, filter *model.MovieFilter) (*model.Movie, error) {
+	const op = "repository.memory.GetMovieAfter"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var first, after *model.Movie
+	for id := range r.movies {
+		movie, _ := r.getMovie(id)
+		if !matchMovieFilter(movie, filter) {
+			continue
+		}
+
+		if first == nil || movie.ID < first.ID {
+			first = movie
+		}
+		if movie.ID >= pivot && (after == nil || movie.ID < after.ID) {
+			after = movie
+		}
+	}
+
+	switch {
+	case after != nil:
+		return after, nil
+	case first != nil:
+		return first, nil
+	default:
+		return nil, fmt.Errorf("%s: no movie matches filter: %w", op, repo.ErrMovieNotExists)
+	}
+}
@@ -0,0 +1,46 @@
+package memoryrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"time"
+)
+
+// RateMovie saves user's score for the movie. If user has already rated the movie,
+// previous score is replaced. Aggregated rating of the movie is adjusted incrementally.
+func (r *Repository) RateMovie(ctx context.Context, rating *model.Rating) (*model.Movie, error) {
+	const op = "repository.memory.RateMovie"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movie, ok := r.movies[rating.MovieID]
+	if !ok {
+		return nil, fmt.Errorf("%s: failed to get movie: %w", op, repo.ErrMovieNotExists)
+	}
+
+	now := time.Now()
+	key := ratingKey{movieID: rating.MovieID, userID: rating.UserID}
+	if prev, ok := r.ratings[key]; ok {
+		movie.RatingSum = movie.RatingSum - uint64(prev.Score) + uint64(rating.Score)
+		prev.Score = rating.Score
+		prev.UpdatedAt = now
+	} else {
+		movie.RatingSum += uint64(rating.Score)
+		movie.RatingCount++
+		r.ratings[key] = &model.Rating{
+			MovieID:   rating.MovieID,
+			UserID:    rating.UserID,
+			Score:     rating.Score,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	rated, _ := r.getMovie(rating.MovieID)
+	r.recordMovieEvent(model.MovieEventUpdated, rated)
+
+	return rated, nil
+}
@@ -0,0 +1,153 @@
+package memoryrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateReview saves new review. Every new review waits for moderation
+// and isn't shown publicly until it's approved.
+func (r *Repository) CreateReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	const op = "repository.memory.CreateReview"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.movies[review.MovieID]; !ok {
+		return nil, fmt.Errorf("%s: failed to add review: %w", op, repo.ErrMovieNotExists)
+	}
+
+	now := time.Now()
+	created := &model.Review{
+		ID:        uuid.NewString(),
+		MovieID:   review.MovieID,
+		UserID:    review.UserID,
+		Text:      review.Text,
+		Status:    model.ReviewStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.reviews[created.ID] = created
+
+	newReview := *created
+
+	return &newReview, nil
+}
+
+// ListReviews returns page of approved reviews of the movie, newest first
+func (r *Repository) ListReviews(ctx context.Context, movieID string, limit, offset uint64) ([]model.Review, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reviews := make([]model.Review, 0)
+	for _, review := range r.reviews {
+		if review.MovieID == movieID && review.Status == model.ReviewStatusApproved {
+			reviews = append(reviews, *review)
+		}
+	}
+
+	slices.SortFunc(reviews, func(a, b model.Review) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return page(reviews, limit, offset), nil
+}
+
+// UpdateReview changes text of the review written by the user. Changed review
+// is sent back to moderation.
+func (r *Repository) UpdateReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	const op = "repository.memory.UpdateReview"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.reviews[review.ID]
+	if !ok || stored.UserID != review.UserID {
+		return nil, fmt.Errorf("%s: failed to get review: %w", op, repo.ErrReviewNotExists)
+	}
+
+	r.adjustReviewCount(stored.MovieID, stored.Status, model.ReviewStatusPending)
+	stored.Text = review.Text
+	stored.Status = model.ReviewStatusPending
+	stored.UpdatedAt = time.Now()
+
+	newReview := *stored
+
+	return &newReview, nil
+}
+
+// DeleteReview deletes review written by the user. Returning bool val
+// indicates whether review was deleted or not, review of another user isn't
+// deleted and ErrReviewNotOwned is returned.
+func (r *Repository) DeleteReview(ctx context.Context, id, userID string) (bool, error) {
+	const op = "repository.memory.DeleteReview"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.reviews[id]
+	if !ok {
+		return false, nil
+	}
+	if review.UserID != userID {
+		return false, fmt.Errorf("%s: %w", op, repo.ErrReviewNotOwned)
+	}
+
+	r.adjustReviewCount(review.MovieID, review.Status, "")
+	delete(r.reviews, id)
+
+	return true, nil
+}
+
+// ModerateReview sets moderation status of the review
+func (r *Repository) ModerateReview(ctx context.Context, id string, status model.ReviewStatus) (*model.Review, error) {
+	const op = "repository.memory.ModerateReview"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.reviews[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: failed to get review: %w", op, repo.ErrReviewNotExists)
+	}
+
+	r.adjustReviewCount(stored.MovieID, stored.Status, status)
+	stored.Status = status
+	stored.UpdatedAt = time.Now()
+
+	newReview := *stored
+
+	return &newReview, nil
+}
+
+// adjustReviewCount keeps number of approved reviews of the movie in sync
+// when review moves from one status to another, and records updated event of
+// the movie if the number has changed
+func (r *Repository) adjustReviewCount(movieID string, prev, cur model.ReviewStatus) {
+	movie, ok := r.movies[movieID]
+	if !ok {
+		return
+	}
+
+	prevCount := movie.ReviewCount
+	if prev == model.ReviewStatusApproved {
+		movie.ReviewCount--
+	}
+	if cur == model.ReviewStatusApproved {
+		movie.ReviewCount++
+	}
+	if movie.ReviewCount == prevCount {
+		return
+	}
+
+	updated, _ := r.getMovie(movieID)
+	r.recordMovieEvent(model.MovieEventUpdated, updated)
+}
@@ -0,0 +1,71 @@
+package memoryrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+	"strings"
+)
+
+// Weights of similarity features, they sum up to 1. Same as in postgres.
+const (
+	weightSameGenre    = 0.35
+	weightSameDirector = 0.35
+	weightEra          = 0.15
+	weightTitle        = 0.15
+)
+
+// GetSimilarMovies returns movies most similar to the given one by genre,
+// director, release year and title, best matches first
+func (r *Repository) GetSimilarMovies(ctx context.Context, id string, limit uint64) ([]model.SimilarMovie, error) {
+	const op = "repository.memory.GetSimilarMovies"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	movie, err := r.getMovie(id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	similar := make([]model.SimilarMovie, 0)
+	for candidateID := range r.movies {
+		if candidateID == id {
+			continue
+		}
+		candidate, _ := r.getMovie(candidateID)
+
+		s := model.SimilarMovie{
+			Movie:           *candidate,
+			SameGenre:       strings.ToLower(candidate.Genre) == strings.ToLower(movie.Genre),
+			SameDirector:    strings.ToLower(candidate.Director) == strings.ToLower(movie.Director),
+			YearDiff:        max(candidate.Year, movie.Year) - min(candidate.Year, movie.Year),
+			TitleSimilarity: repo.TrigramSimilarity(candidate.Title, movie.Title),
+		}
+
+		// Candidates share at least one feature with the movie
+		if !s.SameGenre && !s.SameDirector && s.YearDiff > repo.SimilarEraYears && s.TitleSimilarity < repo.SimilarTitleThreshold {
+			continue
+		}
+
+		s.Score = weightTitle * s.TitleSimilarity
+		if s.SameGenre {
+			s.Score += weightSameGenre
+		}
+		if s.SameDirector {
+			s.Score += weightSameDirector
+		}
+		s.Score += weightEra * max(0, 1-float64(s.YearDiff)/repo.SimilarEraYears)
+
+		similar = append(similar, s)
+	}
+
+	slices.SortFunc(similar, func(a, b model.SimilarMovie) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.ID, b.ID))
+	})
+
+	return page(similar, limit, 0), nil
+}
@@ -0,0 +1,132 @@
+package memoryrepo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GetCatalogStats computes facet counts and histograms over movies matching
+// the filter of the query
+func (r *Repository) GetCatalogStats(ctx context.Context, statsQuery *model.CatalogStatsQuery) (*model.CatalogStats, error) {
+	const op = "repository.memory.GetCatalogStats"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	movies := r.findMovies(&statsQuery.Filter)
+
+	stats := &model.CatalogStats{
+		TotalMovies: uint64(len(movies)),
+		Facets:      make([]model.FacetCounts, 0, len(statsQuery.Facets)),
+	}
+	for _, facet := range statsQuery.Facets {
+		buckets, err := facetBuckets(facet, statsQuery, movies)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		stats.Facets = append(stats.Facets, model.FacetCounts{Facet: facet, Buckets: buckets})
+	}
+
+	return stats, nil
+}
+
+// facetBuckets groups movies by value of the facet
+func facetBuckets(facet model.Facet, statsQuery *model.CatalogStatsQuery, movies []model.Movie) ([]model.FacetBucket, error) {
+	switch facet {
+	case model.FacetGenre, model.FacetDirector, model.FacetTag:
+		// Genres and directors are grouped case-insensitively the same way
+		// filter matches them, the bucket is named after the least spelling
+		counts := make(map[string]*model.FacetBucket)
+		count := func(key, value string) {
+			bucket, ok := counts[key]
+			if !ok {
+				bucket = &model.FacetBucket{Value: value}
+				counts[key] = bucket
+			}
+			bucket.Value = min(bucket.Value, value)
+			bucket.Count++
+		}
+		for _, movie := range movies {
+			switch facet {
+			case model.FacetGenre:
+				count(strings.ToLower(movie.Genre), movie.Genre)
+			case model.FacetDirector:
+				count(strings.ToLower(movie.Director), movie.Director)
+			default:
+				for _, tag := range movie.Tags {
+					count(tag, tag)
+				}
+			}
+		}
+
+		buckets := make([]model.FacetBucket, 0, len(counts))
+		for _, bucket := range counts {
+			buckets = append(buckets, *bucket)
+		}
+
+		slices.SortFunc(buckets, func(a, b model.FacetBucket) int {
+			return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Value, b.Value))
+		})
+
+		return page(buckets, statsQuery.TopLimit, 0), nil
+	case model.FacetYear:
+		if statsQuery.YearBucket == 0 {
+			return nil, errors.New("width of year bucket must be positive")
+		}
+
+		return histogram(movies, func(movie *model.Movie) (uint32, uint32, bool) {
+			from := movie.Year / statsQuery.YearBucket * statsQuery.YearBucket
+
+			return from, from + statsQuery.YearBucket - 1, true
+		}), nil
+	case model.FacetRating:
+		// Average rating rounded down, e.g. movies rated 7.0-7.99 fall into bucket 7
+		return histogram(movies, func(movie *model.Movie) (uint32, uint32, bool) {
+			if movie.RatingCount == 0 {
+				return 0, 0, false
+			}
+			bucket := uint32(movie.RatingSum / uint64(movie.RatingCount))
+
+			return bucket, bucket, true
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown facet %q", facet)
+	}
+}
+
+// histogram counts movies by buckets returned by bucketOf, ordered by
+// lower bound of bucket. Movies for which bucketOf returns false are skipped.
+func histogram(movies []model.Movie, bucketOf func(*model.Movie) (uint32, uint32, bool)) []model.FacetBucket {
+	byFrom := make(map[uint32]*model.FacetBucket)
+	for i := range movies {
+		from, to, ok := bucketOf(&movies[i])
+		if !ok {
+			continue
+		}
+
+		bucket, ok := byFrom[from]
+		if !ok {
+			bucket = &model.FacetBucket{Value: strconv.FormatUint(uint64(from), 10), From: from, To: to}
+			byFrom[from] = bucket
+		}
+		bucket.Count++
+	}
+
+	buckets := make([]model.FacetBucket, 0, len(byFrom))
+	for _, bucket := range byFrom {
+		buckets = append(buckets, *bucket)
+	}
+
+	slices.SortFunc(buckets, func(a, b model.FacetBucket) int {
+		return cmp.Compare(a.From, b.From)
+	})
+
+	return buckets
+}
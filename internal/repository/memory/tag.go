@@ -0,0 +1,104 @@
+package memoryrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+	"strings"
+)
+
+// AddTags labels the movie with tags. Tags which the movie already has are ignored.
+func (r *Repository) AddTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error) {
+	const op = "repository.memory.AddTags"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.movies[movieID]; !ok {
+		return nil, fmt.Errorf("%s: failed to get movie: %w", op, repo.ErrMovieNotExists)
+	}
+
+	labels, ok := r.movieTags[movieID]
+	if !ok {
+		labels = make(map[string]struct{})
+		r.movieTags[movieID] = labels
+	}
+
+	added := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if _, ok := r.tagUsage[tag]; !ok {
+			r.tagUsage[tag] = 0
+		}
+
+		if _, ok := labels[tag]; !ok {
+			labels[tag] = struct{}{}
+			added = append(added, tag)
+		}
+	}
+	r.adjustTagUsage(added, 1)
+
+	movie, _ := r.getMovie(movieID)
+	if len(added) > 0 {
+		r.recordMovieEvent(model.MovieEventUpdated, movie)
+	}
+
+	return movie, nil
+}
+
+// RemoveTags removes tags from the movie. Tags which the movie doesn't have are ignored.
+func (r *Repository) RemoveTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error) {
+	const op = "repository.memory.RemoveTags"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.movies[movieID]; !ok {
+		return nil, fmt.Errorf("%s: failed to get movie: %w", op, repo.ErrMovieNotExists)
+	}
+
+	labels := r.movieTags[movieID]
+	removed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if _, ok := labels[tag]; ok {
+			delete(labels, tag)
+			removed = append(removed, tag)
+		}
+	}
+	r.adjustTagUsage(removed, -1)
+
+	movie, _ := r.getMovie(movieID)
+	if len(removed) > 0 {
+		r.recordMovieEvent(model.MovieEventUpdated, movie)
+	}
+
+	return movie, nil
+}
+
+// ListTags returns tags which are in use along with number of movies
+// labeled by them, most used first. Empty prefix matches all tags.
+func (r *Repository) ListTags(ctx context.Context, prefix string, limit uint64) ([]model.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]model.Tag, 0)
+	for name, usage := range r.tagUsage {
+		if usage > 0 && strings.HasPrefix(name, prefix) {
+			tags = append(tags, model.Tag{Name: name, UsageCount: usage})
+		}
+	}
+
+	slices.SortFunc(tags, func(a, b model.Tag) int {
+		return cmp.Or(cmp.Compare(b.UsageCount, a.UsageCount), strings.Compare(a.Name, b.Name))
+	})
+
+	return page(tags, limit, 0), nil
+}
+
+func (r *Repository) adjustTagUsage(tags []string, delta int) {
+	for _, tag := range tags {
+		r.tagUsage[tag] = uint32(int(r.tagUsage[tag]) + delta)
+	}
+}
@@ -0,0 +1,106 @@
+package memoryrepo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	repo "movie-service/internal/repository"
+	"slices"
+)
+
+// locker is the lock of repository. Repository bound to transaction doesn't
+// lock at all, since the transaction holds the lock of its repository.
+type locker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+}
+
+type noLock struct{}
+
+func (noLock) Lock()    {}
+func (noLock) Unlock()  {}
+func (noLock) RLock()   {}
+func (noLock) RUnlock() {}
+
+// InTx implements repository.TxManager. Transaction holds the lock for its
+// whole duration, so it never conflicts with others and is never retried.
+// fn works on a copy of the data, which replaces the data of repository once
+// fn succeeds.
+func (r *Repository) InTx(ctx context.Context, fn func(r repo.Repository) error) error {
+	const op = "repository.memory.InTx"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bound := &Repository{
+		mu: noLock{},
+		// Listeners are woken up once the whole transaction is committed
+		notifier:   repo.NewLocalNotifier(),
+		data:       r.data.clone(),
+		skipOutbox: r.skipOutbox,
+	}
+	if err := fn(bound); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	*r.data = *bound.data
+	r.notifier.Broadcast()
+
+	return nil
+}
+
+// clone returns deep copy of the data, so changes of the copy don't leak
+// into the original
+func (d *data) clone() *data {
+	return &data{
+		movies:      clonePtrs(d.movies, nil),
+		movieTags:   cloneNested(d.movieTags),
+		tagUsage:    maps.Clone(d.tagUsage),
+		ratings:     clonePtrs(d.ratings, nil),
+		reviews:     clonePtrs(d.reviews, nil),
+		collections: clonePtrs(d.collections, cloneCollection),
+		watchlist:   cloneNested(d.watchlist),
+		history:     clonePtrs(d.history, nil),
+		events:      slices.Clone(d.events),
+		purgedSeq:   d.purgedSeq,
+		outbox:      clonePtrs(d.outbox, nil),
+		webhooks:    clonePtrs(d.webhooks, cloneWebhook),
+		deliveries:  clonePtrs(d.deliveries, nil),
+	}
+}
+
+// clonePtrs copies map along with values it points to. Values are copied
+// with cloneValue if they hold slices, which would be shared otherwise.
+func clonePtrs[K comparable, V any](m map[K]*V, cloneValue func(*V) *V) map[K]*V {
+	clone := make(map[K]*V, len(m))
+	for k, v := range m {
+		if cloneValue != nil {
+			clone[k] = cloneValue(v)
+			continue
+		}
+
+		copied := *v
+		clone[k] = &copied
+	}
+
+	return clone
+}
+
+func cloneNested[K1, K2 comparable, V any](m map[K1]map[K2]V) map[K1]map[K2]V {
+	clone := make(map[K1]map[K2]V, len(m))
+	for k, v := range m {
+		clone[k] = maps.Clone(v)
+	}
+
+	return clone
+}
@@ -0,0 +1,140 @@
+package memoryrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Returning bool val indicates whether movie was added or it had already been
+// in the watchlist
+func (r *Repository) AddToWatchlist(ctx context.Context, userID, movieID string) (bool, error) {
+	const op = "repository.memory.AddToWatchlist"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.movies[movieID]; !ok {
+		return false, fmt.Errorf("%s: failed to add movie to watchlist: %w", op, repo.ErrMovieNotExists)
+	}
+
+	list, ok := r.watchlist[userID]
+	if !ok {
+		list = make(map[string]time.Time)
+		r.watchlist[userID] = list
+	}
+
+	if _, ok := list[movieID]; ok {
+		return false, nil
+	}
+	list[movieID] = time.Now()
+
+	return true, nil
+}
+
+// Returning bool val indicates whether movie was removed or it hadn't been
+// in the watchlist
+func (r *Repository) RemoveFromWatchlist(ctx context.Context, userID, movieID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.watchlist[userID][movieID]; !ok {
+		return false, nil
+	}
+	delete(r.watchlist[userID], movieID)
+
+	return true, nil
+}
+
+// ListWatchlist returns page of user's watchlist, recently added first
+func (r *Repository) ListWatchlist(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchlistEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]model.WatchlistEntry, 0)
+	for movieID, addedAt := range r.watchlist[userID] {
+		entries = append(entries, model.WatchlistEntry{UserID: userID, MovieID: movieID, AddedAt: addedAt})
+	}
+
+	slices.SortFunc(entries, func(a, b model.WatchlistEntry) int {
+		return cmp.Or(b.AddedAt.Compare(a.AddedAt), strings.Compare(a.MovieID, b.MovieID))
+	})
+
+	entries = page(entries, limit, offset)
+	for i := range entries {
+		entries[i].Movie = r.watchedMovie(entries[i].MovieID)
+	}
+
+	return entries, nil
+}
+
+// RecordWatch adds entry to user's watch history. Zero WatchedAt is replaced
+// with current time.
+func (r *Repository) RecordWatch(ctx context.Context, entry *model.WatchHistoryEntry) (*model.WatchHistoryEntry, error) {
+	const op = "repository.memory.RecordWatch"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.movies[entry.MovieID]; !ok {
+		return nil, fmt.Errorf("%s: failed to add watch history entry: %w", op, repo.ErrMovieNotExists)
+	}
+
+	created := &model.WatchHistoryEntry{
+		ID:        uuid.NewString(),
+		UserID:    entry.UserID,
+		MovieID:   entry.MovieID,
+		WatchedAt: entry.WatchedAt,
+		Progress:  entry.Progress,
+	}
+	if created.WatchedAt.IsZero() {
+		created.WatchedAt = time.Now()
+	}
+	r.history[created.ID] = created
+
+	newEntry := *created
+	newEntry.Movie = r.watchedMovie(newEntry.MovieID)
+
+	return &newEntry, nil
+}
+
+// ListWatchHistory returns page of user's watch history, most recent first
+func (r *Repository) ListWatchHistory(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchHistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]model.WatchHistoryEntry, 0)
+	for _, entry := range r.history {
+		if entry.UserID == userID {
+			entries = append(entries, *entry)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b model.WatchHistoryEntry) int {
+		return cmp.Or(b.WatchedAt.Compare(a.WatchedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	entries = page(entries, limit, offset)
+	for i := range entries {
+		entries[i].Movie = r.watchedMovie(entries[i].MovieID)
+	}
+
+	return entries, nil
+}
+
+// watchedMovie returns the movie or nil if it was deleted from catalog
+func (r *Repository) watchedMovie(id string) *model.Movie {
+	movie, err := r.getMovie(id)
+	if err != nil {
+		return nil
+	}
+
+	return movie
+}
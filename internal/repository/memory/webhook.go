@@ -0,0 +1,213 @@
+package memoryrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := &model.Webhook{
+		ID:         uuid.NewString(),
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		EventTypes: append(make([]model.MovieEventType, 0, len(webhook.EventTypes)), webhook.EventTypes...),
+		CreatedAt:  time.Now(),
+	}
+	r.webhooks[created.ID] = created
+
+	return cloneWebhook(created), nil
+}
+
+func (r *Repository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]model.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, *cloneWebhook(webhook))
+	}
+
+	slices.SortFunc(webhooks, func(a, b model.Webhook) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes the webhook along with its deliveries
+func (r *Repository) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return false, nil
+	}
+	delete(r.webhooks, id)
+
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+
+	return true, nil
+}
+
+// ListDeliveries returns deliveries of the webhook, newest first.
+// Empty status matches deliveries in any status.
+func (r *Repository) ListDeliveries(ctx context.Context, webhookID string, status model.DeliveryStatus, limit, offset uint64) ([]model.WebhookDelivery, error) {
+	const op = "repository.memory.ListDeliveries"
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.webhooks[webhookID]; !ok {
+		return nil, fmt.Errorf("%s: failed to get webhook: %w", op, repo.ErrWebhookNotExists)
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+
+	slices.SortFunc(deliveries, func(a, b model.WebhookDelivery) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return page(deliveries, limit, offset), nil
+}
+
+// CreateDelivery schedules one more delivery of the event to the webhook
+// regardless of previous deliveries
+func (r *Repository) CreateDelivery(ctx context.Context, webhookID string, eventSeq uint64) (*model.WebhookDelivery, error) {
+	const op = "repository.memory.CreateDelivery"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[webhookID]; !ok {
+		return nil, fmt.Errorf("%s: failed to get webhook: %w", op, repo.ErrWebhookNotExists)
+	}
+
+	event, ok := r.event(eventSeq)
+	if !ok {
+		return nil, fmt.Errorf("%s: failed to get event: %w", op, repo.ErrEventNotExists)
+	}
+
+	delivery := *r.addDelivery(webhookID, event)
+
+	return &delivery, nil
+}
+
+// ClaimDeliveries takes up to limit pending deliveries which are due, oldest first.
+// Claimed deliveries are hidden from other dispatchers for lease.
+func (r *Repository) ClaimDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]model.WebhookDispatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	due := make([]*model.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.Status == model.DeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b *model.WebhookDelivery) int {
+		return a.NextAttemptAt.Compare(b.NextAttemptAt)
+	})
+	due = page(due, limit, 0)
+
+	slices.SortFunc(due, func(a, b *model.WebhookDelivery) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	dispatches := make([]model.WebhookDispatch, 0, len(due))
+	for _, delivery := range due {
+		delivery.Attempts++
+		delivery.NextAttemptAt = now.Add(lease)
+
+		webhook := r.webhooks[delivery.WebhookID]
+		event, _ := r.event(delivery.EventSeq)
+		dispatches = append(dispatches, model.WebhookDispatch{
+			Delivery: *delivery,
+			URL:      webhook.URL,
+			Secret:   webhook.Secret,
+			Event:    cloneEvent(event),
+		})
+	}
+
+	return dispatches, nil
+}
+
+// MarkDeliverySucceeded marks delivery as delivered with the response status of the endpoint
+func (r *Repository) MarkDeliverySucceeded(ctx context.Context, id string, responseStatus int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery, ok := r.deliveries[id]; ok {
+		now := time.Now()
+		delivery.Status = model.DeliveryStatusDelivered
+		delivery.ResponseStatus = responseStatus
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	}
+
+	return nil
+}
+
+// MarkDeliveryFailed schedules next attempt of the delivery at retryAt,
+// or moves it to dead letters if retryAt is nil
+func (r *Repository) MarkDeliveryFailed(ctx context.Context, id string, retryAt *time.Time, reason string, responseStatus int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery, ok := r.deliveries[id]; ok {
+		delivery.ResponseStatus = responseStatus
+		delivery.LastError = reason
+		if retryAt != nil {
+			delivery.NextAttemptAt = *retryAt
+		} else {
+			delivery.Status = model.DeliveryStatusDead
+		}
+	}
+
+	return nil
+}
+
+// addDelivery schedules delivery of the event to the webhook
+func (r *Repository) addDelivery(webhookID string, event *model.MovieEvent) *model.WebhookDelivery {
+	now := time.Now()
+	delivery := &model.WebhookDelivery{
+		ID:            uuid.NewString(),
+		WebhookID:     webhookID,
+		EventSeq:      event.Seq,
+		EventType:     event.Type,
+		Status:        model.DeliveryStatusPending,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+	r.deliveries[delivery.ID] = delivery
+
+	return delivery
+}
+
+func cloneWebhook(webhook *model.Webhook) *model.Webhook {
+	clone := *webhook
+	clone.EventTypes = slices.Clone(webhook.EventTypes)
+
+	return &clone
+}
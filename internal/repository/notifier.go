@@ -0,0 +1,50 @@
+package repository
+
+import "sync"
+
+// LocalNotifier is a Notifier of storages which run within the process of
+// the service, so they know about new events right when they are recorded
+type LocalNotifier struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func NewLocalNotifier() *LocalNotifier {
+	return &LocalNotifier{
+		subs: make(map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns channel receiving wakeups and function cancelling subscription.
+// Wakeups are coalesced, so single wakeup may stand for several events.
+func (n *LocalNotifier) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	n.subs[ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		delete(n.subs, ch)
+		n.mu.Unlock()
+	}
+}
+
+// Close does nothing as there is no connection to close
+func (n *LocalNotifier) Close() error {
+	return nil
+}
+
+// Broadcast wakes up all subscribers
+func (n *LocalNotifier) Broadcast() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
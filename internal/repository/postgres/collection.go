@@ -0,0 +1,365 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func (r *Repository) CreateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error) {
+	const op = "repository.postgres.CreateCollection"
+
+	var newCollection model.Collection
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		query, args, err := r.builder.Insert("collections").
+			Columns("name", "description", "kind").
+			Values(collection.Name, collection.Description, collection.Kind).
+			Suffix("RETURNING *").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &newCollection, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to add collection: %w", op, err)
+		}
+
+		if err := r.insertCollectionMovies(ctx, tx, newCollection.ID, collection.MovieIDs); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		newCollection.MovieIDs = collection.MovieIDs
+
+		if err := r.recordMembershipChanges(ctx, tx, nil, collection.MovieIDs); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newCollection, nil
+}
+
+func (r *Repository) GetCollection(ctx context.Context, id string) (*model.Collection, error) {
+	const op = "repository.postgres.GetCollection"
+
+	db := r.reader(ctx)
+
+	query, args, err := r.builder.Select("*").
+		From("collections").
+		Where(sq.Eq{"collection_id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var collection model.Collection
+	err = db.GetContext(ctx, &collection, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: failed to get collection: %w", op, repo.ErrCollectionNotExists)
+		}
+
+		return nil, fmt.Errorf("%s: failed to get collection: %w", op, err)
+	}
+
+	collection.MovieIDs, err = r.collectionMovieIDs(ctx, db, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &collection, nil
+}
+
+// UpdateCollection changes non-empty fields of the collection. Membership
+// isn't affected, use SetCollectionMovies for that.
+func (r *Repository) UpdateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error) {
+	const op = "repository.postgres.UpdateCollection"
+
+	builder := r.builder.Update("collections").
+		Set("updated_at", sq.Expr("now()"))
+	if collection.Name != "" {
+		builder = builder.Set("name", collection.Name)
+	}
+	if collection.Description != "" {
+		builder = builder.Set("description", collection.Description)
+	}
+	if collection.Kind != "" {
+		builder = builder.Set("kind", collection.Kind)
+	}
+
+	query, args, err := builder.
+		Where(sq.Eq{"collection_id": collection.ID}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var newCollection model.Collection
+	err = r.db.GetContext(ctx, &newCollection, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: failed to update collection: %w", op, repo.ErrCollectionNotExists)
+		}
+
+		return nil, fmt.Errorf("%s: failed to update collection: %w", op, err)
+	}
+
+	newCollection.MovieIDs, err = r.collectionMovieIDs(ctx, r.db, newCollection.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &newCollection, nil
+}
+
+// SetCollectionMovies replaces movies of the collection. Order of movieIDs
+// defines order of movies in the collection.
+func (r *Repository) SetCollectionMovies(ctx context.Context, id string, movieIDs []string) (*model.Collection, error) {
+	const op = "repository.postgres.SetCollectionMovies"
+
+	var collection model.Collection
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		query, args, err := r.builder.Update("collections").
+			Set("updated_at", sq.Expr("now()")).
+			Where(sq.Eq{"collection_id": id}).
+			Suffix("RETURNING *").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &collection, query, args...); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%s: failed to update collection: %w", op, repo.ErrCollectionNotExists)
+			}
+
+			return fmt.Errorf("%s: failed to update collection: %w", op, err)
+		}
+
+		before, err := r.collectionMovieIDs(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err = r.builder.Delete("collection_movies").
+			Where(sq.Eq{"collection_id": id}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to clear collection: %w", op, err)
+		}
+
+		if err := r.insertCollectionMovies(ctx, tx, id, movieIDs); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		collection.MovieIDs = movieIDs
+
+		if err := r.recordMembershipChanges(ctx, tx, before, movieIDs); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+// Returning bool val indicates whether collection was deleted or not
+func (r *Repository) DeleteCollection(ctx context.Context, id string) (bool, error) {
+	const op = "repository.postgres.DeleteCollection"
+
+	var deleted bool
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		before, err := r.collectionMovieIDs(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := r.builder.Delete("collections").
+			Where(sq.Eq{"collection_id": id}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("%s: failed to delete collection: %w", op, err)
+		}
+
+		num, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: failed to get number of deleted rows: %w", op, err)
+		}
+		deleted = num > 0
+
+		if err := r.recordMembershipChanges(ctx, tx, before, nil); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+// GetCollectionMovies returns movies of the collection in collection order
+func (r *Repository) GetCollectionMovies(ctx context.Context, id string) ([]model.Movie, error) {
+	const op = "repository.postgres.GetCollectionMovies"
+
+	db := r.reader(ctx)
+
+	if _, err := r.GetCollection(ctx, id); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.builder.Select("m.*").
+		From("collection_movies cm").
+		Join("movies m ON m.movie_id = cm.movie_id").
+		Where(sq.Eq{"cm.collection_id": id}).
+		OrderBy("cm.position").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var movies []model.Movie
+	err = db.SelectContext(ctx, &movies, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get movies of collection: %w", op, err)
+	}
+
+	if err := r.loadRelations(ctx, db, moviePtrs(movies)...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return movies, nil
+}
+
+func (r *Repository) insertCollectionMovies(ctx context.Context, tx *sqlx.Tx, id string, movieIDs []string) error {
+	if len(movieIDs) == 0 {
+		return nil
+	}
+
+	builder := r.builder.Insert("collection_movies").
+		Columns("collection_id", "movie_id", "position")
+	for i, movieID := range movieIDs {
+		builder = builder.Values(id, movieID, i+1)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("failed to add movies to collection: %w", repo.ErrMovieNotExists)
+		}
+
+		return fmt.Errorf("failed to add movies to collection: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) collectionMovieIDs(ctx context.Context, q sqlx.QueryerContext, id string) ([]string, error) {
+	query, args, err := r.builder.Select("movie_id").
+		From("collection_movies").
+		Where(sq.Eq{"collection_id": id}).
+		OrderBy("position").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	movieIDs := make([]string, 0)
+	if err := sqlx.SelectContext(ctx, q, &movieIDs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get movies of collection: %w", err)
+	}
+
+	return movieIDs, nil
+}
+
+// loadCollectionIDs fills in IDs of collections the movies belong to
+func (r *Repository) loadCollectionIDs(ctx context.Context, q sqlx.QueryerContext, movies ...*model.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(movies))
+	byID := make(map[string][]*model.Movie, len(movies))
+	for _, movie := range movies {
+		movie.CollectionIDs = make([]string, 0)
+		if _, ok := byID[movie.ID]; !ok {
+			ids = append(ids, movie.ID)
+		}
+		byID[movie.ID] = append(byID[movie.ID], movie)
+	}
+
+	query, args, err := r.builder.Select("movie_id", "collection_id").
+		From("collection_movies").
+		Where(sq.Expr("movie_id = ANY(?)", pq.Array(ids))).
+		OrderBy("collection_id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var memberships []struct {
+		MovieID      string `db:"movie_id"`
+		CollectionID string `db:"collection_id"`
+	}
+	if err := sqlx.SelectContext(ctx, q, &memberships, query, args...); err != nil {
+		return fmt.Errorf("failed to get collections of movies: %w", err)
+	}
+
+	for _, m := range memberships {
+		for _, movie := range byID[m.MovieID] {
+			movie.CollectionIDs = append(movie.CollectionIDs, m.CollectionID)
+		}
+	}
+
+	return nil
+}
+
+// recordMembershipChanges records updated events of movies which joined or
+// left the collection, since collection IDs are part of the movie. Movies are
+// locked in order of IDs, so concurrent changes of collections don't deadlock.
+func (r *Repository) recordMembershipChanges(ctx context.Context, tx *sqlx.Tx, before, after []string) error {
+	changed := repo.ChangedMembers(before, after)
+	movies := make([]*model.Movie, 0, len(changed))
+	for _, movieID := range changed {
+		if err := r.lockMovie(ctx, tx, movieID); err != nil {
+			return err
+		}
+
+		movie, err := r.getMovie(ctx, tx, movieID)
+		if err != nil {
+			return err
+		}
+		movies = append(movies, movie)
+	}
+
+	return r.recordMovieEvents(ctx, tx, model.MovieEventUpdated, movies...)
+}
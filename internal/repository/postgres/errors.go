@@ -0,0 +1,30 @@
+package postgresrepo
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	codeForeignKeyViolation  = "23503"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// isForeignKeyViolation reports whether err is caused by reference
+// to non-existent row
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+
+	return errors.As(err, &pqErr) && pqErr.Code == codeForeignKeyViolation
+}
+
+// isSerializationFailure reports whether err is caused by conflict with
+// concurrent transaction, so the transaction may succeed if retried
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+
+	return errors.As(err, &pqErr) &&
+		(pqErr.Code == codeSerializationFailure || pqErr.Code == codeDeadlockDetected)
+}
@@ -0,0 +1,236 @@
+package postgresrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// MovieEventsChannel is a channel which is notified about new movie events
+const MovieEventsChannel = "movie_events"
+
+// movieEventRow is a movie event with movie snapshot encoded as JSON.
+// EventID is assigned on insert and refers to the event within database,
+// while sequence number is assigned only on commit.
+type movieEventRow struct {
+	model.MovieEvent
+	EventID int64  `db:"event_id"`
+	Payload []byte `db:"movie"`
+}
+
+func (row *movieEventRow) decode() (model.MovieEvent, error) {
+	event := row.MovieEvent
+	if err := json.Unmarshal(row.Payload, &event.Movie); err != nil {
+		return model.MovieEvent{}, fmt.Errorf("failed to decode movie of event %d: %w", row.Seq, err)
+	}
+
+	return event, nil
+}
+
+// GetMovieEvents returns events which happened after the one with afterSeq
+// sequence number, oldest first. Events get sequence numbers on commit, so
+// events of transaction in progress, even the current one, are not returned.
+// ErrEventsPurged is returned if some of the events are already purged.
+func (r *Repository) GetMovieEvents(ctx context.Context, afterSeq uint64, limit uint64) ([]model.MovieEvent, error) {
+	const op = "repository.postgres.GetMovieEvents"
+
+	query, args, err := r.builder.Select("*").
+		From("movie_events").
+		Where(sq.Gt{"seq": afterSeq}).
+		OrderBy("seq").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []movieEventRow
+	err = r.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get movie events: %w", op, err)
+	}
+
+	events := make([]model.MovieEvent, 0, len(rows))
+	for _, row := range rows {
+		event, err := row.decode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, event)
+	}
+
+	// Purged events are checked after reading, so events purged while they
+	// are read are not missed
+	var purgedSeq uint64
+	if err := r.db.GetContext(ctx, &purgedSeq, "SELECT purged_seq FROM movie_events_purged"); err != nil {
+		return nil, fmt.Errorf("%s: failed to get purged movie events: %w", op, err)
+	}
+	if afterSeq < purgedSeq {
+		return nil, fmt.Errorf("%s: events up to %d: %w", op, purgedSeq, repo.ErrEventsPurged)
+	}
+
+	return events, nil
+}
+
+// PurgeMovieEvents deletes events which occurred before occurredBefore and
+// returns their number. Events are purged from the oldest one up to the
+// first event still waiting in the outbox or for webhook delivery, and the
+// latest event is always kept, so purged events are never needed again.
+// Delivered outbox entries and webhook deliveries go along with events.
+func (r *Repository) PurgeMovieEvents(ctx context.Context, occurredBefore time.Time) (uint64, error) {
+	const op = "repository.postgres.PurgeMovieEvents"
+
+	// Sequence numbers are given on commit in order, so every event below
+	// the latest visible one is committed already
+	const horizonQuery = `SELECT max(seq) FROM movie_events
+		WHERE occurred_at < $1
+			AND seq < (SELECT max(seq) FROM movie_events)
+			AND seq < ALL (
+				SELECT e.seq FROM outbox o JOIN movie_events e USING (event_id)
+				WHERE o.delivered_at IS NULL AND e.seq IS NOT NULL
+			)
+			AND seq < ALL (
+				SELECT e.seq FROM webhook_deliveries d JOIN movie_events e USING (event_id)
+				WHERE d.status = 'pending' AND e.seq IS NOT NULL
+			)`
+
+	var num int64
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		var horizon *uint64
+		if err := tx.GetContext(ctx, &horizon, horizonQuery, occurredBefore); err != nil {
+			return fmt.Errorf("%s: failed to find purged movie events: %w", op, err)
+		}
+		if horizon == nil {
+			return nil
+		}
+
+		res, err := tx.ExecContext(ctx, "DELETE FROM movie_events WHERE seq <= $1", *horizon)
+		if err != nil {
+			return fmt.Errorf("%s: failed to purge movie events: %w", op, err)
+		}
+		if num, err = res.RowsAffected(); err != nil {
+			return fmt.Errorf("%s: failed to get number of purged events: %w", op, err)
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE movie_events_purged SET purged_seq = greatest(purged_seq, $1)", *horizon)
+		if err != nil {
+			return fmt.Errorf("%s: failed to save purged movie events: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(num), nil
+}
+
+// GetLastMovieEventSeq returns sequence number of the latest movie event
+// or 0 if there are no events yet
+func (r *Repository) GetLastMovieEventSeq(ctx context.Context) (uint64, error) {
+	const op = "repository.postgres.GetLastMovieEventSeq"
+
+	query, args, err := r.builder.Select("COALESCE(max(seq), 0)").
+		From("movie_events").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var seq uint64
+	if err := r.db.GetContext(ctx, &seq, query, args...); err != nil {
+		return 0, fmt.Errorf("%s: failed to get last movie event: %w", op, err)
+	}
+
+	return seq, nil
+}
+
+// recordMovieEvent saves event about the movie change within the transaction
+// of the change, puts it to the outbox unless disabled by WithoutOutbox and
+// schedules webhook deliveries unless disabled by WithoutWebhooks. Sequence
+// number is given to the event by trigger right before commit, which also
+// notifies listeners (see migration 000013). So writers of events don't
+// wait for each other until they commit.
+func (r *Repository) recordMovieEvent(ctx context.Context, tx *sqlx.Tx, eventType model.MovieEventType, movie *model.Movie) error {
+	return r.recordMovieEvents(ctx, tx, eventType, movie)
+}
+
+// recordMovieEvents is recordMovieEvent for many movies at once. Events get
+// sequence numbers in order of movies.
+func (r *Repository) recordMovieEvents(ctx context.Context, tx *sqlx.Tx, eventType model.MovieEventType, movies ...*model.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(movies))
+	payloads := make([]string, 0, len(movies))
+	for _, movie := range movies {
+		payload, err := json.Marshal(movie)
+		if err != nil {
+			return fmt.Errorf("failed to encode movie: %w", err)
+		}
+
+		ids = append(ids, movie.ID)
+		payloads = append(payloads, string(payload))
+	}
+
+	// Ordinality keeps events in order of movies, the trigger numbers them
+	// in order of insertion
+	const insertEvents = `INSERT INTO movie_events (event_type, movie_id, movie)
+		SELECT $1, e.movie_id, e.movie::jsonb
+		FROM unnest($2::uuid[], $3::text[]) WITH ORDINALITY AS e(movie_id, movie, ord)
+		ORDER BY e.ord
+		RETURNING event_id`
+
+	var eventIDs []int64
+	err := tx.SelectContext(ctx, &eventIDs, insertEvents, string(eventType), pq.Array(ids), pq.Array(payloads))
+	if err != nil {
+		return fmt.Errorf("failed to save movie events: %w", err)
+	}
+
+	if !r.skipOutbox {
+		query, args, err := r.builder.Insert("outbox").
+			Columns("event_id").
+			Select(sq.Select().Column(sq.Expr("unnest(?::bigint[])", pq.Array(eventIDs)))).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to form sql query: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to add movie events to outbox: %w", err)
+		}
+	}
+
+	if r.skipWebhooks {
+		return nil
+	}
+
+	// Schedule delivery to every webhook interested in the events
+	query, args, err := r.builder.Insert("webhook_deliveries").
+		Columns("webhook_id", "event_id").
+		Select(sq.Select("webhook_id", "event_id").
+			From("webhooks").
+			CrossJoin("unnest(?::bigint[]) AS event_id", pq.Array(eventIDs)).
+			Where(sq.Expr("cardinality(event_types) = 0 OR ? = ANY(event_types)", string(eventType))).
+			OrderBy("event_id", "webhook_id"),
+		).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to schedule webhook deliveries: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package postgresrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// exportCursor is a name of the cursor used by ExportMovies
+const exportCursor = "movies_export"
+
+// ExportMovies passes all movies matching the filter to fn in batches of up to
+// batch movies, ordered as in ListMovies. Movies are read through a server-side
+// cursor within a single snapshot, so export is consistent and only one batch
+// is held in memory at a time. Export stays on the primary even if there are
+// replicas, since long transactions on replicas get cancelled by replication.
+func (r *Repository) ExportMovies(ctx context.Context, filter *model.MovieFilter, batch uint64, fn func([]model.Movie) error) error {
+	const op = "repository.postgres.ExportMovies"
+
+	query, args, err := applyMovieFilter(r.builder.Select("*").From("movies"), filter).
+		OrderBy("title", "movie_id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	return r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		// Transaction repository is bound to has its mode set already
+		if r.tx == nil {
+			if _, err := tx.ExecContext(ctx, "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+				return fmt.Errorf("%s: failed to set transaction mode: %w", op, err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, "DECLARE "+exportCursor+" NO SCROLL CURSOR FOR "+query, args...); err != nil {
+			return fmt.Errorf("%s: failed to declare cursor: %w", op, err)
+		}
+
+		if err := r.fetchMovies(ctx, tx, batch, fn); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Cursor outlives savepoint of repository bound to transaction
+		if _, err := tx.ExecContext(ctx, "CLOSE "+exportCursor); err != nil {
+			return fmt.Errorf("%s: failed to close cursor: %w", op, err)
+		}
+
+		return nil
+	})
+}
+
+// fetchMovies passes movies of the export cursor to fn batch by batch
+func (r *Repository) fetchMovies(ctx context.Context, tx *sqlx.Tx, batch uint64, fn func([]model.Movie) error) error {
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", batch, exportCursor)
+	movies := make([]model.Movie, 0, batch)
+	for {
+		movies = movies[:0]
+		if err := tx.SelectContext(ctx, &movies, fetch); err != nil {
+			return fmt.Errorf("failed to fetch movies: %w", err)
+		}
+
+		if len(movies) == 0 {
+			return nil
+		}
+
+		if err := r.loadRelations(ctx, tx, moviePtrs(movies)...); err != nil {
+			return err
+		}
+
+		if err := fn(movies); err != nil {
+			return err
+		}
+
+		if uint64(len(movies)) < batch {
+			return nil
+		}
+	}
+}
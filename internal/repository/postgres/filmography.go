@@ -0,0 +1,39 @@
+package postgresrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// GetFilmography returns movies of the director ordered by release year.
+// Director names are compared ignoring case, diacritics and extra whitespace,
+// so "Pedro Almodóvar" matches " pedro  almodovar".
+func (r *Repository) GetFilmography(ctx context.Context, director string) ([]model.Movie, error) {
+	const op = "repository.postgres.GetFilmography"
+
+	db := r.reader(ctx)
+
+	query, args, err := r.builder.Select("*").
+		From("movies").
+		Where(sq.Expr("normalize_name(director) = normalize_name(?)", director)).
+		OrderBy("year", "title", "movie_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	movies := make([]model.Movie, 0)
+	err = db.SelectContext(ctx, &movies, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get movies of director: %w", op, err)
+	}
+
+	if err := r.loadRelations(ctx, db, moviePtrs(movies)...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return movies, nil
+}
@@ -0,0 +1,56 @@
+package postgresrepo
+
+import (
+	"movie-service/internal/model"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+)
+
+// applyMovieFilter adds conditions of the filter to the query selecting
+// from movies table
+func applyMovieFilter(builder sq.SelectBuilder, filter *model.MovieFilter) sq.SelectBuilder {
+	if filter == nil {
+		return builder
+	}
+
+	if len(filter.TagsAll) > 0 {
+		builder = builder.Where(sq.Expr(
+			"movies.movie_id IN (SELECT movie_id FROM movie_tags WHERE tag = ANY(?) GROUP BY movie_id HAVING count(*) = ?)",
+			pq.Array(filter.TagsAll), len(filter.TagsAll),
+		))
+	}
+
+	if len(filter.TagsAny) > 0 {
+		builder = builder.Where(sq.Expr(
+			"EXISTS (SELECT 1 FROM movie_tags WHERE movie_tags.movie_id = movies.movie_id AND tag = ANY(?))",
+			pq.Array(filter.TagsAny),
+		))
+	}
+
+	if filter.Genre != "" {
+		builder = builder.Where(sq.Expr("lower(movies.genre) = lower(?)", filter.Genre))
+	}
+
+	if filter.Director != "" {
+		builder = builder.Where(sq.Expr("lower(movies.director) = lower(?)", filter.Director))
+	}
+
+	if filter.YearFrom != 0 {
+		builder = builder.Where(sq.GtOrEq{"movies.year": filter.YearFrom})
+	}
+
+	if filter.YearTo != 0 {
+		builder = builder.Where(sq.LtOrEq{"movies.year": filter.YearTo})
+	}
+
+	return builder
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes wildcard characters of LIKE pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
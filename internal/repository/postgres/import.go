@@ -0,0 +1,73 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// GetImportProgress returns progress of import from source. Progress of
+// import which never loaded anything is empty.
+func (r *Repository) GetImportProgress(ctx context.Context, source string) (*model.ImportProgress, error) {
+	const op = "repository.postgres.GetImportProgress"
+
+	query, args, err := r.builder.Select("source", "loaded", "last_id").
+		From("import_progress").
+		Where(sq.Eq{"source": source}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	progress := model.ImportProgress{Source: source}
+	if err := r.db.GetContext(ctx, &progress, query, args...); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: failed to get import progress: %w", op, err)
+	}
+
+	return &progress, nil
+}
+
+// ImportMovies creates movies like CreateMovies and saves progress of the
+// import in the same transaction. So progress always matches loaded movies
+// and rerun import never loads them twice.
+func (r *Repository) ImportMovies(ctx context.Context, movies []model.Movie, progress *model.ImportProgress) ([]string, error) {
+	const op = "repository.postgres.ImportMovies"
+
+	query, args, err := r.builder.Insert("import_progress").
+		Columns("source", "loaded", "last_id").
+		Values(progress.Source, progress.Loaded, progress.LastID).
+		Suffix("ON CONFLICT (source) DO UPDATE SET loaded = EXCLUDED.loaded, last_id = EXCLUDED.last_id, updated_at = now()").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	depth := 0
+	if r.tx != nil {
+		depth = r.depth + 1
+	}
+
+	var ids []string
+	err = r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		var err error
+		if ids, err = r.bind(tx, depth).CreateMovies(ctx, movies); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to save import progress: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,100 @@
+package postgresrepo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// notifierPingInterval is how often idle connection of the listener is checked
+const notifierPingInterval = 90 * time.Second
+
+// Notifier wakes up subscribers whenever new movie events are committed.
+// Subscribers are also woken up after the listener reconnects, since
+// notifications sent while it was disconnected are lost.
+type Notifier struct {
+	listener *pq.Listener
+	done     chan struct{}
+
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func NewNotifier(listener *pq.Listener) (*Notifier, error) {
+	const op = "repository.postgres.NewNotifier"
+
+	if err := listener.Listen(MovieEventsChannel); err != nil {
+		return nil, fmt.Errorf("%s: failed to listen to movie events: %w", op, err)
+	}
+
+	n := &Notifier{
+		listener: listener,
+		done:     make(chan struct{}),
+		subs:     make(map[chan struct{}]struct{}),
+	}
+	go n.run()
+
+	return n, nil
+}
+
+// Subscribe returns channel receiving wakeups and function cancelling subscription.
+// Wakeups are coalesced, so single wakeup may stand for several events.
+func (n *Notifier) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	n.subs[ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		delete(n.subs, ch)
+		n.mu.Unlock()
+	}
+}
+
+func (n *Notifier) Close() error {
+	const op = "repository.postgres.Notifier.Close"
+
+	close(n.done)
+	if err := n.listener.Close(); err != nil {
+		return fmt.Errorf("%s: failed to close listener: %w", op, err)
+	}
+
+	return nil
+}
+
+func (n *Notifier) run() {
+	ticker := time.NewTicker(notifierPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.done:
+			return
+		case _, ok := <-n.listener.Notify:
+			if !ok {
+				return
+			}
+			// nil notification means that connection was re-established
+			n.broadcast()
+		case <-ticker.C:
+			// Ping makes listener notice broken connection and reconnect
+			go n.listener.Ping()
+		}
+	}
+}
+
+func (n *Notifier) broadcast() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package postgresrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ClaimOutboxEvents takes up to limit undelivered events which are due, oldest first.
+// Claimed events are hidden from other relays for lease, so if the relay dies
+// before reporting the result, events are delivered again after lease expires.
+func (r *Repository) ClaimOutboxEvents(ctx context.Context, limit uint64, lease time.Duration) ([]model.OutboxEvent, error) {
+	const op = "repository.postgres.ClaimOutboxEvents"
+
+	due := sq.Select("event_id").
+		From("outbox").
+		Where(sq.Eq{"delivered_at": nil}).
+		Where(sq.Expr("next_attempt_at <= now()")).
+		OrderBy("event_id").
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	claim := sq.Update("outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", sq.Expr("now() + ?::float * interval '1 second'", lease.Seconds())).
+		Where(sq.Expr("event_id IN (?)", due)).
+		Suffix("RETURNING event_id, attempts")
+
+	query, args, err := r.builder.Select("e.*", "c.attempts").
+		Prefix("WITH claimed AS (?)", claim).
+		From("claimed c").
+		Join("movie_events e ON e.event_id = c.event_id").
+		OrderBy("e.seq").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []struct {
+		movieEventRow
+		Attempts uint32 `db:"attempts"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to claim outbox events: %w", op, err)
+	}
+
+	events := make([]model.OutboxEvent, 0, len(rows))
+	for _, row := range rows {
+		event, err := row.decode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, model.OutboxEvent{MovieEvent: event, Attempts: row.Attempts})
+	}
+
+	return events, nil
+}
+
+// MarkOutboxDelivered marks event as delivered, so it is never claimed again
+func (r *Repository) MarkOutboxDelivered(ctx context.Context, seq uint64) error {
+	const op = "repository.postgres.MarkOutboxDelivered"
+
+	query, args, err := r.builder.Update("outbox").
+		Set("delivered_at", sq.Expr("now()")).
+		Set("last_error", "").
+		Where(eventBySeq(seq)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to mark outbox event delivered: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkOutboxFailed schedules next delivery attempt of the event
+func (r *Repository) MarkOutboxFailed(ctx context.Context, seq uint64, retryAt time.Time, reason string) error {
+	const op = "repository.postgres.MarkOutboxFailed"
+
+	query, args, err := r.builder.Update("outbox").
+		Set("next_attempt_at", retryAt).
+		Set("last_error", reason).
+		Where(eventBySeq(seq)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to reschedule outbox event: %w", op, err)
+	}
+
+	return nil
+}
+
+// eventBySeq matches rows referring to the event with sequence number seq
+func eventBySeq(seq uint64) sq.Sqlizer {
+	return sq.Expr("event_id = (SELECT event_id FROM movie_events WHERE seq = ?)", seq)
+}
+
+// PurgeOutbox deletes events delivered before deliveredBefore and returns
+// their number. Events themselves are kept in movie_events.
+func (r *Repository) PurgeOutbox(ctx context.Context, deliveredBefore time.Time) (uint64, error) {
+	const op = "repository.postgres.PurgeOutbox"
+
+	query, args, err := r.builder.Delete("outbox").
+		Where(sq.Lt{"delivered_at": deliveredBefore}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to purge outbox: %w", op, err)
+	}
+
+	num, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to get number of purged events: %w", op, err)
+	}
+
+	return uint64(num), nil
+}
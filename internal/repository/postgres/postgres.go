@@ -1,163 +1,327 @@
 package postgresrepo
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"movie-service/internal/model"
 	repo "movie-service/internal/repository"
+	"slices"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	sq "github.com/Masterminds/squirrel"
 )
 
 const (
-	defaultCreateBatch = 50
+	defaultCreateBatch   = 50
+	defaultCopyThreshold = 1000
 )
 
 type Repository struct {
-	db      *sqlx.DB
+	// db is the pool, or the transaction if repository is bound to one
+	db      querier
+	pool    *sqlx.DB
+	tx      *sqlx.Tx
+	depth   int
 	builder sq.StatementBuilderType
+
+	replicas Replicas
+
+	createBatch   int
+	copyThreshold int
+	skipOutbox    bool
+	skipWebhooks  bool
+}
+
+// Option configures Repository
+type Option func(r *Repository)
+
+// WithCreateBatch sets number of movies CreateMovies inserts with a single
+// statement. Non-positive n keeps the default.
+func WithCreateBatch(n int) Option {
+	return func(r *Repository) {
+		if n > 0 {
+			r.createBatch = n
+		}
+	}
+}
+
+// WithCopyThreshold makes CreateMovies load n and more movies at once with
+// COPY instead of INSERT statements. Zero disables COPY.
+func WithCopyThreshold(n int) Option {
+	return func(r *Repository) {
+		r.copyThreshold = n
+	}
+}
+
+// WithoutOutbox stops putting movie events to the outbox. It is meant for
+// setups where no relay drains the outbox, which would grow without bound.
+func WithoutOutbox() Option {
+	return func(r *Repository) {
+		r.skipOutbox = true
+	}
+}
+
+// WithoutWebhooks stops scheduling webhook deliveries of movie events. It is
+// meant for bulk loads, which would flood webhooks with a delivery per movie.
+func WithoutWebhooks() Option {
+	return func(r *Repository) {
+		r.skipWebhooks = true
+	}
 }
 
-func New(db *sqlx.DB) *Repository {
-	return &Repository{
-		db:      db,
-		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+func New(db *sqlx.DB, opts ...Option) *Repository {
+	r := &Repository{
+		db:            primary{db},
+		pool:          db,
+		builder:       sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		createBatch:   defaultCreateBatch,
+		copyThreshold: defaultCopyThreshold,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
-func (r *Repository) GetMovie(id string) (*model.Movie, error) {
+func (r *Repository) GetMovie(ctx context.Context, id string) (*model.Movie, error) {
 	const op = "repository.postgres.GetMovie"
 
+	db := r.reader(ctx)
+
+	movie, err := r.getMovie(ctx, db, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return movie, nil
+}
+
+func (r *Repository) getMovie(ctx context.Context, q sqlx.QueryerContext, id string) (*model.Movie, error) {
 	query, args, err := r.builder.Select("*").
 		From("movies").
 		Where(sq.Eq{"movie_id": id}).
 		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		return nil, fmt.Errorf("failed to form sql query: %w", err)
 	}
 
 	var movie model.Movie
-	err = r.db.Get(&movie, query, args...)
+	err = sqlx.GetContext(ctx, q, &movie, query, args...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("%s: failed to get movie info by id: %w", op, repo.ErrMovieNotExists)
+			return nil, fmt.Errorf("failed to get movie info by id: %w", repo.ErrMovieNotExists)
 		}
 
-		return nil, fmt.Errorf("%s: failed to get movie info by id: %w", op, err)
+		return nil, fmt.Errorf("failed to get movie info by id: %w", err)
+	}
+
+	if err := r.loadRelations(ctx, q, &movie); err != nil {
+		return nil, err
 	}
 
 	return &movie, nil
 }
 
-func (r *Repository) GetMovies() ([]model.Movie, error) {
+// lockMovie locks movie row until the end of transaction
+func (r *Repository) lockMovie(ctx context.Context, tx *sqlx.Tx, id string) error {
+	query, args, err := r.builder.Select("movie_id").
+		From("movies").
+		Where(sq.Eq{"movie_id": id}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var movieID string
+	if err := tx.GetContext(ctx, &movieID, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to lock movie: %w", repo.ErrMovieNotExists)
+		}
+
+		return fmt.Errorf("failed to lock movie: %w", err)
+	}
+
+	return nil
+}
+
+// GetMovies returns all movies matching the filter
+func (r *Repository) GetMovies(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error) {
 	const op = "repository.postgres.GetMovies"
 
-	query, _, err := r.builder.Select("*").
-		From("movies").
+	db := r.reader(ctx)
+
+	query, args, err := applyMovieFilter(r.builder.Select("*").From("movies"), filter).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
 	}
 
 	var movies []model.Movie
-	err = r.db.Select(&movies, query)
+	err = db.SelectContext(ctx, &movies, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to get info about movies: %w", op, err)
 	}
 
+	if err := r.loadRelations(ctx, db, moviePtrs(movies)...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return movies, nil
 }
 
-func (r *Repository) CreateMovie(movie *model.Movie) (string, error) {
+// ListMovies returns page of movies matching the filter ordered by title
+func (r *Repository) ListMovies(ctx context.Context, filter *model.MovieFilter, limit, offset uint64) ([]model.Movie, error) {
+	const op = "repository.postgres.ListMovies"
+
+	db := r.reader(ctx)
+
+	query, args, err := applyMovieFilter(r.builder.Select("*").From("movies"), filter).
+		OrderBy("title", "movie_id").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	movies := make([]model.Movie, 0, limit)
+	err = db.SelectContext(ctx, &movies, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get page of movies: %w", op, err)
+	}
+
+	if err := r.loadRelations(ctx, db, moviePtrs(movies)...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return movies, nil
+}
+
+func (r *Repository) CreateMovie(ctx context.Context, movie *model.Movie) (string, error) {
 	const op = "repository.postgres.CreateMovie"
 
 	query, args, err := r.builder.Insert("movies").
 		Columns("title", "genre", "director", "year").
 		Values(movie.Title, movie.Genre, movie.Director, movie.Year).
-		Suffix("RETURNING movie_id").
+		Suffix("RETURNING *").
 		ToSql()
 	if err != nil {
 		return "", fmt.Errorf("%s: failed to form sql query: %w", op, err)
 	}
 
-	var movieID string
-	err = r.db.Get(&movieID, query, args...)
+	var created model.Movie
+	err = r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &created, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to add movie info: %w", op, err)
+		}
+		created.CollectionIDs, created.Tags = make([]string, 0), make([]string, 0)
+
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventCreated, &created); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("%s: failed to add movie info: %w", op, err)
+		return "", err
 	}
 
-	return movieID, nil
+	return created.ID, nil
 }
 
-func (r *Repository) CreateMovies(movies []model.Movie) ([]string, error) {
-	const op = "repository.postgres.CreateMovie"
+// CreateMovies creates all movies in a single transaction and returns their
+// IDs in order of movies. Movies are inserted in batches of createBatch, or
+// loaded with COPY if there are at least copyThreshold of them.
+func (r *Repository) CreateMovies(ctx context.Context, movies []model.Movie) ([]string, error) {
+	const op = "repository.postgres.CreateMovies"
 
-	// Begin transaction
-	tx, err := r.db.Beginx()
-	if err != nil {
-		return nil, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	ids := make([]string, 0, len(movies))
+	if len(movies) == 0 {
+		return ids, nil
 	}
 
-	// Intercept non-nil error and rollback whole transaction
-	defer func() {
-		if err != nil {
-			errRb := tx.Rollback()
-			if errRb != nil {
-				err = fmt.Errorf("%s: error during rollback: %w", op, err)
-				return
-			}
-
-			return
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		insert, batchSize := r.createMovies, r.createBatch
+		if r.copyThreshold > 0 && len(movies) >= r.copyThreshold {
+			insert, batchSize = r.copyMovies, len(movies)
 		}
-		err = tx.Commit()
-	}()
 
-	// Alternately, insert all batches
-	createdIDs := make([]string, 0, len(movies))
-	for i := range movies {
-		endOfBatch := i + defaultCreateBatch
-		if endOfBatch > len(movies) {
-			endOfBatch = len(movies)
-		}
+		for batch := range slices.Chunk(movies, batchSize) {
+			created, err := insert(ctx, tx, batch)
+			if err != nil {
+				return fmt.Errorf("%s: failed to insert batch of movies: %w", op, err)
+			}
 
-		idsBatch, err := r.createMovies(tx, movies[i:endOfBatch])
-		if err != nil {
-			return nil, fmt.Errorf("%s: failed to insert batch of movies: %w", op, err)
+			if err := r.recordMovieEvents(ctx, tx, model.MovieEventCreated, moviePtrs(created)...); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			for _, movie := range created {
+				ids = append(ids, movie.ID)
+			}
 		}
 
-		createdIDs = append(createdIDs, idsBatch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return createdIDs, nil
+	return ids, nil
 }
 
-func (r *Repository) createMovies(tx *sqlx.Tx, movies []model.Movie) ([]string, error) {
-	const op = "repository.postgres.createMovies"
-
-	builder := r.builder.Insert("movies").Columns("title", "genre", "director", "year")
+// createMovies inserts movies with a single statement and returns them in
+// order of input
+func (r *Repository) createMovies(ctx context.Context, tx *sqlx.Tx, movies []model.Movie) ([]model.Movie, error) {
+	// IDs are generated here, since order of rows returned by INSERT is not
+	// guaranteed to match order of its values
+	ids := make([]string, 0, len(movies))
+	builder := r.builder.Insert("movies").Columns("movie_id", "title", "genre", "director", "year")
 	for _, movie := range movies {
-		builder = builder.Values(movie.Title, movie.Genre, movie.Director, movie.Year)
+		id := uuid.NewString()
+		ids = append(ids, id)
+		builder = builder.Values(id, movie.Title, movie.Genre, movie.Director, movie.Year)
 	}
-	builder = builder.PlaceholderFormat(sq.Dollar)
 
-	query, args, err := builder.ToSql()
+	query, args, err := builder.Suffix("RETURNING *").ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to form query: %w", op, err)
+		return nil, fmt.Errorf("failed to form sql query: %w", err)
 	}
 
-	var movieIDs []string
-	err = tx.Select(&movieIDs, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("%s: failed to add info about movies: %w", op, err)
+	var inserted []model.Movie
+	if err := tx.SelectContext(ctx, &inserted, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to add movies info: %w", err)
+	}
+	if len(inserted) != len(movies) {
+		return nil, fmt.Errorf("inserted %d movies instead of %d", len(inserted), len(movies))
+	}
+
+	byID := make(map[string]model.Movie, len(inserted))
+	for _, movie := range inserted {
+		byID[movie.ID] = movie
 	}
 
-	return movieIDs, nil
+	created := make([]model.Movie, 0, len(movies))
+	for _, id := range ids {
+		movie, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("inserted movie %s is not returned", id)
+		}
+		movie.CollectionIDs, movie.Tags = make([]string, 0), make([]string, 0)
+
+		created = append(created, movie)
+	}
+
+	return created, nil
 }
 
-func (r *Repository) UpdateMovie(id string, movie *model.Movie) (*model.Movie, error) {
+func (r *Repository) UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
 	const op = "repository.postgres.UpdateMovide"
 
 	builder := r.builder.Update("movies")
@@ -183,43 +347,90 @@ func (r *Repository) UpdateMovie(id string, movie *model.Movie) (*model.Movie, e
 	}
 
 	var newMovie model.Movie
-	err = r.db.Get(&newMovie, query, args...)
+	err = r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &newMovie, query, args...)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%s: failed to update movie info: %w", op, repo.ErrMovieNotExists)
+			}
 
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("%s: failed to update movie info: %w", op, repo.ErrMovieNotExists)
+			return fmt.Errorf("%s: failed to update movie info: %w", op, err)
+		}
+
+		if err := r.loadRelations(ctx, tx, &newMovie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
 		}
 
-		return nil, fmt.Errorf("%s: failed to update movie info: %w", op, err)
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventUpdated, &newMovie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &newMovie, nil
 }
 
 // Returning bool val indicates whether movie info was deleted or not
-func (r *Repository) DeleteMovie(id string) (bool, error) {
+func (r *Repository) DeleteMovie(ctx context.Context, id string) (bool, error) {
 	const op = "repository.postgres.DeleteMovie"
 
-	query, args, err := r.builder.Delete("movies").
-		Where(sq.Eq{"movie_id": id}).
-		ToSql()
-	if err != nil {
-		return false, fmt.Errorf("%s: failed to form query: %w", op, err)
-	}
+	var deleted bool
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		if err := r.lockMovie(ctx, tx, id); err != nil {
+			if errors.Is(err, repo.ErrMovieNotExists) {
+				return nil
+			}
 
-	res, err := r.db.Exec(query, args...)
-	if err != nil {
-		return false, fmt.Errorf("%s: failed to delete movie info: %w", op, err)
-	}
+			return fmt.Errorf("%s: %w", op, err)
+		}
 
-	num, err := res.RowsAffected()
-	if err != nil {
-		return false, fmt.Errorf("%s: failed to get number of updated rows: %w", op, err)
-	}
+		// Snapshot of the movie goes to the deletion event, so it is taken before
+		// relations are deleted along with the movie
+		movie, err := r.getMovie(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
 
-	if num == 0 {
-		return false, nil
+		// Movie tags are deleted along with the movie, so usage counts must be decreased
+		if err := r.adjustTagUsage(ctx, tx, movie.Tags, -1); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := r.builder.Delete("movies").
+			Where(sq.Eq{"movie_id": id}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form query: %w", op, err)
+		}
+
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("%s: failed to delete movie info: %w", op, err)
+		}
+
+		num, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: failed to get number of updated rows: %w", op, err)
+		}
+
+		if num == 0 {
+			return nil
+		}
+		deleted = true
+
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventDeleted, movie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
 	}
 
-	return true, nil
+	return deleted, nil
 }
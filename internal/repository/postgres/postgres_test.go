@@ -0,0 +1,542 @@
+package postgresrepo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	postgresrepo "movie-service/internal/repository/postgres"
+	"movie-service/internal/repository/repotest"
+	"movie-service/migrations"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/lib/pq"
+)
+
+// dsnEnv names variable with connection string of postgres used by tests.
+// All data of the database is deleted by every test. If it is not set, tests
+// start throwaway postgres by themselves using initdb and pg_ctl from PATH.
+const dsnEnv = "TEST_POSTGRES_DSN"
+
+// dsn is a connection string of postgres, empty if there is none
+var dsn string
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+// run runs tests and stops postgres started for them, which os.Exit would skip
+func run(m *testing.M) int {
+	dsn = os.Getenv(dsnEnv)
+	if dsn == "" {
+		var stop func()
+		var err error
+		dsn, stop, err = startPostgres()
+		if err != nil {
+			log.Printf("postgres is not available: %v", err)
+		} else {
+			defer stop()
+		}
+	}
+
+	return m.Run()
+}
+
+func TestRepository(t *testing.T) {
+	db := openDB(t)
+
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		cleanDB(t, db)
+		return postgresrepo.New(db)
+	})
+}
+
+// TestRepositoryCopy runs the suite creating every batch of movies with COPY
+func TestRepositoryCopy(t *testing.T) {
+	db := openDB(t)
+
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		cleanDB(t, db)
+		return postgresrepo.New(db, postgresrepo.WithCopyThreshold(1))
+	})
+}
+
+// TestRepositoryReplicas runs the suite reading through replica, which is
+// the primary itself, so there is no replication lag
+func TestRepositoryReplicas(t *testing.T) {
+	db := openDB(t)
+	replicas := &countingReplicas{db: db}
+
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		cleanDB(t, db)
+		return postgresrepo.New(db, postgresrepo.WithReplicas(replicas))
+	})
+
+	if replicas.picks.Load() == 0 {
+		t.Fatal("no query is routed to replicas")
+	}
+}
+
+func TestReadYourWrites(t *testing.T) {
+	db := openDB(t)
+	cleanDB(t, db)
+	replicas := &countingReplicas{db: db}
+	r := postgresrepo.New(db, postgresrepo.WithReplicas(replicas))
+
+	wantPicks := func(name string, want int32) {
+		t.Helper()
+
+		if got := replicas.picks.Load(); got != want {
+			t.Fatalf("%s: replicas are picked %d times, want %d", name, got, want)
+		}
+	}
+
+	ctx := postgresrepo.WithSession(context.Background())
+	if _, err := r.GetMovies(ctx, nil); err != nil {
+		t.Fatalf("GetMovies error = %v", err)
+	}
+	wantPicks("read before write", 1)
+
+	id, err := r.CreateMovie(ctx, &model.Movie{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979})
+	if err != nil {
+		t.Fatalf("CreateMovie error = %v", err)
+	}
+	if _, err := r.GetMovie(ctx, id); err != nil {
+		t.Fatalf("GetMovie error = %v", err)
+	}
+	wantPicks("read of session after write", 1)
+
+	// Other requests still read from replicas
+	if _, err := r.GetMovie(context.Background(), id); err != nil {
+		t.Fatalf("GetMovie error = %v", err)
+	}
+	wantPicks("read without session", 2)
+
+	// Transaction reads its own data only
+	err = r.InTx(context.Background(), func(tx repository.Repository) error {
+		_, err := tx.GetMovie(context.Background(), id)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("InTx error = %v", err)
+	}
+	wantPicks("read within transaction", 2)
+}
+
+// countingReplicas poses the primary as its only replica and counts queries
+// routed to it
+type countingReplicas struct {
+	db    *sqlx.DB
+	picks atomic.Int32
+}
+
+func (r *countingReplicas) Pick() *sqlx.DB {
+	r.picks.Add(1)
+	return r.db
+}
+
+func TestCreateMovies(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts []postgresrepo.Option
+		n    int
+	}{
+		{"single batch", []postgresrepo.Option{postgresrepo.WithCreateBatch(10), postgresrepo.WithCopyThreshold(0)}, 10},
+		{"uneven batches", []postgresrepo.Option{postgresrepo.WithCreateBatch(7), postgresrepo.WithCopyThreshold(0)}, 23},
+		{"batches of one", []postgresrepo.Option{postgresrepo.WithCreateBatch(1), postgresrepo.WithCopyThreshold(0)}, 5},
+		{"below copy threshold", []postgresrepo.Option{postgresrepo.WithCreateBatch(3), postgresrepo.WithCopyThreshold(11)}, 10},
+		{"copy", []postgresrepo.Option{postgresrepo.WithCopyThreshold(10)}, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanDB(t, db)
+			r := postgresrepo.New(db, tt.opts...)
+
+			movies := newMovies(tt.n)
+			ids, err := r.CreateMovies(ctx, movies)
+			if err != nil {
+				t.Fatalf("CreateMovies error = %v", err)
+			}
+			if len(ids) != tt.n {
+				t.Fatalf("CreateMovies returned %d IDs, want %d", len(ids), tt.n)
+			}
+
+			for i, id := range ids {
+				movie, err := r.GetMovie(ctx, id)
+				if err != nil {
+					t.Fatalf("GetMovie(%q) error = %v", id, err)
+				}
+				if movie.Title != movies[i].Title || movie.Year != movies[i].Year {
+					t.Fatalf("movie %d = %q (%d), want %q (%d)", i, movie.Title, movie.Year, movies[i].Title, movies[i].Year)
+				}
+			}
+
+			if got := count(t, db, "movies"); got != tt.n {
+				t.Fatalf("%d movies are stored, want %d", got, tt.n)
+			}
+
+			events, err := r.GetMovieEvents(ctx, 0, uint64(tt.n)+1)
+			if err != nil {
+				t.Fatalf("GetMovieEvents error = %v", err)
+			}
+			if len(events) != tt.n {
+				t.Fatalf("CreateMovies recorded %d events, want %d", len(events), tt.n)
+			}
+			for i, event := range events {
+				if event.MovieID != ids[i] {
+					t.Fatalf("event %d is about movie %s, want %s", i, event.MovieID, ids[i])
+				}
+			}
+			if got := count(t, db, "outbox"); got != tt.n {
+				t.Fatalf("%d events are in outbox, want %d", got, tt.n)
+			}
+		})
+	}
+}
+
+// TestCreateMoviesRollback checks that failure of any batch leaves no movies
+// of the call behind
+func TestCreateMoviesRollback(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts []postgresrepo.Option
+	}{
+		{"batches", []postgresrepo.Option{postgresrepo.WithCreateBatch(4), postgresrepo.WithCopyThreshold(0)}},
+		{"copy", []postgresrepo.Option{postgresrepo.WithCopyThreshold(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanDB(t, db)
+			r := postgresrepo.New(db, tt.opts...)
+
+			// The last movie violates check of year in the last batch
+			movies := newMovies(10)
+			movies[9].Year = 0
+
+			ids, err := r.CreateMovies(ctx, movies)
+			if err == nil {
+				t.Fatalf("CreateMovies succeeded with invalid movie, IDs = %v", ids)
+			}
+			if ids != nil {
+				t.Fatalf("CreateMovies returned IDs %v along with error", ids)
+			}
+
+			for _, table := range []string{"movies", "movie_events", "outbox"} {
+				if got := count(t, db, table); got != 0 {
+					t.Fatalf("%d rows are left in %s", got, table)
+				}
+			}
+		})
+	}
+}
+
+// TestImportMovies checks that progress of import is saved only along with
+// the movies
+func TestImportMovies(t *testing.T) {
+	db := openDB(t)
+	cleanDB(t, db)
+	ctx := context.Background()
+	r := postgresrepo.New(db)
+
+	progress, err := r.GetImportProgress(ctx, "imdb")
+	if err != nil {
+		t.Fatalf("GetImportProgress error = %v", err)
+	}
+	if *progress != (model.ImportProgress{Source: "imdb"}) {
+		t.Fatalf("GetImportProgress = %+v, want empty progress", progress)
+	}
+
+	loaded := &model.ImportProgress{Source: "imdb", Loaded: 3, LastID: "tt0000003"}
+	if _, err := r.ImportMovies(ctx, newMovies(3), loaded); err != nil {
+		t.Fatalf("ImportMovies error = %v", err)
+	}
+
+	// Batch failing to load leaves progress as it was
+	movies := newMovies(3)
+	movies[2].Year = 0
+	if _, err := r.ImportMovies(ctx, movies, &model.ImportProgress{Source: "imdb", Loaded: 6, LastID: "tt0000006"}); err == nil {
+		t.Fatal("ImportMovies succeeded with invalid movie")
+	}
+
+	progress, err = r.GetImportProgress(ctx, "imdb")
+	if err != nil {
+		t.Fatalf("GetImportProgress error = %v", err)
+	}
+	if *progress != *loaded {
+		t.Errorf("GetImportProgress = %+v, want %+v", progress, loaded)
+	}
+	if got := count(t, db, "movies"); got != 3 {
+		t.Errorf("%d movies are loaded, want 3", got)
+	}
+}
+
+func TestWithoutFanOut(t *testing.T) {
+	db := openDB(t)
+	cleanDB(t, db)
+	ctx := context.Background()
+
+	if _, err := postgresrepo.New(db).CreateWebhook(ctx, &model.Webhook{URL: "https://example.com/hook", Secret: "secret"}); err != nil {
+		t.Fatalf("CreateWebhook error = %v", err)
+	}
+
+	r := postgresrepo.New(db, postgresrepo.WithoutOutbox(), postgresrepo.WithoutWebhooks())
+	if _, err := r.ImportMovies(ctx, newMovies(3), &model.ImportProgress{Source: "imdb", Loaded: 3, LastID: "tt0000003"}); err != nil {
+		t.Fatalf("ImportMovies error = %v", err)
+	}
+
+	// Events are recorded, but go neither to the outbox nor to webhooks
+	if got := count(t, db, "movie_events"); got != 3 {
+		t.Errorf("%d movie events are recorded, want 3", got)
+	}
+	if got := count(t, db, "outbox"); got != 0 {
+		t.Errorf("%d events are put to the outbox, want none", got)
+	}
+	if got := count(t, db, "webhook_deliveries"); got != 0 {
+		t.Errorf("%d webhook deliveries are scheduled, want none", got)
+	}
+}
+
+// TestInTxRetry checks that transactions failing to serialize with each
+// other are retried until both succeed
+func TestInTxRetry(t *testing.T) {
+	db := openDB(t)
+	cleanDB(t, db)
+	r := postgresrepo.New(db)
+	ctx := context.Background()
+
+	// Both transactions read movies before either of them creates one, so
+	// they can't be serialized and one of them has to be retried
+	var read sync.WaitGroup
+	read.Add(2)
+
+	var attempts atomic.Int32
+	errs := make(chan error, 2)
+	for i := range 2 {
+		go func() {
+			first := true
+			errs <- r.InTx(ctx, func(tx repository.Repository) error {
+				attempts.Add(1)
+
+				movies, err := tx.GetMovies(ctx, nil)
+				if err != nil {
+					return err
+				}
+				if first {
+					first = false
+					read.Done()
+					read.Wait()
+				}
+
+				_, err = tx.CreateMovie(ctx, &model.Movie{
+					Title:    fmt.Sprintf("Movie %d of %d", i, len(movies)),
+					Genre:    "Drama",
+					Director: "Director",
+					Year:     2000,
+				})
+				return err
+			})
+		}()
+	}
+
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("InTx error = %v", err)
+		}
+	}
+
+	if got := attempts.Load(); got < 3 {
+		t.Fatalf("transactions are run %d times, want retry of one of them", got)
+	}
+	if got := count(t, db, "movies"); got != 2 {
+		t.Fatalf("%d movies are stored, want 2", got)
+	}
+}
+
+func TestMovieEventsOrder(t *testing.T) {
+	db := openDB(t)
+	cleanDB(t, db)
+	r := postgresrepo.New(db)
+	ctx := context.Background()
+
+	err := r.InTx(ctx, func(tx repository.Repository) error {
+		if _, err := tx.CreateMovie(ctx, &model.Movie{Title: "First", Genre: "Drama", Director: "Director", Year: 2000}); err != nil {
+			return err
+		}
+
+		// Writer of events doesn't wait for transaction which has written events before it
+		done := make(chan error, 1)
+		go func() {
+			_, err := r.CreateMovie(ctx, &model.Movie{Title: "Second", Genre: "Drama", Director: "Director", Year: 2000})
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			return errors.New("writer of events is blocked by transaction in progress")
+		}
+	})
+	if err != nil {
+		t.Fatalf("InTx error = %v", err)
+	}
+
+	// Events are numbered in order of commit
+	events, err := r.GetMovieEvents(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetMovieEvents error = %v", err)
+	}
+	got := make([]string, 0, len(events))
+	for _, event := range events {
+		got = append(got, fmt.Sprintf("%d %s", event.Seq, event.Movie.Title))
+	}
+	if want := []string{"1 Second", "2 First"}; !slices.Equal(got, want) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+}
+
+func newMovies(n int) []model.Movie {
+	movies := make([]model.Movie, 0, n)
+	for i := range n {
+		movies = append(movies, model.Movie{
+			Title:    fmt.Sprintf("Movie %03d", n-i),
+			Genre:    "Drama",
+			Director: "Director",
+			Year:     uint32(1900 + i),
+		})
+	}
+
+	return movies
+}
+
+func count(t *testing.T, db *sqlx.DB, table string) int {
+	t.Helper()
+
+	var n int
+	if err := db.Get(&n, "SELECT count(*) FROM "+table); err != nil {
+		t.Fatalf("failed to count rows of %s: %v", table, err)
+	}
+
+	return n
+}
+
+// openDB connects to migrated test database, the test is skipped if there is none
+func openDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	if dsn == "" {
+		t.Skipf("neither %s is set nor postgres binaries are found", dsnEnv)
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	src, err := iofs.New(migrations.Postgres, ".")
+	if err != nil {
+		t.Fatalf("failed to read embedded migrations: %v", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, dsn)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("failed to perform db migrations: %v", err)
+	}
+
+	return db
+}
+
+func cleanDB(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+
+	_, err := db.Exec(`TRUNCATE movies, ratings, reviews, watchlist, watch_history, collections,
+		collection_movies, tags, movie_tags, movie_events, outbox, webhooks, webhook_deliveries, import_progress
+		RESTART IDENTITY CASCADE;
+		UPDATE movie_events_purged SET purged_seq = 0`)
+	if err != nil {
+		t.Fatalf("failed to clean up database: %v", err)
+	}
+}
+
+// startPostgres creates database cluster in temporary directory and starts
+// postgres on a free port. Returned function stops it and removes the cluster.
+func startPostgres() (string, func(), error) {
+	initdb, err := exec.LookPath("initdb")
+	if err != nil {
+		return "", nil, err
+	}
+	pgCtl, err := exec.LookPath("pg_ctl")
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "movie-service-postgres-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create directory of cluster: %w", err)
+	}
+	dataDir := filepath.Join(dir, "data")
+
+	out, err := exec.Command(initdb, "-D", dataDir, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("initdb failed: %w: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir)
+	out, err = exec.Command(pgCtl, "-D", dataDir, "-o", opts, "-l", filepath.Join(dir, "postgres.log"), "-w", "start").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("pg_ctl start failed: %w: %s", err, out)
+	}
+
+	stop := func() {
+		if out, err := exec.Command(pgCtl, "-D", dataDir, "-m", "immediate", "-w", "stop").CombinedOutput(); err != nil {
+			log.Printf("pg_ctl stop failed: %v: %s", err, out)
+		}
+		os.RemoveAll(dir)
+	}
+
+	return fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port), stop, nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find free port: %w", err)
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,105 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Replicas picks read replica for read-only queries
+type Replicas interface {
+	// Pick returns healthy replica, nil if there is none
+	Pick() *sqlx.DB
+}
+
+// WithReplicas makes read-only methods query replicas. They fall back to
+// the primary if no replica is healthy.
+func WithReplicas(replicas Replicas) Option {
+	return func(r *Repository) {
+		r.replicas = replicas
+	}
+}
+
+type sessionKey struct{}
+
+// session is a request reading its own writes
+type session struct {
+	pinned atomic.Bool
+}
+
+// WithSession returns context of request which reads its own writes: once
+// anything is queried on the primary with the context, all following reads
+// with it go to the primary too.
+//
+// Session lasts as long as the context, i.e. a single RPC. Following requests
+// of the same client may still read from a replica which hasn't caught up
+// with the write yet.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// pin makes session of the context read from the primary
+func pin(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.pinned.Store(true)
+	}
+}
+
+func pinned(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+
+	return ok && s.pinned.Load()
+}
+
+// reader returns connection for read-only queries: healthy replica unless
+// repository is bound to transaction or session of the context is pinned
+// to the primary
+func (r *Repository) reader(ctx context.Context) querier {
+	if r.replicas == nil || r.tx != nil || pinned(ctx) {
+		return r.db
+	}
+
+	if replica := r.replicas.Pick(); replica != nil {
+		return replica
+	}
+
+	return r.db
+}
+
+// primary is the pool of the primary. Every query on it pins session of its
+// context, since it may change data.
+type primary struct {
+	*sqlx.DB
+}
+
+func (p primary) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	pin(ctx)
+	return p.DB.ExecContext(ctx, query, args...)
+}
+
+func (p primary) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	pin(ctx)
+	return p.DB.QueryContext(ctx, query, args...)
+}
+
+func (p primary) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	pin(ctx)
+	return p.DB.QueryxContext(ctx, query, args...)
+}
+
+func (p primary) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	pin(ctx)
+	return p.DB.QueryRowxContext(ctx, query, args...)
+}
+
+func (p primary) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	pin(ctx)
+	return p.DB.GetContext(ctx, dest, query, args...)
+}
+
+func (p primary) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	pin(ctx)
+	return p.DB.SelectContext(ctx, dest, query, args...)
+}
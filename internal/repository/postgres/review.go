@@ -0,0 +1,266 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateReview saves new review. Every new review waits for moderation
+// and isn't shown publicly until it's approved.
+func (r *Repository) CreateReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	const op = "repository.postgres.CreateReview"
+
+	query, args, err := r.builder.Insert("reviews").
+		Columns("movie_id", "user_id", "text", "status").
+		Values(review.MovieID, review.UserID, review.Text, model.ReviewStatusPending).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var newReview model.Review
+	err = r.db.GetContext(ctx, &newReview, query, args...)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, fmt.Errorf("%s: failed to add review: %w", op, repo.ErrMovieNotExists)
+		}
+
+		return nil, fmt.Errorf("%s: failed to add review: %w", op, err)
+	}
+
+	return &newReview, nil
+}
+
+// ListReviews returns page of approved reviews of the movie, newest first
+func (r *Repository) ListReviews(ctx context.Context, movieID string, limit, offset uint64) ([]model.Review, error) {
+	const op = "repository.postgres.ListReviews"
+
+	db := r.reader(ctx)
+
+	query, args, err := r.builder.Select("*").
+		From("reviews").
+		Where(sq.Eq{"movie_id": movieID, "status": model.ReviewStatusApproved}).
+		OrderBy("created_at DESC", "review_id").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	reviews := make([]model.Review, 0, limit)
+	err = db.SelectContext(ctx, &reviews, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get reviews: %w", op, err)
+	}
+
+	return reviews, nil
+}
+
+// UpdateReview changes text of the review written by the user. Changed review
+// is sent back to moderation.
+func (r *Repository) UpdateReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	const op = "repository.postgres.UpdateReview"
+
+	var newReview model.Review
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		prevStatus, err := r.lockReview(ctx, tx, review.ID, sq.Eq{"user_id": review.UserID})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := r.builder.Update("reviews").
+			Set("text", review.Text).
+			Set("status", model.ReviewStatusPending).
+			Set("updated_at", sq.Expr("now()")).
+			Where(sq.Eq{"review_id": review.ID}).
+			Suffix("RETURNING *").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &newReview, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to update review: %w", op, err)
+		}
+
+		return r.adjustReviewCount(ctx, tx, newReview.MovieID, prevStatus, newReview.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newReview, nil
+}
+
+// DeleteReview deletes review written by the user. Returning bool val
+// indicates whether review was deleted or not, review of another user isn't
+// deleted and ErrReviewNotOwned is returned.
+func (r *Repository) DeleteReview(ctx context.Context, id, userID string) (bool, error) {
+	const op = "repository.postgres.DeleteReview"
+
+	deleted := false
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		query, args, err := r.builder.Delete("reviews").
+			Where(sq.Eq{"review_id": id, "user_id": userID}).
+			Suffix("RETURNING *").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		var review model.Review
+		if err := tx.GetContext(ctx, &review, query, args...); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				if err := r.checkReviewMissing(ctx, tx, id); err != nil {
+					return fmt.Errorf("%s: %w", op, err)
+				}
+
+				return nil
+			}
+
+			return fmt.Errorf("%s: failed to delete review: %w", op, err)
+		}
+		deleted = true
+
+		return r.adjustReviewCount(ctx, tx, review.MovieID, review.Status, "")
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+// ModerateReview sets moderation status of the review
+func (r *Repository) ModerateReview(ctx context.Context, id string, status model.ReviewStatus) (*model.Review, error) {
+	const op = "repository.postgres.ModerateReview"
+
+	var newReview model.Review
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		prevStatus, err := r.lockReview(ctx, tx, id, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := r.builder.Update("reviews").
+			Set("status", status).
+			Set("updated_at", sq.Expr("now()")).
+			Where(sq.Eq{"review_id": id}).
+			Suffix("RETURNING *").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &newReview, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to update review status: %w", op, err)
+		}
+
+		return r.adjustReviewCount(ctx, tx, newReview.MovieID, prevStatus, newReview.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newReview, nil
+}
+
+// lockReview locks review row until the end of transaction and returns its
+// current moderation status
+func (r *Repository) lockReview(ctx context.Context, tx *sqlx.Tx, id string, pred sq.Sqlizer) (model.ReviewStatus, error) {
+	builder := r.builder.Select("status").
+		From("reviews").
+		Where(sq.Eq{"review_id": id})
+	if pred != nil {
+		builder = builder.Where(pred)
+	}
+
+	query, args, err := builder.Suffix("FOR UPDATE").ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var status model.ReviewStatus
+	if err := tx.GetContext(ctx, &status, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("failed to lock review: %w", repo.ErrReviewNotExists)
+		}
+
+		return "", fmt.Errorf("failed to lock review: %w", err)
+	}
+
+	return status, nil
+}
+
+// adjustReviewCount keeps number of approved reviews of the movie in sync
+// when review moves from one status to another, and records updated event of
+// the movie if the number has changed
+func (r *Repository) adjustReviewCount(ctx context.Context, tx *sqlx.Tx, movieID string, prev, cur model.ReviewStatus) error {
+	const op = "repository.postgres.adjustReviewCount"
+
+	delta := 0
+	if prev == model.ReviewStatusApproved {
+		delta--
+	}
+	if cur == model.ReviewStatusApproved {
+		delta++
+	}
+	if delta == 0 {
+		return nil
+	}
+
+	query, args, err := r.builder.Update("movies").
+		Set("review_count", sq.Expr("review_count + ?", delta)).
+		Where(sq.Eq{"movie_id": movieID}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var movie model.Movie
+	if err := tx.GetContext(ctx, &movie, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to update review count: %w", op, err)
+	}
+
+	if err := r.loadRelations(ctx, tx, &movie); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.recordMovieEvent(ctx, tx, model.MovieEventUpdated, &movie); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// checkReviewMissing returns ErrReviewNotOwned if the review exists, it's
+// called when the review isn't found among reviews of the user
+func (r *Repository) checkReviewMissing(ctx context.Context, tx *sqlx.Tx, id string) error {
+	query, args, err := r.builder.Select("count(*)").
+		From("reviews").
+		Where(sq.Eq{"review_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var num int
+	if err := tx.GetContext(ctx, &num, query, args...); err != nil {
+		return fmt.Errorf("failed to check review: %w", err)
+	}
+	if num > 0 {
+		return fmt.Errorf("failed to delete review: %w", repo.ErrReviewNotOwned)
+	}
+
+	return nil
+}
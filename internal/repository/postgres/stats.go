@@ -0,0 +1,101 @@
+package postgresrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// GetCatalogStats computes facet counts and histograms over movies matching
+// the filter of the query
+func (r *Repository) GetCatalogStats(ctx context.Context, statsQuery *model.CatalogStatsQuery) (*model.CatalogStats, error) {
+	const op = "repository.postgres.GetCatalogStats"
+
+	db := r.reader(ctx)
+
+	query, args, err := applyMovieFilter(r.builder.Select("count(*)").From("movies"), &statsQuery.Filter).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	stats := &model.CatalogStats{Facets: make([]model.FacetCounts, 0, len(statsQuery.Facets))}
+	if err := db.GetContext(ctx, &stats.TotalMovies, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to count movies: %w", op, err)
+	}
+
+	for _, facet := range statsQuery.Facets {
+		builder, err := r.facetQuery(facet, statsQuery)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := applyMovieFilter(builder, &statsQuery.Filter).ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		buckets := make([]model.FacetBucket, 0)
+		if err := db.SelectContext(ctx, &buckets, query, args...); err != nil {
+			return nil, fmt.Errorf("%s: failed to count movies by %s: %w", op, facet, err)
+		}
+
+		stats.Facets = append(stats.Facets, model.FacetCounts{Facet: facet, Buckets: buckets})
+	}
+
+	return stats, nil
+}
+
+// facetQuery returns query grouping movies by value of the facet.
+// Filter conditions are to be added by caller.
+func (r *Repository) facetQuery(facet model.Facet, statsQuery *model.CatalogStatsQuery) (sq.SelectBuilder, error) {
+	switch facet {
+	case model.FacetGenre, model.FacetDirector:
+		// Values are grouped case-insensitively the same way filter matches
+		// them, the bucket is named after one of its spellings
+		column := "movies." + string(facet)
+
+		return r.builder.Select("min("+column+` COLLATE "C") AS value`, "count(*) AS count").
+			From("movies").
+			GroupBy("lower(" + column + ")").
+			OrderBy("count DESC", "value").
+			Limit(statsQuery.TopLimit), nil
+	case model.FacetTag:
+		return r.builder.Select("movie_tags.tag AS value", "count(*) AS count").
+			From("movies").
+			Join("movie_tags ON movie_tags.movie_id = movies.movie_id").
+			GroupBy("movie_tags.tag").
+			OrderBy("count DESC", "value").
+			Limit(statsQuery.TopLimit), nil
+	case model.FacetYear:
+		bucket := fmt.Sprintf("((movies.year / %d) * %d)", statsQuery.YearBucket, statsQuery.YearBucket)
+
+		return r.builder.Select(
+			bucket+"::text AS value",
+			bucket+" AS bucket_from",
+			fmt.Sprintf("%s + %d AS bucket_to", bucket, statsQuery.YearBucket-1),
+			"count(*) AS count",
+		).
+			From("movies").
+			GroupBy(bucket).
+			OrderBy("bucket_from"), nil
+	case model.FacetRating:
+		// Average rating rounded down, e.g. movies rated 7.0-7.99 fall into bucket 7
+		bucket := "(movies.rating_sum / movies.rating_count)"
+
+		return r.builder.Select(
+			bucket+"::text AS value",
+			bucket+" AS bucket_from",
+			bucket+" AS bucket_to",
+			"count(*) AS count",
+		).
+			From("movies").
+			Where(sq.Gt{"movies.rating_count": 0}).
+			GroupBy(bucket).
+			OrderBy("bucket_from"), nil
+	default:
+		return sq.SelectBuilder{}, fmt.Errorf("unknown facet %q", facet)
+	}
+}
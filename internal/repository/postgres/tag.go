@@ -0,0 +1,220 @@
+package postgresrepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// AddTags labels the movie with tags. Tags which the movie already has are ignored.
+func (r *Repository) AddTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error) {
+	const op = "repository.postgres.AddTags"
+
+	var movie *model.Movie
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		if err := r.lockMovie(ctx, tx, movieID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Make sure all tags exist
+		builder := r.builder.Insert("tags").Columns("name")
+		for _, tag := range tags {
+			builder = builder.Values(tag)
+		}
+
+		query, args, err := builder.Suffix("ON CONFLICT (name) DO NOTHING").ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to add tags: %w", op, err)
+		}
+
+		// Label the movie
+		builder = r.builder.Insert("movie_tags").Columns("movie_id", "tag")
+		for _, tag := range tags {
+			builder = builder.Values(movieID, tag)
+		}
+
+		query, args, err = builder.Suffix("ON CONFLICT (movie_id, tag) DO NOTHING RETURNING tag").ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		var added []string
+		if err := tx.SelectContext(ctx, &added, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to tag movie: %w", op, err)
+		}
+
+		if err := r.adjustTagUsage(ctx, tx, added, 1); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		movie, err = r.getMovie(ctx, tx, movieID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if len(added) == 0 {
+			return nil
+		}
+
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventUpdated, movie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movie, nil
+}
+
+// RemoveTags removes tags from the movie. Tags which the movie doesn't have are ignored.
+func (r *Repository) RemoveTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error) {
+	const op = "repository.postgres.RemoveTags"
+
+	var movie *model.Movie
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		if err := r.lockMovie(ctx, tx, movieID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := r.builder.Delete("movie_tags").
+			Where(sq.Eq{"movie_id": movieID, "tag": tags}).
+			Suffix("RETURNING tag").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		var removed []string
+		if err := tx.SelectContext(ctx, &removed, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to untag movie: %w", op, err)
+		}
+
+		if err := r.adjustTagUsage(ctx, tx, removed, -1); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		movie, err = r.getMovie(ctx, tx, movieID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if len(removed) == 0 {
+			return nil
+		}
+
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventUpdated, movie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return movie, nil
+}
+
+// ListTags returns tags which are in use along with number of movies
+// labeled by them, most used first. Empty prefix matches all tags.
+func (r *Repository) ListTags(ctx context.Context, prefix string, limit uint64) ([]model.Tag, error) {
+	const op = "repository.postgres.ListTags"
+
+	db := r.reader(ctx)
+
+	builder := r.builder.Select("*").
+		From("tags").
+		Where(sq.Gt{"usage_count": 0})
+	if prefix != "" {
+		builder = builder.Where(sq.Like{"name": escapeLike(prefix) + "%"})
+	}
+
+	query, args, err := builder.
+		OrderBy("usage_count DESC", "name").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	tags := make([]model.Tag, 0, limit)
+	err = db.SelectContext(ctx, &tags, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get tags: %w", op, err)
+	}
+
+	return tags, nil
+}
+
+func (r *Repository) adjustTagUsage(ctx context.Context, tx *sqlx.Tx, tags []string, delta int) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	query, args, err := r.builder.Update("tags").
+		Set("usage_count", sq.Expr("usage_count + ?", delta)).
+		Where(sq.Eq{"name": tags}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update tag usage counts: %w", err)
+	}
+
+	return nil
+}
+
+// loadTags fills in tags of the movies
+func (r *Repository) loadTags(ctx context.Context, q sqlx.QueryerContext, movies ...*model.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(movies))
+	byID := make(map[string][]*model.Movie, len(movies))
+	for _, movie := range movies {
+		movie.Tags = make([]string, 0)
+		if _, ok := byID[movie.ID]; !ok {
+			ids = append(ids, movie.ID)
+		}
+		byID[movie.ID] = append(byID[movie.ID], movie)
+	}
+
+	query, args, err := r.builder.Select("movie_id", "tag").
+		From("movie_tags").
+		Where(sq.Expr("movie_id = ANY(?)", pq.Array(ids))).
+		OrderBy("tag").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var labels []struct {
+		MovieID string `db:"movie_id"`
+		Tag     string `db:"tag"`
+	}
+	if err := sqlx.SelectContext(ctx, q, &labels, query, args...); err != nil {
+		return fmt.Errorf("failed to get tags of movies: %w", err)
+	}
+
+	for _, l := range labels {
+		for _, movie := range byID[l.MovieID] {
+			movie.Tags = append(movie.Tags, l.Tag)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	repo "movie-service/internal/repository"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// txMaxAttempts is how many times InTx runs transaction failing to serialize
+	txMaxAttempts = 5
+	// txRetryBackoff is a pause before the second attempt, doubled after each next one
+	txRetryBackoff = 10 * time.Millisecond
+)
+
+// querier runs queries either on the pool or within a transaction
+type querier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// InTx implements repository.TxManager. Transactions are serializable, so
+// fn sees the same data during the whole transaction.
+func (r *Repository) InTx(ctx context.Context, fn func(r repo.Repository) error) error {
+	const op = "repository.postgres.InTx"
+
+	if r.tx != nil {
+		return r.inTx(ctx, op, func(*sqlx.Tx) error {
+			return fn(r.bind(r.tx, r.depth+1))
+		})
+	}
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	backoff := txRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := r.beginTx(ctx, op, opts, func(tx *sqlx.Tx) error {
+			return fn(r.bind(tx, 0))
+		})
+		if err == nil || attempt == txMaxAttempts || !isSerializationFailure(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", op, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// bind returns copy of repository running all queries within the transaction.
+// Changes of its methods are made in savepoints nested depth times.
+func (r *Repository) bind(tx *sqlx.Tx, depth int) *Repository {
+	bound := *r
+	bound.db, bound.tx, bound.depth = tx, tx, depth
+
+	return &bound
+}
+
+// inTx runs fn inside of transaction. Transaction is committed if fn succeeds
+// and rolled back otherwise. Repository bound to transaction runs fn in
+// savepoint instead.
+func (r *Repository) inTx(ctx context.Context, op string, fn func(tx *sqlx.Tx) error) error {
+	if r.tx != nil {
+		return r.inSavepoint(ctx, op, fn)
+	}
+
+	return r.beginTx(ctx, op, nil, fn)
+}
+
+func (r *Repository) beginTx(ctx context.Context, op string, opts *sql.TxOptions, fn func(tx *sqlx.Tx) error) (err error) {
+	pin(ctx)
+	tx, err := r.pool.BeginTxx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+
+	defer func() {
+		// Transaction is rolled back by cancelled context anyway, so commit
+		// would fail with a vague error
+		if err == nil && ctx.Err() != nil {
+			err = fmt.Errorf("%s: %w", op, ctx.Err())
+		}
+
+		if err != nil {
+			if errRb := tx.Rollback(); errRb != nil && !errors.Is(errRb, sql.ErrTxDone) {
+				err = fmt.Errorf("%s: failed to rollback transaction: %w", op, errors.Join(err, errRb))
+			}
+
+			return
+		}
+
+		if errCm := tx.Commit(); errCm != nil {
+			err = fmt.Errorf("%s: failed to commit transaction: %w", op, errCm)
+		}
+	}()
+
+	return fn(tx)
+}
+
+// inSavepoint runs fn inside of savepoint of the transaction repository is
+// bound to. Savepoint is released if fn succeeds and rolled back otherwise,
+// so the transaction can go on after failure of fn.
+func (r *Repository) inSavepoint(ctx context.Context, op string, fn func(tx *sqlx.Tx) error) (err error) {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	savepoint := fmt.Sprintf("sp_%d", r.depth+1)
+	if _, err := r.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("%s: failed to create savepoint: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			// Rolled back savepoint stays on the stack until it is released.
+			// Context of fn may be cancelled already, transaction has its own one.
+			if _, errRb := r.tx.Exec("ROLLBACK TO SAVEPOINT " + savepoint + "; RELEASE SAVEPOINT " + savepoint); errRb != nil {
+				err = fmt.Errorf("%s: failed to rollback to savepoint: %w", op, errors.Join(err, errRb))
+			}
+
+			return
+		}
+
+		if _, errRl := r.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); errRl != nil {
+			err = fmt.Errorf("%s: failed to release savepoint: %w", op, errRl)
+		}
+	}()
+
+	return fn(r.tx)
+}
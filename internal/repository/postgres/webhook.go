@@ -0,0 +1,342 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// webhookRow is a webhook with event filter stored as postgres array
+type webhookRow struct {
+	model.Webhook
+	EventTypes pq.StringArray `db:"event_types"`
+}
+
+// deliveryRow is a delivery referring to its event by event_id, sequence
+// number of the event is selected along with it
+type deliveryRow struct {
+	model.WebhookDelivery
+	EventID int64 `db:"event_id"`
+}
+
+func (row *webhookRow) toModel() model.Webhook {
+	webhook := row.Webhook
+	webhook.EventTypes = make([]model.MovieEventType, 0, len(row.EventTypes))
+	for _, t := range row.EventTypes {
+		webhook.EventTypes = append(webhook.EventTypes, model.MovieEventType(t))
+	}
+
+	return webhook
+}
+
+func (r *Repository) CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	const op = "repository.postgres.CreateWebhook"
+
+	eventTypes := make(pq.StringArray, 0, len(webhook.EventTypes))
+	for _, t := range webhook.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+
+	query, args, err := r.builder.Insert("webhooks").
+		Columns("url", "secret", "event_types").
+		Values(webhook.URL, webhook.Secret, eventTypes).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var row webhookRow
+	if err := r.db.GetContext(ctx, &row, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to add webhook: %w", op, err)
+	}
+
+	created := row.toModel()
+
+	return &created, nil
+}
+
+func (r *Repository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	const op = "repository.postgres.ListWebhooks"
+
+	db := r.reader(ctx)
+
+	query, args, err := r.builder.Select("*").
+		From("webhooks").
+		OrderBy("created_at", "webhook_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []webhookRow
+	if err := db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to get webhooks: %w", op, err)
+	}
+
+	webhooks := make([]model.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhooks = append(webhooks, row.toModel())
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes the webhook along with its deliveries
+func (r *Repository) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	const op = "repository.postgres.DeleteWebhook"
+
+	query, args, err := r.builder.Delete("webhooks").
+		Where(sq.Eq{"webhook_id": id}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to delete webhook: %w", op, err)
+	}
+
+	num, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get number of deleted rows: %w", op, err)
+	}
+
+	return num > 0, nil
+}
+
+// ListDeliveries returns deliveries of the webhook, newest first.
+// Empty status matches deliveries in any status.
+func (r *Repository) ListDeliveries(ctx context.Context, webhookID string, status model.DeliveryStatus, limit, offset uint64) ([]model.WebhookDelivery, error) {
+	const op = "repository.postgres.ListDeliveries"
+
+	db := r.reader(ctx)
+
+	if err := r.checkWebhook(ctx, db, webhookID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Events of the current transaction have no sequence number until commit
+	builder := r.builder.Select("d.*", "COALESCE(e.seq, 0) AS event_seq", "e.event_type").
+		From("webhook_deliveries d").
+		Join("movie_events e ON e.event_id = d.event_id").
+		Where(sq.Eq{"d.webhook_id": webhookID})
+	if status != "" {
+		builder = builder.Where(sq.Eq{"d.status": status})
+	}
+
+	query, args, err := builder.
+		OrderBy("d.created_at DESC", "d.delivery_id").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []deliveryRow
+	if err := db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to get deliveries: %w", op, err)
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, row.WebhookDelivery)
+	}
+
+	return deliveries, nil
+}
+
+// CreateDelivery schedules one more delivery of the event to the webhook
+// regardless of previous deliveries
+func (r *Repository) CreateDelivery(ctx context.Context, webhookID string, eventSeq uint64) (*model.WebhookDelivery, error) {
+	const op = "repository.postgres.CreateDelivery"
+
+	var delivery deliveryRow
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		if err := r.checkWebhook(ctx, tx, webhookID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := r.builder.Select("event_id", "seq AS event_seq", "event_type").
+			From("movie_events").
+			Where(sq.Eq{"seq": eventSeq}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &delivery, query, args...); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%s: failed to get event: %w", op, repo.ErrEventNotExists)
+			}
+
+			return fmt.Errorf("%s: failed to get event: %w", op, err)
+		}
+
+		query, args, err = r.builder.Insert("webhook_deliveries").
+			Columns("webhook_id", "event_id").
+			Values(webhookID, delivery.EventID).
+			Suffix("RETURNING *").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &delivery, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to add delivery: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery.WebhookDelivery, nil
+}
+
+// ClaimDeliveries takes up to limit pending deliveries which are due, oldest first.
+// Claimed deliveries are hidden from other dispatchers for lease.
+func (r *Repository) ClaimDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]model.WebhookDispatch, error) {
+	const op = "repository.postgres.ClaimDeliveries"
+
+	due := sq.Select("delivery_id").
+		From("webhook_deliveries").
+		Where(sq.Eq{"status": model.DeliveryStatusPending}).
+		Where(sq.Expr("next_attempt_at <= now()")).
+		OrderBy("next_attempt_at").
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	claim := sq.Update("webhook_deliveries").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", sq.Expr("now() + ?::float * interval '1 second'", lease.Seconds())).
+		Where(sq.Expr("delivery_id IN (?)", due)).
+		Suffix("RETURNING *")
+
+	query, args, err := r.builder.Select(
+		"c.*", "e.seq AS event_seq", "e.event_type", "w.url", "w.secret",
+		`e.seq AS "event.seq"`,
+		`e.event_type AS "event.event_type"`,
+		`e.movie_id AS "event.movie_id"`,
+		`e.movie AS "event.movie"`,
+		`e.occurred_at AS "event.occurred_at"`,
+	).
+		Prefix("WITH claimed AS (?)", claim).
+		From("claimed c").
+		Join("webhooks w ON w.webhook_id = c.webhook_id").
+		Join("movie_events e ON e.event_id = c.event_id").
+		OrderBy("c.created_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []struct {
+		deliveryRow
+		URL    string        `db:"url"`
+		Secret string        `db:"secret"`
+		Event  movieEventRow `db:"event"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to claim deliveries: %w", op, err)
+	}
+
+	dispatches := make([]model.WebhookDispatch, 0, len(rows))
+	for _, row := range rows {
+		event, err := row.Event.decode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		dispatches = append(dispatches, model.WebhookDispatch{
+			Delivery: row.WebhookDelivery,
+			URL:      row.URL,
+			Secret:   row.Secret,
+			Event:    event,
+		})
+	}
+
+	return dispatches, nil
+}
+
+// MarkDeliverySucceeded marks delivery as delivered with the response status of the endpoint
+func (r *Repository) MarkDeliverySucceeded(ctx context.Context, id string, responseStatus int) error {
+	const op = "repository.postgres.MarkDeliverySucceeded"
+
+	query, args, err := r.builder.Update("webhook_deliveries").
+		Set("status", model.DeliveryStatusDelivered).
+		Set("response_status", responseStatus).
+		Set("last_error", "").
+		Set("delivered_at", sq.Expr("now()")).
+		Where(sq.Eq{"delivery_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to mark delivery succeeded: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkDeliveryFailed schedules next attempt of the delivery at retryAt,
+// or moves it to dead letters if retryAt is nil
+func (r *Repository) MarkDeliveryFailed(ctx context.Context, id string, retryAt *time.Time, reason string, responseStatus int) error {
+	const op = "repository.postgres.MarkDeliveryFailed"
+
+	builder := r.builder.Update("webhook_deliveries").
+		Set("response_status", responseStatus).
+		Set("last_error", reason)
+	if retryAt != nil {
+		builder = builder.Set("next_attempt_at", *retryAt)
+	} else {
+		builder = builder.Set("status", model.DeliveryStatusDead)
+	}
+
+	query, args, err := builder.
+		Where(sq.Eq{"delivery_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to mark delivery failed: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) checkWebhook(ctx context.Context, q sqlx.QueryerContext, id string) error {
+	query, args, err := r.builder.Select("webhook_id").
+		From("webhooks").
+		Where(sq.Eq{"webhook_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var webhookID string
+	if err := sqlx.GetContext(ctx, q, &webhookID, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get webhook: %w", repo.ErrWebhookNotExists)
+		}
+
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return nil
+}
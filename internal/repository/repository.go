@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"time"
+)
+
+// Repository is a storage of the service. Every storage backend implements
+// it with the same semantics, including errors of this package returned
+// for missing entities.
+type Repository interface {
+	TxManager
+
+	// Movies
+	GetMovie(ctx context.Context, id string) (*model.Movie, error)
+	GetMovies(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error)
+	ListMovies(ctx context.Context, filter *model.MovieFilter, limit, offset uint64) ([]model.Movie, error)
+	CreateMovie(ctx context.Context, movie *model.Movie) (string, error)
+	CreateMovies(ctx context.Context, movies []model.Movie) ([]string, error)
+	UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error)
+	DeleteMovie(ctx context.Context, id string) (bool, error)
+	GetMovieAfter(ctx context.Context, pivot string, filter *model.MovieFilter) (*model.Movie, error)
+	GetSimilarMovies(ctx context.Context, id string, limit uint64) ([]model.SimilarMovie, error)
+	GetFilmography(ctx context.Context, director string) ([]model.Movie, error)
+	GetCatalogStats(ctx context.Context, query *model.CatalogStatsQuery) (*model.CatalogStats, error)
+	ExportMovies(ctx context.Context, filter *model.MovieFilter, batch uint64, fn func([]model.Movie) error) error
+
+	// Ratings and reviews
+	RateMovie(ctx context.Context, rating *model.Rating) (*model.Movie, error)
+	CreateReview(ctx context.Context, review *model.Review) (*model.Review, error)
+	ListReviews(ctx context.Context, movieID string, limit, offset uint64) ([]model.Review, error)
+	UpdateReview(ctx context.Context, review *model.Review) (*model.Review, error)
+	DeleteReview(ctx context.Context, id, userID string) (bool, error)
+	ModerateReview(ctx context.Context, id string, status model.ReviewStatus) (*model.Review, error)
+
+	// Collections
+	CreateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error)
+	GetCollection(ctx context.Context, id string) (*model.Collection, error)
+	UpdateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error)
+	SetCollectionMovies(ctx context.Context, id string, movieIDs []string) (*model.Collection, error)
+	DeleteCollection(ctx context.Context, id string) (bool, error)
+	GetCollectionMovies(ctx context.Context, id string) ([]model.Movie, error)
+
+	// Tags
+	AddTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error)
+	RemoveTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error)
+	ListTags(ctx context.Context, prefix string, limit uint64) ([]model.Tag, error)
+
+	// Watchlist and watch history
+	AddToWatchlist(ctx context.Context, userID, movieID string) (bool, error)
+	RemoveFromWatchlist(ctx context.Context, userID, movieID string) (bool, error)
+	ListWatchlist(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchlistEntry, error)
+	RecordWatch(ctx context.Context, entry *model.WatchHistoryEntry) (*model.WatchHistoryEntry, error)
+	ListWatchHistory(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchHistoryEntry, error)
+
+	// Movie events and their delivery
+	GetMovieEvents(ctx context.Context, afterSeq uint64, limit uint64) ([]model.MovieEvent, error)
+	GetLastMovieEventSeq(ctx context.Context) (uint64, error)
+	ClaimOutboxEvents(ctx context.Context, limit uint64, lease time.Duration) ([]model.OutboxEvent, error)
+	MarkOutboxDelivered(ctx context.Context, seq uint64) error
+	MarkOutboxFailed(ctx context.Context, seq uint64, retryAt time.Time, reason string) error
+	PurgeOutbox(ctx context.Context, deliveredBefore time.Time) (uint64, error)
+	PurgeMovieEvents(ctx context.Context, occurredBefore time.Time) (uint64, error)
+
+	// Webhooks
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) (bool, error)
+	ListDeliveries(ctx context.Context, webhookID string, status model.DeliveryStatus, limit, offset uint64) ([]model.WebhookDelivery, error)
+	CreateDelivery(ctx context.Context, webhookID string, eventSeq uint64) (*model.WebhookDelivery, error)
+	ClaimDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]model.WebhookDispatch, error)
+	MarkDeliverySucceeded(ctx context.Context, id string, responseStatus int) error
+	MarkDeliveryFailed(ctx context.Context, id string, retryAt *time.Time, reason string, responseStatus int) error
+}
+
+// TxManager runs units of work spanning several calls of repository
+type TxManager interface {
+	// InTx runs fn with repository bound to a transaction, which is committed
+	// if fn succeeds and rolled back otherwise. Calling InTx of the repository
+	// given to fn runs nested unit of work in a savepoint: its failure rolls
+	// back only its own changes. The whole transaction is retried if it fails
+	// to serialize with concurrent ones, so fn must have no side effects beyond
+	// the repository. fn must not use any other repository of the same storage
+	// until it returns, since the transaction may hold locks they wait for.
+	InTx(ctx context.Context, fn func(r Repository) error) error
+}
+
+// Notifier wakes up subscribers whenever new movie events are recorded
+type Notifier interface {
+	// Subscribe returns channel receiving wakeups and function cancelling subscription
+	Subscribe() (<-chan struct{}, func())
+	Close() error
+}
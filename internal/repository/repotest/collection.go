@@ -0,0 +1,196 @@
+package repotest
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"testing"
+)
+
+func testCollections(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	alien := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	aliens := createMovie(t, r, "Aliens", "Sci-Fi", "James Cameron", 1986)
+	alien3 := createMovie(t, r, "Alien 3", "Sci-Fi", "David Fincher", 1992)
+
+	collection, err := r.CreateCollection(ctx, &model.Collection{
+		Name:     "Alien",
+		Kind:     model.CollectionKindFranchise,
+		MovieIDs: []string{aliens, alien},
+	})
+	wantNoErr(t, "CreateCollection", err)
+	wantEqual(t, "Name", collection.Name, "Alien")
+	wantSlice(t, "MovieIDs", collection.MovieIDs, []string{aliens, alien})
+
+	got, err := r.GetCollection(ctx, collection.ID)
+	wantNoErr(t, "GetCollection", err)
+	wantSlice(t, "stored MovieIDs", got.MovieIDs, []string{aliens, alien})
+	wantSlice(t, "CollectionIDs of movie", getMovie(t, r, alien).CollectionIDs, []string{collection.ID})
+
+	got, err = r.UpdateCollection(ctx, &model.Collection{ID: collection.ID, Description: "Quadrilogy"})
+	wantNoErr(t, "UpdateCollection", err)
+	wantEqual(t, "updated Description", got.Description, "Quadrilogy")
+	wantEqual(t, "updated Name", got.Name, "Alien")
+	wantSlice(t, "MovieIDs after update", got.MovieIDs, []string{aliens, alien})
+
+	got, err = r.SetCollectionMovies(ctx, collection.ID, []string{alien, aliens, alien3})
+	wantNoErr(t, "SetCollectionMovies", err)
+	wantSlice(t, "MovieIDs after reordering", got.MovieIDs, []string{alien, aliens, alien3})
+
+	movies, err := r.GetCollectionMovies(ctx, collection.ID)
+	wantNoErr(t, "GetCollectionMovies", err)
+	wantSlice(t, "movies of collection", movieIDs(movies), []string{alien, aliens, alien3})
+
+	_, err = r.SetCollectionMovies(ctx, collection.ID, []string{alien, "00000000-0000-0000-0000-000000000000"})
+	wantErr(t, "SetCollectionMovies with missing movie", err, repository.ErrMovieNotExists)
+
+	got, err = r.GetCollection(ctx, collection.ID)
+	wantNoErr(t, "GetCollection", err)
+	wantSlice(t, "MovieIDs after failed change", got.MovieIDs, []string{alien, aliens, alien3})
+
+	// Deleted movie leaves the collection
+	_, err = r.DeleteMovie(ctx, aliens)
+	wantNoErr(t, "DeleteMovie", err)
+
+	got, err = r.GetCollection(ctx, collection.ID)
+	wantNoErr(t, "GetCollection", err)
+	wantSlice(t, "MovieIDs after movie deletion", got.MovieIDs, []string{alien, alien3})
+
+	deleted, err := r.DeleteCollection(ctx, collection.ID)
+	wantNoErr(t, "DeleteCollection", err)
+	wantEqual(t, "deleted", deleted, true)
+	wantSlice(t, "CollectionIDs after deletion", getMovie(t, r, alien).CollectionIDs, []string{})
+
+	deleted, err = r.DeleteCollection(ctx, collection.ID)
+	wantNoErr(t, "DeleteCollection of deleted collection", err)
+	wantEqual(t, "deleted again", deleted, false)
+
+	_, err = r.GetCollection(ctx, collection.ID)
+	wantErr(t, "GetCollection", err, repository.ErrCollectionNotExists)
+
+	_, err = r.GetCollectionMovies(ctx, collection.ID)
+	wantErr(t, "GetCollectionMovies", err, repository.ErrCollectionNotExists)
+
+	_, err = r.UpdateCollection(ctx, &model.Collection{ID: collection.ID, Name: "Missing"})
+	wantErr(t, "UpdateCollection", err, repository.ErrCollectionNotExists)
+
+	_, err = r.SetCollectionMovies(ctx, collection.ID, []string{alien})
+	wantErr(t, "SetCollectionMovies", err, repository.ErrCollectionNotExists)
+}
+
+func testTags(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	alien := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	heat := createMovie(t, r, "Heat", "Crime", "Michael Mann", 1995)
+
+	movie, err := r.AddTags(ctx, alien, []string{"space", "classic"})
+	wantNoErr(t, "AddTags", err)
+	wantSlice(t, "Tags", movie.Tags, []string{"classic", "space"})
+
+	// Tags which the movie already has are ignored
+	movie, err = r.AddTags(ctx, alien, []string{"space", "horror"})
+	wantNoErr(t, "AddTags", err)
+	wantSlice(t, "Tags", movie.Tags, []string{"classic", "horror", "space"})
+
+	_, err = r.AddTags(ctx, heat, []string{"classic", "heist"})
+	wantNoErr(t, "AddTags", err)
+
+	tags, err := r.ListTags(ctx, "", 10)
+	wantNoErr(t, "ListTags", err)
+	wantSlice(t, "tags", tags, []model.Tag{
+		{Name: "classic", UsageCount: 2},
+		{Name: "heist", UsageCount: 1},
+		{Name: "horror", UsageCount: 1},
+		{Name: "space", UsageCount: 1},
+	})
+
+	tags, err = r.ListTags(ctx, "h", 1)
+	wantNoErr(t, "ListTags with prefix", err)
+	wantSlice(t, "tags with prefix", tags, []model.Tag{{Name: "heist", UsageCount: 1}})
+
+	tags, err = r.ListTags(ctx, "%", 10)
+	wantNoErr(t, "ListTags with wildcard", err)
+	wantEqual(t, "tags matching wildcard", len(tags), 0)
+
+	movie, err = r.RemoveTags(ctx, alien, []string{"horror", "unknown"})
+	wantNoErr(t, "RemoveTags", err)
+	wantSlice(t, "Tags after removal", movie.Tags, []string{"classic", "space"})
+
+	// Tags of deleted movie are not counted anymore
+	_, err = r.DeleteMovie(ctx, heat)
+	wantNoErr(t, "DeleteMovie", err)
+
+	tags, err = r.ListTags(ctx, "", 10)
+	wantNoErr(t, "ListTags", err)
+	wantSlice(t, "tags after deletion", tags, []model.Tag{
+		{Name: "classic", UsageCount: 1},
+		{Name: "space", UsageCount: 1},
+	})
+}
+
+func testCollectionEvents(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	alien := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	aliens := createMovie(t, r, "Aliens", "Sci-Fi", "James Cameron", 1986)
+	alien3 := createMovie(t, r, "Alien 3", "Sci-Fi", "David Fincher", 1992)
+
+	// wantEvents checks that movies which joined or left the collection got
+	// updated events with actual collection IDs, and others got none
+	wantEvents := func(name string, after uint64, want map[string][]string) uint64 {
+		t.Helper()
+
+		events, err := r.GetMovieEvents(ctx, after, 10)
+		wantNoErr(t, "GetMovieEvents", err)
+		if len(events) != len(want) {
+			t.Fatalf("%s: got %d events, want %d", name, len(events), len(want))
+		}
+		for _, event := range events {
+			collectionIDs, ok := want[event.MovieID]
+			if !ok {
+				t.Fatalf("%s: unexpected event of movie %s", name, event.MovieID)
+			}
+			wantEqual(t, name+" Type", event.Type, model.MovieEventUpdated)
+			wantSlice(t, name+" CollectionIDs", event.Movie.CollectionIDs, collectionIDs)
+			after = event.Seq
+		}
+
+		return after
+	}
+
+	seq, err := r.GetLastMovieEventSeq(ctx)
+	wantNoErr(t, "GetLastMovieEventSeq", err)
+
+	collection, err := r.CreateCollection(ctx, &model.Collection{
+		Name:     "Alien",
+		Kind:     model.CollectionKindFranchise,
+		MovieIDs: []string{alien, aliens},
+	})
+	wantNoErr(t, "CreateCollection", err)
+	seq = wantEvents("CreateCollection", seq, map[string][]string{
+		alien:  {collection.ID},
+		aliens: {collection.ID},
+	})
+
+	_, err = r.SetCollectionMovies(ctx, collection.ID, []string{aliens, alien3})
+	wantNoErr(t, "SetCollectionMovies", err)
+	seq = wantEvents("SetCollectionMovies", seq, map[string][]string{
+		alien:  {},
+		alien3: {collection.ID},
+	})
+
+	// Reordering doesn't change membership
+	_, err = r.SetCollectionMovies(ctx, collection.ID, []string{alien3, aliens})
+	wantNoErr(t, "SetCollectionMovies", err)
+	seq = wantEvents("reordering", seq, map[string][]string{})
+
+	deleted, err := r.DeleteCollection(ctx, collection.ID)
+	wantNoErr(t, "DeleteCollection", err)
+	wantEqual(t, "deleted", deleted, true)
+	wantEvents("DeleteCollection", seq, map[string][]string{
+		aliens: {},
+		alien3: {},
+	})
+}
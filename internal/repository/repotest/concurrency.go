@@ -0,0 +1,164 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+// workers is a number of goroutines calling repository at once
+const workers = 16
+
+// parallel calls fn from workers goroutines at once and fails the test
+// if any call fails
+func parallel(t *testing.T, fn func(i int) error) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn(i)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("worker %d failed: %v", i, err)
+		}
+	}
+}
+
+func testConcurrentCreates(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	lastSeq, err := r.GetLastMovieEventSeq(ctx)
+	wantNoErr(t, "GetLastMovieEventSeq", err)
+
+	ids := make([]string, workers)
+	parallel(t, func(i int) error {
+		var err error
+		ids[i], err = r.CreateMovie(ctx, &model.Movie{
+			Title:    fmt.Sprintf("Movie %02d", i),
+			Genre:    "Drama",
+			Director: "Director",
+			Year:     2000,
+		})
+		return err
+	})
+
+	movies, err := r.GetMovies(ctx, nil)
+	wantNoErr(t, "GetMovies", err)
+	wantEqual(t, "number of movies", len(movies), workers)
+
+	// Every creation gets its own event and sequence numbers have no gaps
+	events, err := r.GetMovieEvents(ctx, lastSeq, 2*workers)
+	wantNoErr(t, "GetMovieEvents", err)
+	if len(events) != workers {
+		t.Fatalf("GetMovieEvents returned %d events, want %d", len(events), workers)
+	}
+
+	seen := make(map[string]bool, workers)
+	for i, event := range events {
+		wantEqual(t, "Seq", event.Seq, lastSeq+uint64(i)+1)
+		seen[event.MovieID] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			t.Fatalf("no event about creation of movie %s", id)
+		}
+	}
+}
+
+func testConcurrentRatings(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	id := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	// Every user rates twice, so updates of aggregates race with each other
+	parallel(t, func(i int) error {
+		user := fmt.Sprintf("user-%d", i)
+		if _, err := r.RateMovie(ctx, &model.Rating{MovieID: id, UserID: user, Score: 1}); err != nil {
+			return err
+		}
+
+		_, err := r.RateMovie(ctx, &model.Rating{MovieID: id, UserID: user, Score: uint32(i%10 + 1)})
+		return err
+	})
+
+	var wantSum uint64
+	for i := range workers {
+		wantSum += uint64(i%10 + 1)
+	}
+
+	movie := getMovie(t, r, id)
+	wantEqual(t, "RatingCount", movie.RatingCount, workers)
+	wantEqual(t, "RatingSum", movie.RatingSum, wantSum)
+}
+
+func testConcurrentTags(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	id := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	// Tag is counted once however many times it's added at once
+	parallel(t, func(i int) error {
+		_, err := r.AddTags(ctx, id, []string{"space", fmt.Sprintf("tag-%02d", i)})
+		return err
+	})
+
+	wantEqual(t, "number of tags", len(getMovie(t, r, id).Tags), workers+1)
+
+	tags, err := r.ListTags(ctx, "space", 10)
+	wantNoErr(t, "ListTags", err)
+	wantSlice(t, "tags", tags, []model.Tag{{Name: "space", UsageCount: 1}})
+
+	parallel(t, func(i int) error {
+		_, err := r.RemoveTags(ctx, id, []string{"space"})
+		return err
+	})
+
+	tags, err = r.ListTags(ctx, "", 2*workers)
+	wantNoErr(t, "ListTags", err)
+	wantEqual(t, "number of tags in use", len(tags), workers)
+}
+
+func testConcurrentOutboxClaims(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	for i := range 2 * workers {
+		createMovie(t, r, fmt.Sprintf("Movie %02d", i), "Drama", "Director", 2000)
+	}
+
+	// Relays claiming at once never get the same event
+	claims := make([][]model.OutboxEvent, workers)
+	parallel(t, func(i int) error {
+		var err error
+		claims[i], err = r.ClaimOutboxEvents(ctx, 3, time.Minute)
+		return err
+	})
+
+	// Locked events are skipped rather than waited for, so some may be left
+	// for the next claim
+	rest, err := r.ClaimOutboxEvents(ctx, 2*workers, time.Minute)
+	wantNoErr(t, "ClaimOutboxEvents", err)
+	claims = append(claims, rest)
+
+	seen := make(map[uint64]bool)
+	for _, events := range claims {
+		for _, event := range events {
+			if seen[event.Seq] {
+				t.Fatalf("event %d is claimed twice", event.Seq)
+			}
+			seen[event.Seq] = true
+		}
+	}
+	wantEqual(t, "number of claimed events", len(seen), 2*workers)
+}
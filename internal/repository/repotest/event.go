@@ -0,0 +1,279 @@
+package repotest
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"testing"
+	"time"
+)
+
+func testMovieEvents(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	lastSeq, err := r.GetLastMovieEventSeq(ctx)
+	wantNoErr(t, "GetLastMovieEventSeq", err)
+
+	id := createMovie(t, r, "Alien", "Horror", "Ridley Scott", 1979)
+	_, err = r.UpdateMovie(ctx, id, &model.Movie{Genre: "Sci-Fi"})
+	wantNoErr(t, "UpdateMovie", err)
+	_, err = r.DeleteMovie(ctx, id)
+	wantNoErr(t, "DeleteMovie", err)
+
+	seq, err := r.GetLastMovieEventSeq(ctx)
+	wantNoErr(t, "GetLastMovieEventSeq", err)
+	wantEqual(t, "last seq", seq, lastSeq+3)
+
+	events, err := r.GetMovieEvents(ctx, lastSeq, 10)
+	wantNoErr(t, "GetMovieEvents", err)
+	if len(events) != 3 {
+		t.Fatalf("GetMovieEvents returned %d events, want 3", len(events))
+	}
+
+	wantTypes := []model.MovieEventType{model.MovieEventCreated, model.MovieEventUpdated, model.MovieEventDeleted}
+	wantGenres := []string{"Horror", "Sci-Fi", "Sci-Fi"}
+	for i, event := range events {
+		wantEqual(t, "Seq", event.Seq, lastSeq+uint64(i)+1)
+		wantEqual(t, "Type", event.Type, wantTypes[i])
+		wantEqual(t, "MovieID", event.MovieID, id)
+		wantEqual(t, "Movie.ID", event.Movie.ID, id)
+		wantEqual(t, "Movie.Genre", event.Movie.Genre, wantGenres[i])
+		if event.OccurredAt.IsZero() {
+			t.Fatalf("event %d has no time", event.Seq)
+		}
+	}
+
+	events, err = r.GetMovieEvents(ctx, lastSeq+1, 1)
+	wantNoErr(t, "GetMovieEvents", err)
+	if len(events) != 1 || events[0].Seq != lastSeq+2 {
+		t.Fatalf("GetMovieEvents = %+v, want event %d only", events, lastSeq+2)
+	}
+
+	events, err = r.GetMovieEvents(ctx, seq, 10)
+	wantNoErr(t, "GetMovieEvents", err)
+	wantEqual(t, "events after the last one", len(events), 0)
+}
+
+func testOutbox(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	createMovie(t, r, "Heat", "Crime", "Michael Mann", 1995)
+
+	events, err := r.ClaimOutboxEvents(ctx, 10, time.Minute)
+	wantNoErr(t, "ClaimOutboxEvents", err)
+	if len(events) != 2 || events[0].Seq >= events[1].Seq {
+		t.Fatalf("ClaimOutboxEvents = %+v, want 2 events oldest first", events)
+	}
+	wantEqual(t, "Attempts", events[0].Attempts, 1)
+	wantEqual(t, "Movie.Title", events[0].Movie.Title, "Alien")
+
+	// Claimed events are hidden until lease expires
+	claimed, err := r.ClaimOutboxEvents(ctx, 10, time.Minute)
+	wantNoErr(t, "ClaimOutboxEvents", err)
+	wantEqual(t, "events claimed twice", len(claimed), 0)
+
+	wantNoErr(t, "MarkOutboxDelivered", r.MarkOutboxDelivered(ctx, events[0].Seq))
+	wantNoErr(t, "MarkOutboxFailed", r.MarkOutboxFailed(ctx, events[1].Seq, time.Now().Add(-time.Second), "unavailable"))
+
+	claimed, err = r.ClaimOutboxEvents(ctx, 10, time.Minute)
+	wantNoErr(t, "ClaimOutboxEvents", err)
+	if len(claimed) != 1 || claimed[0].Seq != events[1].Seq {
+		t.Fatalf("ClaimOutboxEvents = %+v, want failed event %d only", claimed, events[1].Seq)
+	}
+	wantEqual(t, "Attempts of retried event", claimed[0].Attempts, 2)
+
+	// Event scheduled for later is not due yet
+	wantNoErr(t, "MarkOutboxFailed", r.MarkOutboxFailed(ctx, claimed[0].Seq, time.Now().Add(time.Hour), "unavailable"))
+
+	claimed, err = r.ClaimOutboxEvents(ctx, 10, 0)
+	wantNoErr(t, "ClaimOutboxEvents", err)
+	wantEqual(t, "events claimed before retry time", len(claimed), 0)
+
+	// Only delivered events are purged
+	purged, err := r.PurgeOutbox(ctx, time.Now().Add(-time.Hour))
+	wantNoErr(t, "PurgeOutbox", err)
+	wantEqual(t, "events purged before delivery time", purged, 0)
+
+	purged, err = r.PurgeOutbox(ctx, time.Now().Add(time.Hour))
+	wantNoErr(t, "PurgeOutbox", err)
+	wantEqual(t, "purged events", purged, 1)
+
+	wantNoErr(t, "MarkOutboxFailed", r.MarkOutboxFailed(ctx, events[1].Seq, time.Now().Add(-time.Second), "unavailable"))
+	claimed, err = r.ClaimOutboxEvents(ctx, 10, time.Minute)
+	wantNoErr(t, "ClaimOutboxEvents", err)
+	if len(claimed) != 1 || claimed[0].Seq != events[1].Seq {
+		t.Fatalf("ClaimOutboxEvents after purge = %+v, want undelivered event %d", claimed, events[1].Seq)
+	}
+
+	// Events stay in the log after they leave the outbox
+	logged, err := r.GetMovieEvents(ctx, 0, 10)
+	wantNoErr(t, "GetMovieEvents", err)
+	wantEqual(t, "number of events", len(logged), 2)
+}
+
+func testPurgeMovieEvents(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	lastSeq, err := r.GetLastMovieEventSeq(ctx)
+	wantNoErr(t, "GetLastMovieEventSeq", err)
+	for _, title := range []string{"Alien", "Aliens", "Alien 3", "Alien Resurrection"} {
+		createMovie(t, r, title, "Sci-Fi", "Director", 1979)
+	}
+
+	// Events waiting in the outbox are not purged
+	purged, err := r.PurgeMovieEvents(ctx, time.Now().Add(time.Hour))
+	wantNoErr(t, "PurgeMovieEvents", err)
+	wantEqual(t, "events purged before delivery", purged, 0)
+
+	events, err := r.ClaimOutboxEvents(ctx, 10, time.Minute)
+	wantNoErr(t, "ClaimOutboxEvents", err)
+	wantEqual(t, "claimed events", len(events), 4)
+	for _, event := range events[:2] {
+		wantNoErr(t, "MarkOutboxDelivered", r.MarkOutboxDelivered(ctx, event.Seq))
+	}
+
+	purged, err = r.PurgeMovieEvents(ctx, time.Now().Add(-time.Hour))
+	wantNoErr(t, "PurgeMovieEvents", err)
+	wantEqual(t, "events purged before they occurred", purged, 0)
+
+	// Events are purged up to the first one waiting in the outbox
+	purged, err = r.PurgeMovieEvents(ctx, time.Now().Add(time.Hour))
+	wantNoErr(t, "PurgeMovieEvents", err)
+	wantEqual(t, "purged events", purged, 2)
+
+	for _, afterSeq := range []uint64{lastSeq, lastSeq + 1} {
+		_, err = r.GetMovieEvents(ctx, afterSeq, 10)
+		wantErr(t, "GetMovieEvents of purged events", err, repository.ErrEventsPurged)
+	}
+	left, err := r.GetMovieEvents(ctx, lastSeq+2, 10)
+	wantNoErr(t, "GetMovieEvents", err)
+	if len(left) != 2 || left[0].Seq != lastSeq+3 {
+		t.Fatalf("GetMovieEvents = %+v, want 2 events from %d", left, lastSeq+3)
+	}
+
+	// The latest event is kept, so sequence numbers go on
+	for _, event := range events[2:] {
+		wantNoErr(t, "MarkOutboxDelivered", r.MarkOutboxDelivered(ctx, event.Seq))
+	}
+	purged, err = r.PurgeMovieEvents(ctx, time.Now().Add(time.Hour))
+	wantNoErr(t, "PurgeMovieEvents", err)
+	wantEqual(t, "purged events", purged, 1)
+
+	seq, err := r.GetLastMovieEventSeq(ctx)
+	wantNoErr(t, "GetLastMovieEventSeq", err)
+	wantEqual(t, "last seq", seq, lastSeq+4)
+
+	// Events waiting for webhook delivery are not purged either
+	_, err = r.CreateWebhook(ctx, &model.Webhook{URL: "https://example.com/all", Secret: "secret-of-all-events"})
+	wantNoErr(t, "CreateWebhook", err)
+	createMovie(t, r, "Prometheus", "Sci-Fi", "Ridley Scott", 2012)
+	createMovie(t, r, "Covenant", "Sci-Fi", "Ridley Scott", 2017)
+
+	events, err = r.ClaimOutboxEvents(ctx, 10, time.Minute)
+	wantNoErr(t, "ClaimOutboxEvents", err)
+	for _, event := range events {
+		wantNoErr(t, "MarkOutboxDelivered", r.MarkOutboxDelivered(ctx, event.Seq))
+	}
+
+	purged, err = r.PurgeMovieEvents(ctx, time.Now().Add(time.Hour))
+	wantNoErr(t, "PurgeMovieEvents", err)
+	wantEqual(t, "purged events", purged, 1)
+
+	left, err = r.GetMovieEvents(ctx, lastSeq+4, 10)
+	wantNoErr(t, "GetMovieEvents", err)
+	wantEqual(t, "events waiting for webhooks", len(left), 2)
+}
+
+func testWebhooks(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	all, err := r.CreateWebhook(ctx, &model.Webhook{URL: "https://example.com/all", Secret: "secret-of-all-events"})
+	wantNoErr(t, "CreateWebhook", err)
+	wantSlice(t, "EventTypes", all.EventTypes, []model.MovieEventType{})
+	if all.ID == "" || all.CreatedAt.IsZero() {
+		t.Fatalf("CreateWebhook returned webhook without ID or creation time: %+v", all)
+	}
+
+	deletions, err := r.CreateWebhook(ctx, &model.Webhook{
+		URL:        "https://example.com/deleted",
+		Secret:     "secret-of-deletions",
+		EventTypes: []model.MovieEventType{model.MovieEventDeleted},
+	})
+	wantNoErr(t, "CreateWebhook", err)
+	wantSlice(t, "EventTypes", deletions.EventTypes, []model.MovieEventType{model.MovieEventDeleted})
+
+	webhooks, err := r.ListWebhooks(ctx)
+	wantNoErr(t, "ListWebhooks", err)
+	if len(webhooks) != 2 {
+		t.Fatalf("ListWebhooks returned %d webhooks, want 2", len(webhooks))
+	}
+
+	// Only webhook interested in all events gets creation
+	createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	deliveries, err := r.ListDeliveries(ctx, all.ID, "", 10, 0)
+	wantNoErr(t, "ListDeliveries", err)
+	if len(deliveries) != 1 || deliveries[0].EventType != model.MovieEventCreated || deliveries[0].Status != model.DeliveryStatusPending {
+		t.Fatalf("ListDeliveries = %+v, want pending delivery of creation", deliveries)
+	}
+
+	deliveries, err = r.ListDeliveries(ctx, deletions.ID, "", 10, 0)
+	wantNoErr(t, "ListDeliveries", err)
+	wantEqual(t, "deliveries of uninterested webhook", len(deliveries), 0)
+
+	dispatches, err := r.ClaimDeliveries(ctx, 10, time.Minute)
+	wantNoErr(t, "ClaimDeliveries", err)
+	if len(dispatches) != 1 {
+		t.Fatalf("ClaimDeliveries returned %d deliveries, want 1", len(dispatches))
+	}
+	dispatch := dispatches[0]
+	wantEqual(t, "URL", dispatch.URL, all.URL)
+	wantEqual(t, "Secret", dispatch.Secret, all.Secret)
+	wantEqual(t, "Attempts", dispatch.Delivery.Attempts, 1)
+	wantEqual(t, "Event.Type", dispatch.Event.Type, model.MovieEventCreated)
+	wantEqual(t, "Event.Movie.Title", dispatch.Event.Movie.Title, "Alien")
+
+	dispatches, err = r.ClaimDeliveries(ctx, 10, time.Minute)
+	wantNoErr(t, "ClaimDeliveries", err)
+	wantEqual(t, "deliveries claimed twice", len(dispatches), 0)
+
+	wantNoErr(t, "MarkDeliverySucceeded", r.MarkDeliverySucceeded(ctx, dispatch.Delivery.ID, 204))
+
+	deliveries, err = r.ListDeliveries(ctx, all.ID, model.DeliveryStatusDelivered, 10, 0)
+	wantNoErr(t, "ListDeliveries", err)
+	if len(deliveries) != 1 || deliveries[0].ResponseStatus != 204 || deliveries[0].DeliveredAt == nil {
+		t.Fatalf("ListDeliveries = %+v, want delivered delivery", deliveries)
+	}
+
+	// Redelivery of the event which then runs out of attempts
+	delivery, err := r.CreateDelivery(ctx, all.ID, dispatch.Event.Seq)
+	wantNoErr(t, "CreateDelivery", err)
+	wantEqual(t, "EventType", delivery.EventType, model.MovieEventCreated)
+	wantEqual(t, "Status", delivery.Status, model.DeliveryStatusPending)
+
+	wantNoErr(t, "MarkDeliveryFailed", r.MarkDeliveryFailed(ctx, delivery.ID, nil, "gone", 410))
+
+	deliveries, err = r.ListDeliveries(ctx, all.ID, model.DeliveryStatusDead, 10, 0)
+	wantNoErr(t, "ListDeliveries", err)
+	if len(deliveries) != 1 || deliveries[0].ID != delivery.ID || deliveries[0].LastError != "gone" {
+		t.Fatalf("ListDeliveries = %+v, want dead delivery", deliveries)
+	}
+
+	_, err = r.CreateDelivery(ctx, all.ID, dispatch.Event.Seq+100)
+	wantErr(t, "CreateDelivery of missing event", err, repository.ErrEventNotExists)
+
+	deleted, err := r.DeleteWebhook(ctx, all.ID)
+	wantNoErr(t, "DeleteWebhook", err)
+	wantEqual(t, "deleted", deleted, true)
+
+	deleted, err = r.DeleteWebhook(ctx, all.ID)
+	wantNoErr(t, "DeleteWebhook of deleted webhook", err)
+	wantEqual(t, "deleted again", deleted, false)
+
+	_, err = r.ListDeliveries(ctx, all.ID, "", 10, 0)
+	wantErr(t, "ListDeliveries", err, repository.ErrWebhookNotExists)
+
+	_, err = r.CreateDelivery(ctx, all.ID, dispatch.Event.Seq)
+	wantErr(t, "CreateDelivery", err, repository.ErrWebhookNotExists)
+}
@@ -0,0 +1,425 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"testing"
+)
+
+func testMovies(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	id := createMovie(t, r, "Alien", "Horror", "Ridley Scott", 1979)
+
+	movie := getMovie(t, r, id)
+	wantEqual(t, "ID", movie.ID, id)
+	wantEqual(t, "Title", movie.Title, "Alien")
+	wantEqual(t, "Genre", movie.Genre, "Horror")
+	wantEqual(t, "Director", movie.Director, "Ridley Scott")
+	wantEqual(t, "Year", movie.Year, 1979)
+	wantEqual(t, "RatingCount", movie.RatingCount, 0)
+	wantSlice(t, "Tags", movie.Tags, []string{})
+	wantSlice(t, "CollectionIDs", movie.CollectionIDs, []string{})
+
+	updated, err := r.UpdateMovie(ctx, id, &model.Movie{Genre: "Sci-Fi"})
+	wantNoErr(t, "UpdateMovie", err)
+	wantEqual(t, "updated Genre", updated.Genre, "Sci-Fi")
+	wantEqual(t, "updated Title", updated.Title, "Alien")
+	wantEqual(t, "stored Genre", getMovie(t, r, id).Genre, "Sci-Fi")
+
+	deleted, err := r.DeleteMovie(ctx, id)
+	wantNoErr(t, "DeleteMovie", err)
+	wantEqual(t, "deleted", deleted, true)
+
+	deleted, err = r.DeleteMovie(ctx, id)
+	wantNoErr(t, "DeleteMovie of deleted movie", err)
+	wantEqual(t, "deleted again", deleted, false)
+
+	_, err = r.GetMovie(ctx, id)
+	wantErr(t, "GetMovie of deleted movie", err, repository.ErrMovieNotExists)
+}
+
+func testMovieNotFound(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+	const missing = "00000000-0000-0000-0000-000000000000"
+
+	_, err := r.GetMovie(ctx, missing)
+	wantErr(t, "GetMovie", err, repository.ErrMovieNotExists)
+
+	_, err = r.UpdateMovie(ctx, missing, &model.Movie{Title: "Missing"})
+	wantErr(t, "UpdateMovie", err, repository.ErrMovieNotExists)
+
+	_, err = r.GetSimilarMovies(ctx, missing, 10)
+	wantErr(t, "GetSimilarMovies", err, repository.ErrMovieNotExists)
+
+	_, err = r.GetMovieAfter(ctx, missing, nil)
+	wantErr(t, "GetMovieAfter in empty catalog", err, repository.ErrMovieNotExists)
+
+	_, err = r.RateMovie(ctx, &model.Rating{MovieID: missing, UserID: "user", Score: 5})
+	wantErr(t, "RateMovie", err, repository.ErrMovieNotExists)
+
+	_, err = r.AddTags(ctx, missing, []string{"tag"})
+	wantErr(t, "AddTags", err, repository.ErrMovieNotExists)
+
+	_, err = r.RemoveTags(ctx, missing, []string{"tag"})
+	wantErr(t, "RemoveTags", err, repository.ErrMovieNotExists)
+
+	_, err = r.CreateReview(ctx, &model.Review{MovieID: missing, UserID: "user", Text: "text"})
+	wantErr(t, "CreateReview", err, repository.ErrMovieNotExists)
+
+	_, err = r.AddToWatchlist(ctx, "user", missing)
+	wantErr(t, "AddToWatchlist", err, repository.ErrMovieNotExists)
+
+	_, err = r.RecordWatch(ctx, &model.WatchHistoryEntry{UserID: "user", MovieID: missing})
+	wantErr(t, "RecordWatch", err, repository.ErrMovieNotExists)
+}
+
+func testMovieFilter(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	alien := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	blade := createMovie(t, r, "Blade Runner", "Sci-Fi", "Ridley Scott", 1982)
+	gladiator := createMovie(t, r, "Gladiator", "Drama", "Ridley Scott", 2000)
+	heat := createMovie(t, r, "Heat", "Crime", "Michael Mann", 1995)
+
+	_, err := r.AddTags(ctx, alien, []string{"space", "classic"})
+	wantNoErr(t, "AddTags", err)
+	_, err = r.AddTags(ctx, blade, []string{"classic", "noir"})
+	wantNoErr(t, "AddTags", err)
+	_, err = r.AddTags(ctx, heat, []string{"noir"})
+	wantNoErr(t, "AddTags", err)
+
+	tests := []struct {
+		name   string
+		filter *model.MovieFilter
+		want   []string
+	}{
+		{"nil", nil, []string{alien, blade, gladiator, heat}},
+		{"zero", &model.MovieFilter{}, []string{alien, blade, gladiator, heat}},
+		{"genre ignores case", &model.MovieFilter{Genre: "sci-fi"}, []string{alien, blade}},
+		{"director ignores case", &model.MovieFilter{Director: "MICHAEL MANN"}, []string{heat}},
+		{"year from", &model.MovieFilter{YearFrom: 1995}, []string{gladiator, heat}},
+		{"year to", &model.MovieFilter{YearTo: 1982}, []string{alien, blade}},
+		{"year range", &model.MovieFilter{YearFrom: 1980, YearTo: 1999}, []string{blade, heat}},
+		{"all tags", &model.MovieFilter{TagsAll: []string{"classic", "noir"}}, []string{blade}},
+		{"any tag", &model.MovieFilter{TagsAny: []string{"space", "noir"}}, []string{alien, blade, heat}},
+		{"combined", &model.MovieFilter{TagsAny: []string{"noir"}, Genre: "Sci-Fi"}, []string{blade}},
+		{"no match", &model.MovieFilter{Genre: "Western"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movies, err := r.ListMovies(ctx, tt.filter, 10, 0)
+			wantNoErr(t, "ListMovies", err)
+			wantSlice(t, "ListMovies", movieIDs(movies), tt.want)
+
+			movies, err = r.GetMovies(ctx, tt.filter)
+			wantNoErr(t, "GetMovies", err)
+			if len(movies) != len(tt.want) {
+				t.Fatalf("GetMovies returned %d movies, want %d", len(movies), len(tt.want))
+			}
+		})
+	}
+
+	page, err := r.ListMovies(ctx, nil, 2, 1)
+	wantNoErr(t, "ListMovies", err)
+	wantSlice(t, "page of movies", titles(page), []string{"Blade Runner", "Gladiator"})
+	wantSlice(t, "tags of listed movie", page[0].Tags, []string{"classic", "noir"})
+}
+
+func testExportMovies(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	for _, title := range []string{"E", "C", "A", "D", "B"} {
+		createMovie(t, r, title, "Drama", "Director", 2000)
+	}
+	createMovie(t, r, "F", "Comedy", "Director", 2000)
+
+	var batches [][]string
+	err := r.ExportMovies(ctx, &model.MovieFilter{Genre: "Drama"}, 2, func(movies []model.Movie) error {
+		batches = append(batches, titles(movies))
+		return nil
+	})
+	wantNoErr(t, "ExportMovies", err)
+
+	want := [][]string{{"A", "B"}, {"C", "D"}, {"E"}}
+	if len(batches) != len(want) {
+		t.Fatalf("ExportMovies passed %v, want %v", batches, want)
+	}
+	for i := range want {
+		wantSlice(t, "batch", batches[i], want[i])
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = r.ExportMovies(ctx, nil, 2, func(movies []model.Movie) error {
+		calls++
+		return errStop
+	})
+	wantErr(t, "ExportMovies stopped by fn", err, errStop)
+	wantEqual(t, "calls of fn", calls, 1)
+}
+
+func testFilmography(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	createMovie(t, r, "Volver", "Drama", "Pedro Almodóvar", 2006)
+	createMovie(t, r, "Talk to Her", "Drama", "pedro  almodovar", 2002)
+	createMovie(t, r, "Heat", "Crime", "Michael Mann", 1995)
+
+	movies, err := r.GetFilmography(ctx, " PEDRO ALMODOVAR ")
+	wantNoErr(t, "GetFilmography", err)
+	wantSlice(t, "filmography", titles(movies), []string{"Talk to Her", "Volver"})
+
+	movies, err = r.GetFilmography(ctx, "Nobody")
+	wantNoErr(t, "GetFilmography of unknown director", err)
+	wantEqual(t, "movies of unknown director", len(movies), 0)
+}
+
+func testMovieAfter(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	first := createMovie(t, r, "First", "Drama", "Director", 2000)
+	second := createMovie(t, r, "Second", "Comedy", "Director", 2000)
+	if second < first {
+		first, second = second, first
+	}
+
+	movie, err := r.GetMovieAfter(ctx, first, nil)
+	wantNoErr(t, "GetMovieAfter", err)
+	wantEqual(t, "movie at pivot", movie.ID, first)
+
+	movie, err = r.GetMovieAfter(ctx, first+"0", nil)
+	wantNoErr(t, "GetMovieAfter", err)
+	wantEqual(t, "movie after pivot", movie.ID, second)
+
+	movie, err = r.GetMovieAfter(ctx, second+"0", nil)
+	wantNoErr(t, "GetMovieAfter", err)
+	wantEqual(t, "movie after wrap around", movie.ID, first)
+
+	movie, err = r.GetMovieAfter(ctx, second+"0", &model.MovieFilter{Genre: getMovie(t, r, second).Genre})
+	wantNoErr(t, "GetMovieAfter with filter", err)
+	wantEqual(t, "filtered movie", movie.ID, second)
+
+	_, err = r.GetMovieAfter(ctx, first, &model.MovieFilter{Genre: "Western"})
+	wantErr(t, "GetMovieAfter with no match", err, repository.ErrMovieNotExists)
+}
+
+func testSimilarMovies(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	alien := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	aliens := createMovie(t, r, "Aliens", "Sci-Fi", "James Cameron", 1986)
+	blade := createMovie(t, r, "Blade Runner", "Sci-Fi", "Ridley Scott", 1982)
+	createMovie(t, r, "Amelie", "Romance", "Jean-Pierre Jeunet", 2001)
+
+	similar, err := r.GetSimilarMovies(ctx, alien, 10)
+	wantNoErr(t, "GetSimilarMovies", err)
+
+	ids := make([]string, 0, len(similar))
+	for _, s := range similar {
+		ids = append(ids, s.ID)
+	}
+	wantSlice(t, "similar movies", ids, []string{blade, aliens})
+
+	wantEqual(t, "SameGenre", similar[0].SameGenre, true)
+	wantEqual(t, "SameDirector", similar[0].SameDirector, true)
+	wantEqual(t, "YearDiff", similar[0].YearDiff, 3)
+	wantEqual(t, "SameDirector of sequel", similar[1].SameDirector, false)
+	if similar[1].TitleSimilarity <= repository.SimilarTitleThreshold {
+		t.Fatalf("TitleSimilarity of sequel = %v, want above %v", similar[1].TitleSimilarity, repository.SimilarTitleThreshold)
+	}
+	if similar[0].Score <= similar[1].Score {
+		t.Fatalf("scores %v and %v are not ordered", similar[0].Score, similar[1].Score)
+	}
+
+	similar, err = r.GetSimilarMovies(ctx, alien, 1)
+	wantNoErr(t, "GetSimilarMovies", err)
+	wantEqual(t, "limited similar movies", len(similar), 1)
+}
+
+func testCatalogStats(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	alien := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	// Genres and directors are counted case-insensitively
+	createMovie(t, r, "Blade Runner", "sci-fi", "RIDLEY SCOTT", 1982)
+	createMovie(t, r, "Heat", "Crime", "Michael Mann", 1995)
+
+	_, err := r.AddTags(ctx, alien, []string{"space"})
+	wantNoErr(t, "AddTags", err)
+	_, err = r.RateMovie(ctx, &model.Rating{MovieID: alien, UserID: "user", Score: 8})
+	wantNoErr(t, "RateMovie", err)
+
+	stats, err := r.GetCatalogStats(ctx, &model.CatalogStatsQuery{
+		Facets:     []model.Facet{model.FacetGenre, model.FacetDirector, model.FacetTag, model.FacetYear, model.FacetRating},
+		TopLimit:   10,
+		YearBucket: 10,
+	})
+	wantNoErr(t, "GetCatalogStats", err)
+	wantEqual(t, "TotalMovies", stats.TotalMovies, 3)
+
+	want := [][]model.FacetBucket{
+		{{Value: "Sci-Fi", Count: 2}, {Value: "Crime", Count: 1}},
+		{{Value: "RIDLEY SCOTT", Count: 2}, {Value: "Michael Mann", Count: 1}},
+		{{Value: "space", Count: 1}},
+		{{Value: "1970", From: 1970, To: 1979, Count: 1}, {Value: "1980", From: 1980, To: 1989, Count: 1}, {Value: "1990", From: 1990, To: 1999, Count: 1}},
+		{{Value: "8", From: 8, To: 8, Count: 1}},
+	}
+	if len(stats.Facets) != len(want) {
+		t.Fatalf("GetCatalogStats returned %d facets, want %d", len(stats.Facets), len(want))
+	}
+	for i, facet := range stats.Facets {
+		wantSlice(t, "buckets of "+string(facet.Facet), facet.Buckets, want[i])
+	}
+}
+
+func testCreateMovies(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	ids, err := r.CreateMovies(ctx, nil)
+	wantNoErr(t, "CreateMovies of no movies", err)
+	wantEqual(t, "IDs of no movies", len(ids), 0)
+
+	lastSeq, err := r.GetLastMovieEventSeq(ctx)
+	wantNoErr(t, "GetLastMovieEventSeq", err)
+
+	// More movies than fit into a single batch of inserting repositories
+	const n = 123
+	movies := make([]model.Movie, 0, n)
+	for i := range n {
+		movies = append(movies, model.Movie{
+			Title:    fmt.Sprintf("Movie %03d", n-i),
+			Genre:    "Drama",
+			Director: "Director",
+			Year:     uint32(1900 + i),
+		})
+	}
+
+	ids, err = r.CreateMovies(ctx, movies)
+	wantNoErr(t, "CreateMovies", err)
+	if len(ids) != n {
+		t.Fatalf("CreateMovies returned %d IDs, want %d", len(ids), n)
+	}
+
+	// IDs line up with movies they were given to
+	seen := make(map[string]bool, n)
+	for i, id := range ids {
+		if seen[id] {
+			t.Fatalf("CreateMovies returned ID %s twice", id)
+		}
+		seen[id] = true
+
+		movie := getMovie(t, r, id)
+		wantEqual(t, "Title", movie.Title, movies[i].Title)
+		wantEqual(t, "Year", movie.Year, movies[i].Year)
+	}
+
+	all, err := r.GetMovies(ctx, nil)
+	wantNoErr(t, "GetMovies", err)
+	wantEqual(t, "number of stored movies", len(all), n)
+
+	events, err := r.GetMovieEvents(ctx, lastSeq, n+1)
+	wantNoErr(t, "GetMovieEvents", err)
+	if len(events) != n {
+		t.Fatalf("CreateMovies recorded %d events, want %d", len(events), n)
+	}
+	for i, event := range events {
+		wantEqual(t, "Type", event.Type, model.MovieEventCreated)
+		wantEqual(t, "MovieID", event.MovieID, ids[i])
+	}
+}
+
+func testPartialUpdate(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	id := createMovie(t, r, "Alien", "Horror", "Ridley Scott", 1979)
+	_, err := r.AddTags(ctx, id, []string{"space"})
+	wantNoErr(t, "AddTags", err)
+	_, err = r.RateMovie(ctx, &model.Rating{MovieID: id, UserID: "alice", Score: 9})
+	wantNoErr(t, "RateMovie", err)
+
+	want := model.Movie{ID: id, Title: "Alien", Genre: "Horror", Director: "Ridley Scott", Year: 1979}
+
+	// Zero fields are left as they are
+	updates := []struct {
+		name   string
+		update model.Movie
+		apply  func(m *model.Movie)
+	}{
+		{"title", model.Movie{Title: "Alien: Director's Cut"}, func(m *model.Movie) { m.Title = "Alien: Director's Cut" }},
+		{"genre", model.Movie{Genre: "Sci-Fi"}, func(m *model.Movie) { m.Genre = "Sci-Fi" }},
+		{"director", model.Movie{Director: "R. Scott"}, func(m *model.Movie) { m.Director = "R. Scott" }},
+		{"year", model.Movie{Year: 2003}, func(m *model.Movie) { m.Year = 2003 }},
+		{"several", model.Movie{Title: "Alien", Year: 1979}, func(m *model.Movie) { m.Title, m.Year = "Alien", 1979 }},
+		// Aggregates are maintained by repository and can't be overwritten
+		{"aggregates", model.Movie{Genre: "Horror", RatingSum: 100, RatingCount: 100, ReviewCount: 100}, func(m *model.Movie) { m.Genre = "Horror" }},
+	}
+
+	for _, u := range updates {
+		u.apply(&want)
+
+		updated, err := r.UpdateMovie(ctx, id, &u.update)
+		wantNoErr(t, "UpdateMovie of "+u.name, err)
+
+		for _, movie := range []*model.Movie{updated, getMovie(t, r, id)} {
+			wantEqual(t, u.name+": Title", movie.Title, want.Title)
+			wantEqual(t, u.name+": Genre", movie.Genre, want.Genre)
+			wantEqual(t, u.name+": Director", movie.Director, want.Director)
+			wantEqual(t, u.name+": Year", movie.Year, want.Year)
+			wantEqual(t, u.name+": RatingSum", movie.RatingSum, 9)
+			wantEqual(t, u.name+": RatingCount", movie.RatingCount, 1)
+			wantEqual(t, u.name+": ReviewCount", movie.ReviewCount, 0)
+			wantSlice(t, u.name+": Tags", movie.Tags, []string{"space"})
+		}
+	}
+}
+
+func testMovieOrdering(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	// Movies of the same title are ordered by ID
+	same2001 := createMovie(t, r, "Same", "Drama", "Director", 2001)
+	same2002 := createMovie(t, r, "Same", "Drama", "Director", 2002)
+	sameA, sameB := min(same2001, same2002), max(same2001, same2002)
+	zulu := createMovie(t, r, "Zulu", "War", "Cy Endfield", 1964)
+	alpha := createMovie(t, r, "Alpha", "Drama", "Albert Hughes", 2018)
+	mid := createMovie(t, r, "Mid", "Drama", "Director", 1990)
+	want := []string{alpha, mid, sameA, sameB, zulu}
+
+	movies, err := r.ListMovies(ctx, nil, 10, 0)
+	wantNoErr(t, "ListMovies", err)
+	wantSlice(t, "ListMovies", movieIDs(movies), want)
+
+	// Pages cover all movies without gaps and overlaps
+	var paged []string
+	for offset := uint64(0); ; offset += 2 {
+		page, err := r.ListMovies(ctx, nil, 2, offset)
+		wantNoErr(t, "ListMovies", err)
+		if len(page) == 0 {
+			break
+		}
+		paged = append(paged, movieIDs(page)...)
+	}
+	wantSlice(t, "paged movies", paged, want)
+
+	movies, err = r.ListMovies(ctx, nil, 10, 100)
+	wantNoErr(t, "ListMovies beyond the end", err)
+	wantEqual(t, "movies beyond the end", len(movies), 0)
+
+	var exported []string
+	err = r.ExportMovies(ctx, nil, 3, func(movies []model.Movie) error {
+		exported = append(exported, movieIDs(movies)...)
+		return nil
+	})
+	wantNoErr(t, "ExportMovies", err)
+	wantSlice(t, "exported movies", exported, want)
+
+	// Filmography is ordered by year, then by title
+	movies, err = r.GetFilmography(ctx, "Director")
+	wantNoErr(t, "GetFilmography", err)
+	wantSlice(t, "filmography", movieIDs(movies), []string{mid, same2001, same2002})
+}
@@ -0,0 +1,139 @@
+// Package repotest is a conformance suite of repository.Repository. Every
+// storage backend runs it from its own tests, so all of them keep the same
+// semantics.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"slices"
+	"testing"
+)
+
+// NewRepo returns empty repository. It is called once per test.
+type NewRepo func(t *testing.T) repository.Repository
+
+// Run runs the whole suite against repositories created by newRepo
+func Run(t *testing.T, newRepo NewRepo) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.Repository)
+	}{
+		{"Movies", testMovies},
+		{"CreateMovies", testCreateMovies},
+		{"PartialUpdate", testPartialUpdate},
+		{"MovieNotFound", testMovieNotFound},
+		{"MovieOrdering", testMovieOrdering},
+		{"MovieFilter", testMovieFilter},
+		{"ExportMovies", testExportMovies},
+		{"Filmography", testFilmography},
+		{"MovieAfter", testMovieAfter},
+		{"SimilarMovies", testSimilarMovies},
+		{"CatalogStats", testCatalogStats},
+		{"Ratings", testRatings},
+		{"Reviews", testReviews},
+		{"ReviewModeration", testReviewModeration},
+		{"ReviewOrdering", testReviewOrdering},
+		{"Collections", testCollections},
+		{"CollectionEvents", testCollectionEvents},
+		{"Tags", testTags},
+		{"Watchlist", testWatchlist},
+		{"WatchHistory", testWatchHistory},
+		{"MovieEvents", testMovieEvents},
+		{"Outbox", testOutbox},
+		{"PurgeMovieEvents", testPurgeMovieEvents},
+		{"Webhooks", testWebhooks},
+		{"Transactions", testTransactions},
+		{"NestedTransactions", testNestedTransactions},
+		{"TransactionCancellation", testTransactionCancellation},
+		{"ConcurrentCreates", testConcurrentCreates},
+		{"ConcurrentRatings", testConcurrentRatings},
+		{"ConcurrentTags", testConcurrentTags},
+		{"ConcurrentOutboxClaims", testConcurrentOutboxClaims},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func createMovie(t *testing.T, r repository.Repository, title, genre, director string, year uint32) string {
+	t.Helper()
+
+	id, err := r.CreateMovie(context.Background(), &model.Movie{
+		Title:    title,
+		Genre:    genre,
+		Director: director,
+		Year:     year,
+	})
+	if err != nil {
+		t.Fatalf("CreateMovie(%q) error = %v", title, err)
+	}
+
+	return id
+}
+
+func getMovie(t *testing.T, r repository.Repository, id string) *model.Movie {
+	t.Helper()
+
+	movie, err := r.GetMovie(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetMovie(%q) error = %v", id, err)
+	}
+
+	return movie
+}
+
+func wantErr(t *testing.T, name string, err, target error) {
+	t.Helper()
+
+	if !errors.Is(err, target) {
+		t.Fatalf("%s error = %v, want %v", name, err, target)
+	}
+}
+
+func wantNoErr(t *testing.T, name string, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("%s error = %v", name, err)
+	}
+}
+
+func wantEqual[T comparable](t *testing.T, name string, got, want T) {
+	t.Helper()
+
+	if got != want {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func wantSlice[T comparable](t *testing.T, name string, got, want []T) {
+	t.Helper()
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func movieIDs(movies []model.Movie) []string {
+	ids := make([]string, 0, len(movies))
+	for _, movie := range movies {
+		ids = append(ids, movie.ID)
+	}
+
+	return ids
+}
+
+func titles(movies []model.Movie) []string {
+	titles := make([]string, 0, len(movies))
+	for _, movie := range movies {
+		titles = append(titles, movie.Title)
+	}
+
+	return titles
+}
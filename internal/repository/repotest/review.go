@@ -0,0 +1,188 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"testing"
+	"time"
+)
+
+func testRatings(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	id := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	movie, err := r.RateMovie(ctx, &model.Rating{MovieID: id, UserID: "alice", Score: 8})
+	wantNoErr(t, "RateMovie", err)
+	wantEqual(t, "RatingSum", movie.RatingSum, 8)
+	wantEqual(t, "RatingCount", movie.RatingCount, 1)
+
+	_, err = r.RateMovie(ctx, &model.Rating{MovieID: id, UserID: "bob", Score: 6})
+	wantNoErr(t, "RateMovie", err)
+
+	// Second score of the same user replaces the first one
+	movie, err = r.RateMovie(ctx, &model.Rating{MovieID: id, UserID: "alice", Score: 10})
+	wantNoErr(t, "RateMovie", err)
+	wantEqual(t, "RatingSum after rerating", movie.RatingSum, 16)
+	wantEqual(t, "RatingCount after rerating", movie.RatingCount, 2)
+	wantEqual(t, "AverageRating", getMovie(t, r, id).AverageRating(), 8)
+}
+
+func testReviews(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	id := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	review, err := r.CreateReview(ctx, &model.Review{MovieID: id, UserID: "alice", Text: "Scary"})
+	wantNoErr(t, "CreateReview", err)
+	wantEqual(t, "Status", review.Status, model.ReviewStatusPending)
+	wantEqual(t, "Text", review.Text, "Scary")
+	if review.ID == "" || review.CreatedAt.IsZero() {
+		t.Fatalf("CreateReview returned review without ID or creation time: %+v", review)
+	}
+
+	// Pending reviews are not listed
+	reviews, err := r.ListReviews(ctx, id, 10, 0)
+	wantNoErr(t, "ListReviews", err)
+	wantEqual(t, "pending reviews listed", len(reviews), 0)
+
+	review, err = r.ModerateReview(ctx, review.ID, model.ReviewStatusApproved)
+	wantNoErr(t, "ModerateReview", err)
+	wantEqual(t, "moderated Status", review.Status, model.ReviewStatusApproved)
+	wantEqual(t, "ReviewCount after approval", getMovie(t, r, id).ReviewCount, 1)
+
+	reviews, err = r.ListReviews(ctx, id, 10, 0)
+	wantNoErr(t, "ListReviews", err)
+	wantEqual(t, "approved reviews listed", len(reviews), 1)
+
+	// Review can be changed by its author only and goes back to moderation
+	_, err = r.UpdateReview(ctx, &model.Review{ID: review.ID, UserID: "bob", Text: "Hacked"})
+	wantErr(t, "UpdateReview by another user", err, repository.ErrReviewNotExists)
+
+	review, err = r.UpdateReview(ctx, &model.Review{ID: review.ID, UserID: "alice", Text: "Very scary"})
+	wantNoErr(t, "UpdateReview", err)
+	wantEqual(t, "updated Text", review.Text, "Very scary")
+	wantEqual(t, "updated Status", review.Status, model.ReviewStatusPending)
+	wantEqual(t, "ReviewCount after update", getMovie(t, r, id).ReviewCount, 0)
+
+	_, err = r.ModerateReview(ctx, review.ID, model.ReviewStatusApproved)
+	wantNoErr(t, "ModerateReview", err)
+
+	// Review can be deleted by its author only
+	deleted, err := r.DeleteReview(ctx, review.ID, "bob")
+	wantErr(t, "DeleteReview by another user", err, repository.ErrReviewNotOwned)
+	wantEqual(t, "deleted by another user", deleted, false)
+	wantEqual(t, "ReviewCount after deletion by another user", getMovie(t, r, id).ReviewCount, 1)
+
+	deleted, err = r.DeleteReview(ctx, review.ID, "alice")
+	wantNoErr(t, "DeleteReview", err)
+	wantEqual(t, "deleted", deleted, true)
+	wantEqual(t, "ReviewCount after deletion", getMovie(t, r, id).ReviewCount, 0)
+
+	deleted, err = r.DeleteReview(ctx, review.ID, "alice")
+	wantNoErr(t, "DeleteReview of deleted review", err)
+	wantEqual(t, "deleted again", deleted, false)
+
+	_, err = r.ModerateReview(ctx, review.ID, model.ReviewStatusRejected)
+	wantErr(t, "ModerateReview of deleted review", err, repository.ErrReviewNotExists)
+}
+
+func testReviewModeration(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	id := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	other, err := r.CreateReview(ctx, &model.Review{MovieID: id, UserID: "bob", Text: "Classic"})
+	wantNoErr(t, "CreateReview", err)
+	_, err = r.ModerateReview(ctx, other.ID, model.ReviewStatusApproved)
+	wantNoErr(t, "ModerateReview", err)
+
+	review, err := r.CreateReview(ctx, &model.Review{MovieID: id, UserID: "alice", Text: "Scary"})
+	wantNoErr(t, "CreateReview", err)
+
+	// Only approved reviews are counted and listed, whatever status they came from
+	transitions := []struct {
+		status    model.ReviewStatus
+		wantCount uint32
+	}{
+		{model.ReviewStatusApproved, 2},
+		{model.ReviewStatusApproved, 2},
+		{model.ReviewStatusRejected, 1},
+		{model.ReviewStatusRejected, 1},
+		{model.ReviewStatusApproved, 2},
+		{model.ReviewStatusPending, 1},
+		{model.ReviewStatusRejected, 1},
+		{model.ReviewStatusPending, 1},
+	}
+	prev := model.ReviewStatusPending
+	prevCount := uint32(1)
+	for _, tr := range transitions {
+		seq, err := r.GetLastMovieEventSeq(ctx)
+		wantNoErr(t, "GetLastMovieEventSeq", err)
+
+		moderated, err := r.ModerateReview(ctx, review.ID, tr.status)
+		wantNoErr(t, "ModerateReview", err)
+		wantEqual(t, "Status", moderated.Status, tr.status)
+
+		name := fmt.Sprintf("ReviewCount after %s -> %s", prev, tr.status)
+		wantEqual(t, name, getMovie(t, r, id).ReviewCount, tr.wantCount)
+
+		// Movie is updated only if the number of approved reviews changes
+		events, err := r.GetMovieEvents(ctx, seq, 10)
+		wantNoErr(t, "GetMovieEvents", err)
+		if tr.wantCount == prevCount {
+			wantEqual(t, "events after "+name, len(events), 0)
+		} else {
+			if len(events) != 1 || events[0].Type != model.MovieEventUpdated {
+				t.Fatalf("events after %s = %+v, want single updated event", name, events)
+			}
+			wantEqual(t, "event "+name, events[0].Movie.ReviewCount, tr.wantCount)
+		}
+
+		reviews, err := r.ListReviews(ctx, id, 10, 0)
+		wantNoErr(t, "ListReviews", err)
+		wantEqual(t, "listed reviews", uint32(len(reviews)), tr.wantCount)
+
+		prev = tr.status
+		prevCount = tr.wantCount
+	}
+
+	// Deleting review which is not approved leaves the count alone
+	deleted, err := r.DeleteReview(ctx, review.ID, "alice")
+	wantNoErr(t, "DeleteReview", err)
+	wantEqual(t, "deleted", deleted, true)
+	wantEqual(t, "ReviewCount after deletion of pending review", getMovie(t, r, id).ReviewCount, 1)
+}
+
+func testReviewOrdering(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	id := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	var ids []string
+	for _, user := range []string{"alice", "bob", "carol"} {
+		review, err := r.CreateReview(ctx, &model.Review{MovieID: id, UserID: user, Text: "Review of " + user})
+		wantNoErr(t, "CreateReview", err)
+		_, err = r.ModerateReview(ctx, review.ID, model.ReviewStatusApproved)
+		wantNoErr(t, "ModerateReview", err)
+
+		ids = append([]string{review.ID}, ids...)
+		// Reviews written within the same clock tick would be ordered by ID
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	reviews, err := r.ListReviews(ctx, id, 10, 0)
+	wantNoErr(t, "ListReviews", err)
+	got := make([]string, 0, len(reviews))
+	for _, review := range reviews {
+		got = append(got, review.ID)
+	}
+	wantSlice(t, "reviews newest first", got, ids)
+
+	reviews, err = r.ListReviews(ctx, id, 1, 2)
+	wantNoErr(t, "ListReviews", err)
+	if len(reviews) != 1 || reviews[0].ID != ids[2] {
+		t.Fatalf("ListReviews = %+v, want the oldest review only", reviews)
+	}
+}
@@ -0,0 +1,135 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"testing"
+)
+
+var errAbort = errors.New("unit of work is aborted")
+
+func testTransactions(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	lastSeq, err := r.GetLastMovieEventSeq(ctx)
+	wantNoErr(t, "GetLastMovieEventSeq", err)
+
+	// Changes of committed transaction are kept
+	var id string
+	err = r.InTx(ctx, func(tx repository.Repository) error {
+		var err error
+		if id, err = tx.CreateMovie(ctx, &model.Movie{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979}); err != nil {
+			return err
+		}
+
+		// Transaction sees its own changes
+		if _, err := tx.AddTags(ctx, id, []string{"space"}); err != nil {
+			return err
+		}
+		movie, err := tx.GetMovie(ctx, id)
+		if err != nil {
+			return err
+		}
+		wantSlice(t, "Tags within transaction", movie.Tags, []string{"space"})
+
+		return nil
+	})
+	wantNoErr(t, "InTx", err)
+	wantSlice(t, "Tags", getMovie(t, r, id).Tags, []string{"space"})
+
+	events, err := r.GetMovieEvents(ctx, lastSeq, 10)
+	wantNoErr(t, "GetMovieEvents", err)
+	wantEqual(t, "number of events of committed transaction", len(events), 2)
+
+	// Nothing is left of failed transaction, its error is returned as is
+	err = r.InTx(ctx, func(tx repository.Repository) error {
+		if _, err := tx.CreateMovie(ctx, &model.Movie{Title: "Aliens", Genre: "Sci-Fi", Director: "James Cameron", Year: 1986}); err != nil {
+			return err
+		}
+		if _, err := tx.DeleteMovie(ctx, id); err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+	wantErr(t, "InTx", err, errAbort)
+
+	movies, err := r.GetMovies(ctx, nil)
+	wantNoErr(t, "GetMovies", err)
+	wantSlice(t, "movies after rollback", movieIDs(movies), []string{id})
+
+	events, err = r.GetMovieEvents(ctx, lastSeq, 10)
+	wantNoErr(t, "GetMovieEvents", err)
+	wantEqual(t, "number of events after rollback", len(events), 2)
+}
+
+func testNestedTransactions(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	var first, dropped, last string
+	err := r.InTx(ctx, func(tx repository.Repository) error {
+		var err error
+		if first, err = tx.CreateMovie(ctx, &model.Movie{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979}); err != nil {
+			return err
+		}
+
+		// Failure of nested unit of work rolls back its own changes only
+		err = tx.InTx(ctx, func(nested repository.Repository) error {
+			var err error
+			if dropped, err = nested.CreateMovie(ctx, &model.Movie{Title: "Aliens", Genre: "Sci-Fi", Director: "James Cameron", Year: 1986}); err != nil {
+				return err
+			}
+
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Errorf("nested InTx error = %v, want %v", err, errAbort)
+		}
+
+		// Failed call of repository doesn't break the transaction
+		_, err = tx.RateMovie(ctx, &model.Rating{MovieID: dropped, UserID: "alice", Score: 5})
+		if !errors.Is(err, repository.ErrMovieNotExists) {
+			t.Errorf("RateMovie of rolled back movie error = %v, want %v", err, repository.ErrMovieNotExists)
+		}
+
+		return tx.InTx(ctx, func(nested repository.Repository) error {
+			var err error
+			last, err = nested.CreateMovie(ctx, &model.Movie{Title: "Alien 3", Genre: "Sci-Fi", Director: "David Fincher", Year: 1992})
+			return err
+		})
+	})
+	wantNoErr(t, "InTx", err)
+
+	movies, err := r.ListMovies(ctx, nil, 10, 0)
+	wantNoErr(t, "ListMovies", err)
+	wantSlice(t, "movies", movieIDs(movies), []string{first, last})
+}
+
+func testTransactionCancellation(t *testing.T, r repository.Repository) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Transaction is rolled back if context is cancelled before commit
+	err := r.InTx(ctx, func(tx repository.Repository) error {
+		if _, err := tx.CreateMovie(ctx, &model.Movie{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979}); err != nil {
+			return err
+		}
+		cancel()
+
+		return nil
+	})
+	wantErr(t, "InTx with cancelled context", err, context.Canceled)
+
+	movies, err := r.GetMovies(context.Background(), nil)
+	wantNoErr(t, "GetMovies", err)
+	wantEqual(t, "number of movies", len(movies), 0)
+
+	called := false
+	err = r.InTx(ctx, func(tx repository.Repository) error {
+		called = true
+		return nil
+	})
+	wantErr(t, "InTx with cancelled context", err, context.Canceled)
+	wantEqual(t, "fn called", called, false)
+}
@@ -0,0 +1,96 @@
+package repotest
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"testing"
+	"time"
+)
+
+func testWatchlist(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	alien := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	heat := createMovie(t, r, "Heat", "Crime", "Michael Mann", 1995)
+
+	added, err := r.AddToWatchlist(ctx, "alice", alien)
+	wantNoErr(t, "AddToWatchlist", err)
+	wantEqual(t, "added", added, true)
+
+	added, err = r.AddToWatchlist(ctx, "alice", alien)
+	wantNoErr(t, "AddToWatchlist", err)
+	wantEqual(t, "added again", added, false)
+
+	// Entries added within the same clock tick would be ordered by movie ID
+	time.Sleep(10 * time.Millisecond)
+	_, err = r.AddToWatchlist(ctx, "alice", heat)
+	wantNoErr(t, "AddToWatchlist", err)
+
+	entries, err := r.ListWatchlist(ctx, "alice", 10, 0)
+	wantNoErr(t, "ListWatchlist", err)
+	if len(entries) != 2 || entries[0].MovieID != heat || entries[1].MovieID != alien {
+		t.Fatalf("ListWatchlist = %+v, want heat then alien", entries)
+	}
+	if entries[0].Movie == nil || entries[0].Movie.Title != "Heat" {
+		t.Fatalf("ListWatchlist returned movie %+v, want Heat", entries[0].Movie)
+	}
+
+	entries, err = r.ListWatchlist(ctx, "bob", 10, 0)
+	wantNoErr(t, "ListWatchlist of another user", err)
+	wantEqual(t, "entries of another user", len(entries), 0)
+
+	// Deleted movie stays in the watchlist without details
+	_, err = r.DeleteMovie(ctx, heat)
+	wantNoErr(t, "DeleteMovie", err)
+
+	entries, err = r.ListWatchlist(ctx, "alice", 10, 0)
+	wantNoErr(t, "ListWatchlist", err)
+	if len(entries) != 2 || entries[0].MovieID != heat || entries[0].Movie != nil {
+		t.Fatalf("ListWatchlist = %+v, want deleted movie without details first", entries)
+	}
+
+	removed, err := r.RemoveFromWatchlist(ctx, "alice", heat)
+	wantNoErr(t, "RemoveFromWatchlist", err)
+	wantEqual(t, "removed", removed, true)
+
+	removed, err = r.RemoveFromWatchlist(ctx, "alice", heat)
+	wantNoErr(t, "RemoveFromWatchlist", err)
+	wantEqual(t, "removed again", removed, false)
+}
+
+func testWatchHistory(t *testing.T, r repository.Repository) {
+	ctx := context.Background()
+
+	alien := createMovie(t, r, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	watchedAt := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+
+	entry, err := r.RecordWatch(ctx, &model.WatchHistoryEntry{UserID: "alice", MovieID: alien, WatchedAt: watchedAt, Progress: 40})
+	wantNoErr(t, "RecordWatch", err)
+	wantEqual(t, "Progress", entry.Progress, 40)
+	if !entry.WatchedAt.Equal(watchedAt) {
+		t.Fatalf("WatchedAt = %v, want %v", entry.WatchedAt, watchedAt)
+	}
+	if entry.Movie == nil || entry.Movie.ID != alien {
+		t.Fatalf("RecordWatch returned movie %+v, want Alien", entry.Movie)
+	}
+
+	// Zero time means now
+	entry, err = r.RecordWatch(ctx, &model.WatchHistoryEntry{UserID: "alice", MovieID: alien, Progress: 100})
+	wantNoErr(t, "RecordWatch", err)
+	if time.Since(entry.WatchedAt) > time.Minute {
+		t.Fatalf("WatchedAt = %v, want current time", entry.WatchedAt)
+	}
+
+	entries, err := r.ListWatchHistory(ctx, "alice", 10, 0)
+	wantNoErr(t, "ListWatchHistory", err)
+	if len(entries) != 2 || entries[0].Progress != 100 || entries[1].Progress != 40 {
+		t.Fatalf("ListWatchHistory = %+v, want most recent first", entries)
+	}
+
+	entries, err = r.ListWatchHistory(ctx, "alice", 1, 1)
+	wantNoErr(t, "ListWatchHistory", err)
+	if len(entries) != 1 || !entries[0].WatchedAt.Equal(watchedAt) {
+		t.Fatalf("ListWatchHistory = %+v, want the earliest entry", entries)
+	}
+}
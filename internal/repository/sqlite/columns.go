@@ -0,0 +1,37 @@
+package sqliterepo
+
+import "fmt"
+
+// movieColumns lists columns of movies table along with values which replace
+// NULLs when movie is missing in outer join. Missing movie_id is replaced with
+// the ID from joined table.
+var movieColumns = []struct {
+	name string
+	zero string
+}{
+	{name: "movie_id"},
+	{name: "title", zero: "''"},
+	{name: "genre", zero: "''"},
+	{name: "director", zero: "''"},
+	{name: "year", zero: "0"},
+	{name: "rating_sum", zero: "0"},
+	{name: "rating_count", zero: "0"},
+	{name: "review_count", zero: "0"},
+}
+
+// outerMovieColumns returns columns of movies table (referenced by alias) for
+// scanning into nested "movie" struct. Additional movie_deleted column tells
+// whether movie was not found.
+func outerMovieColumns(alias, joinedID string) []string {
+	cols := make([]string, 0, len(movieColumns)+1)
+	for _, col := range movieColumns {
+		zero := col.zero
+		if col.name == "movie_id" {
+			zero = joinedID
+		}
+
+		cols = append(cols, fmt.Sprintf(`COALESCE(%s.%s, %s) AS "movie.%s"`, alias, col.name, zero, col.name))
+	}
+
+	return append(cols, fmt.Sprintf("%s.movie_id IS NULL AS movie_deleted", alias))
+}
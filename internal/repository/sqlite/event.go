@@ -0,0 +1,218 @@
+package sqliterepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// movieEventRow is a movie event with movie snapshot encoded as JSON
+type movieEventRow struct {
+	model.MovieEvent
+	Payload []byte `db:"movie"`
+}
+
+func (row *movieEventRow) decode() (model.MovieEvent, error) {
+	event := row.MovieEvent
+	if err := json.Unmarshal(row.Payload, &event.Movie); err != nil {
+		return model.MovieEvent{}, fmt.Errorf("failed to decode movie of event %d: %w", row.Seq, err)
+	}
+
+	return event, nil
+}
+
+// GetMovieEvents returns events which happened after the one with afterSeq
+// sequence number, oldest first. ErrEventsPurged is returned if some of
+// these events are already purged.
+func (r *Repository) GetMovieEvents(ctx context.Context, afterSeq uint64, limit uint64) ([]model.MovieEvent, error) {
+	const op = "repository.sqlite.GetMovieEvents"
+
+	query, args, err := r.builder.Select("*").
+		From("movie_events").
+		Where(sq.Gt{"seq": afterSeq}).
+		OrderBy("seq").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []movieEventRow
+	err = r.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get movie events: %w", op, err)
+	}
+
+	events := make([]model.MovieEvent, 0, len(rows))
+	for _, row := range rows {
+		event, err := row.decode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, event)
+	}
+
+	var purgedSeq uint64
+	if err := r.db.GetContext(ctx, &purgedSeq, "SELECT purged_seq FROM movie_events_purged"); err != nil {
+		return nil, fmt.Errorf("%s: failed to get purged movie events: %w", op, err)
+	}
+	if afterSeq < purgedSeq {
+		return nil, fmt.Errorf("%s: events up to %d: %w", op, purgedSeq, repo.ErrEventsPurged)
+	}
+
+	return events, nil
+}
+
+// PurgeMovieEvents deletes events which occurred before occurredBefore and
+// returns their number. Events are purged from the oldest one up to the
+// first event still waiting in the outbox or for webhook delivery, and the
+// latest event is always kept, so purged events are never needed again.
+// Delivered outbox entries and webhook deliveries go along with events.
+func (r *Repository) PurgeMovieEvents(ctx context.Context, occurredBefore time.Time) (uint64, error) {
+	const op = "repository.sqlite.PurgeMovieEvents"
+
+	const horizonQuery = `SELECT max(seq) FROM movie_events
+		WHERE occurred_at < ?
+			AND seq < (SELECT max(seq) FROM movie_events)
+			AND seq < coalesce((SELECT min(event_seq) FROM outbox WHERE delivered_at IS NULL), 9223372036854775807)
+			AND seq < coalesce((SELECT min(event_seq) FROM webhook_deliveries WHERE status = 'pending'), 9223372036854775807)`
+
+	var num int64
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		var horizon *uint64
+		if err := tx.GetContext(ctx, &horizon, horizonQuery, occurredBefore.UTC()); err != nil {
+			return fmt.Errorf("%s: failed to find purged movie events: %w", op, err)
+		}
+		if horizon == nil {
+			return nil
+		}
+
+		res, err := tx.ExecContext(ctx, "DELETE FROM movie_events WHERE seq <= ?", *horizon)
+		if err != nil {
+			return fmt.Errorf("%s: failed to purge movie events: %w", op, err)
+		}
+		if num, err = res.RowsAffected(); err != nil {
+			return fmt.Errorf("%s: failed to get number of purged events: %w", op, err)
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE movie_events_purged SET purged_seq = max(purged_seq, ?)", *horizon)
+		if err != nil {
+			return fmt.Errorf("%s: failed to save purged movie events: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(num), nil
+}
+
+// GetLastMovieEventSeq returns sequence number of the latest movie event
+// or 0 if there are no events yet
+func (r *Repository) GetLastMovieEventSeq(ctx context.Context) (uint64, error) {
+	const op = "repository.sqlite.GetLastMovieEventSeq"
+
+	query, args, err := r.builder.Select("COALESCE(max(seq), 0)").
+		From("movie_events").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var seq uint64
+	if err := r.db.GetContext(ctx, &seq, query, args...); err != nil {
+		return 0, fmt.Errorf("%s: failed to get last movie event: %w", op, err)
+	}
+
+	return seq, nil
+}
+
+// recordMovieEvent saves event about the movie change within the transaction
+// of the change, puts it to the outbox unless disabled by WithoutOutbox and
+// schedules webhook deliveries. Caller wakes up subscribers once transaction is committed.
+//
+// Transactions changing data never interleave, so events become visible
+// strictly in order of their sequence numbers without extra locking.
+func (r *Repository) recordMovieEvent(ctx context.Context, tx *sqlx.Tx, eventType model.MovieEventType, movie *model.Movie) error {
+	payload, err := json.Marshal(movie)
+	if err != nil {
+		return fmt.Errorf("failed to encode movie: %w", err)
+	}
+
+	occurredAt := now()
+	query, args, err := r.builder.Insert("movie_events").
+		Columns("event_type", "movie_id", "movie", "occurred_at").
+		Values(eventType, movie.ID, string(payload), occurredAt).
+		Suffix("RETURNING seq").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var seq uint64
+	if err := tx.GetContext(ctx, &seq, query, args...); err != nil {
+		return fmt.Errorf("failed to save movie event: %w", err)
+	}
+
+	if !r.skipOutbox {
+		query, args, err := r.builder.Insert("outbox").
+			Columns("event_seq", "next_attempt_at").
+			Values(seq, occurredAt).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to form sql query: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to add movie event to outbox: %w", err)
+		}
+	}
+
+	// Schedule delivery to every webhook interested in the event
+	query, args, err = r.builder.Select("webhook_id").
+		From("webhooks").
+		Where(sq.Or{
+			sq.Expr("json_array_length(event_types) = 0"),
+			sq.Expr("EXISTS (SELECT 1 FROM json_each(webhooks.event_types) WHERE value = ?)", string(eventType)),
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var webhookIDs []string
+	if err := tx.SelectContext(ctx, &webhookIDs, query, args...); err != nil {
+		return fmt.Errorf("failed to get webhooks interested in event: %w", err)
+	}
+
+	if len(webhookIDs) == 0 {
+		return nil
+	}
+
+	builder := r.builder.Insert("webhook_deliveries").
+		Columns("delivery_id", "webhook_id", "event_seq", "created_at", "next_attempt_at")
+	for _, webhookID := range webhookIDs {
+		builder = builder.Values(uuid.NewString(), webhookID, seq, occurredAt, occurredAt)
+	}
+
+	query, args, err = builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to schedule webhook deliveries: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ExportMovies passes all movies matching the filter to fn in batches of up to
+// batch movies, ordered as in ListMovies. Batches are read page by page after
+// the last exported movie within a single read transaction, so export is
+// consistent and only one batch is held in memory at a time.
+func (r *Repository) ExportMovies(ctx context.Context, filter *model.MovieFilter, batch uint64, fn func([]model.Movie) error) (err error) {
+	const op = "repository.sqlite.ExportMovies"
+
+	// Read-only transaction doesn't take the write lock, so writers
+	// are not blocked for the duration of export
+	q := r.db
+	if r.tx == nil {
+		tx, err := r.pool.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+		}
+
+		defer func() {
+			if errRb := tx.Rollback(); errRb != nil {
+				err = errors.Join(err, fmt.Errorf("%s: failed to rollback transaction: %w", op, errRb))
+			}
+		}()
+		q = tx
+	}
+
+	movies := make([]model.Movie, 0, batch)
+	for {
+		builder := applyMovieFilter(r.builder.Select("*").From("movies"), filter)
+		if len(movies) > 0 {
+			last := movies[len(movies)-1]
+			builder = builder.Where(sq.Expr("(movies.title, movies.movie_id) > (?, ?)", last.Title, last.ID))
+		}
+
+		query, args, err := builder.
+			OrderBy("title", "movie_id").
+			Limit(batch).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		movies = movies[:0]
+		if err := q.SelectContext(ctx, &movies, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to fetch movies: %w", op, err)
+		}
+
+		if len(movies) == 0 {
+			return nil
+		}
+
+		if err := r.loadRelations(ctx, q, moviePtrs(movies)...); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := fn(movies); err != nil {
+			return err
+		}
+
+		if uint64(len(movies)) < batch {
+			return nil
+		}
+	}
+}
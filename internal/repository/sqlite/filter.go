@@ -0,0 +1,54 @@
+package sqliterepo
+
+import (
+	"movie-service/internal/model"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// applyMovieFilter adds conditions of the filter to the query selecting
+// from movies table
+func applyMovieFilter(builder sq.SelectBuilder, filter *model.MovieFilter) sq.SelectBuilder {
+	if filter == nil {
+		return builder
+	}
+
+	if len(filter.TagsAll) > 0 {
+		builder = builder.Where(sq.Expr(
+			"movies.movie_id IN (?)",
+			sq.Select("movie_id").
+				From("movie_tags").
+				Where(sq.Eq{"tag": filter.TagsAll}).
+				GroupBy("movie_id").
+				Having("count(*) = ?", len(filter.TagsAll)),
+		))
+	}
+
+	if len(filter.TagsAny) > 0 {
+		builder = builder.Where(sq.Expr(
+			"EXISTS (?)",
+			sq.Select("1").
+				From("movie_tags").
+				Where("movie_tags.movie_id = movies.movie_id").
+				Where(sq.Eq{"tag": filter.TagsAny}),
+		))
+	}
+
+	if filter.Genre != "" {
+		builder = builder.Where(sq.Expr("unicode_lower(movies.genre) = unicode_lower(?)", filter.Genre))
+	}
+
+	if filter.Director != "" {
+		builder = builder.Where(sq.Expr("unicode_lower(movies.director) = unicode_lower(?)", filter.Director))
+	}
+
+	if filter.YearFrom != 0 {
+		builder = builder.Where(sq.GtOrEq{"movies.year": filter.YearFrom})
+	}
+
+	if filter.YearTo != 0 {
+		builder = builder.Where(sq.LtOrEq{"movies.year": filter.YearTo})
+	}
+
+	return builder
+}
@@ -0,0 +1,57 @@
+package sqliterepo
+
+import (
+	"database/sql/driver"
+	"fmt"
+	repo "movie-service/internal/repository"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// Functions which postgres has built in or gets from extensions are defined
+// in Go. They are registered for every connection opened afterwards.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("normalize_name", 1, textFunction(repo.NormalizeName))
+	// Built-in lower of sqlite folds ASCII letters only
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1, textFunction(strings.ToLower))
+	sqlite.MustRegisterDeterministicScalarFunction("similarity", 2,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			a, b := textArg(args[0]), textArg(args[1])
+			if a == nil || b == nil {
+				return nil, nil
+			}
+
+			return repo.TrigramSimilarity(*a, *b), nil
+		},
+	)
+}
+
+// textFunction turns fn into sqlite function of single text argument.
+// NULL argument gives NULL as in built-in functions.
+func textFunction(fn func(string) string) func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+	return func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		s := textArg(args[0])
+		if s == nil {
+			return nil, nil
+		}
+
+		return fn(*s), nil
+	}
+}
+
+// textArg converts argument of sqlite function to string, returning nil for NULL
+func textArg(arg driver.Value) *string {
+	switch v := arg.(type) {
+	case nil:
+		return nil
+	case string:
+		return &v
+	case []byte:
+		s := string(v)
+		return &s
+	default:
+		s := fmt.Sprint(v)
+		return &s
+	}
+}
@@ -0,0 +1,146 @@
+package sqliterepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// ClaimOutboxEvents takes up to limit undelivered events which are due, oldest first.
+// Claimed events are hidden from other relays for lease, so if the relay dies
+// before reporting the result, events are delivered again after lease expires.
+func (r *Repository) ClaimOutboxEvents(ctx context.Context, limit uint64, lease time.Duration) ([]model.OutboxEvent, error) {
+	const op = "repository.sqlite.ClaimOutboxEvents"
+
+	claimedAt := now()
+	due := sq.Select("event_seq").
+		From("outbox").
+		Where(sq.Eq{"delivered_at": nil}).
+		Where(sq.LtOrEq{"next_attempt_at": claimedAt}).
+		OrderBy("event_seq").
+		Limit(limit)
+
+	claim, claimArgs, err := r.builder.Update("outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", claimedAt.Add(lease)).
+		Where(sq.Expr("event_seq IN (?)", due)).
+		Suffix("RETURNING event_seq").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []struct {
+		movieEventRow
+		Attempts uint32 `db:"attempts"`
+	}
+	err = r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		var seqs []uint64
+		if err := tx.SelectContext(ctx, &seqs, claim, claimArgs...); err != nil {
+			return fmt.Errorf("%s: failed to claim outbox events: %w", op, err)
+		}
+
+		if len(seqs) == 0 {
+			return nil
+		}
+
+		query, args, err := r.builder.Select("e.*", "o.attempts").
+			From("outbox o").
+			Join("movie_events e ON e.seq = o.event_seq").
+			Where(sq.Eq{"o.event_seq": seqs}).
+			OrderBy("e.seq").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.SelectContext(ctx, &rows, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to get claimed outbox events: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]model.OutboxEvent, 0, len(rows))
+	for _, row := range rows {
+		event, err := row.decode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, model.OutboxEvent{MovieEvent: event, Attempts: row.Attempts})
+	}
+
+	return events, nil
+}
+
+// MarkOutboxDelivered marks event as delivered, so it is never claimed again
+func (r *Repository) MarkOutboxDelivered(ctx context.Context, seq uint64) error {
+	const op = "repository.sqlite.MarkOutboxDelivered"
+
+	query, args, err := r.builder.Update("outbox").
+		Set("delivered_at", now()).
+		Set("last_error", "").
+		Where(sq.Eq{"event_seq": seq}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to mark outbox event delivered: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkOutboxFailed schedules next delivery attempt of the event
+func (r *Repository) MarkOutboxFailed(ctx context.Context, seq uint64, retryAt time.Time, reason string) error {
+	const op = "repository.sqlite.MarkOutboxFailed"
+
+	query, args, err := r.builder.Update("outbox").
+		Set("next_attempt_at", retryAt.UTC()).
+		Set("last_error", reason).
+		Where(sq.Eq{"event_seq": seq}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to reschedule outbox event: %w", op, err)
+	}
+
+	return nil
+}
+
+// PurgeOutbox deletes events delivered before deliveredBefore and returns
+// their number. Events themselves are kept in movie_events.
+func (r *Repository) PurgeOutbox(ctx context.Context, deliveredBefore time.Time) (uint64, error) {
+	const op = "repository.sqlite.PurgeOutbox"
+
+	query, args, err := r.builder.Delete("outbox").
+		Where(sq.Lt{"delivered_at": deliveredBefore.UTC()}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to purge outbox: %w", op, err)
+	}
+
+	num, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to get number of purged events: %w", op, err)
+	}
+
+	return uint64(num), nil
+}
@@ -0,0 +1,48 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// GetMovieAfter returns movie matching the filter with the smallest ID which is
+// not less than pivot, wrapping around to the smallest ID overall. As movie IDs
+// are random UUIDs, random pivot gives uniformly sampled movie using primary key
+// index instead of sorting the whole table.
+func (r *Repository) GetMovieAfter(ctx context.Context, pivot string, filter *model.MovieFilter) (*model.Movie, error) {
+	const op = "repository.sqlite.GetMovieAfter"
+
+	for _, cond := range []sq.Sqlizer{sq.GtOrEq{"movies.movie_id": pivot}, sq.Expr("TRUE")} {
+		query, args, err := applyMovieFilter(r.builder.Select("*").From("movies"), filter).
+			Where(cond).
+			OrderBy("movie_id").
+			Limit(1).
+			ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		var movie model.Movie
+		err = r.db.GetContext(ctx, &movie, query, args...)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to get movie: %w", op, err)
+		}
+
+		if err := r.loadRelations(ctx, r.db, &movie); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return &movie, nil
+	}
+
+	return nil, fmt.Errorf("%s: no movie matches filter: %w", op, repo.ErrMovieNotExists)
+}
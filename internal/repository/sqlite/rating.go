@@ -0,0 +1,102 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// RateMovie saves user's score for the movie. If user has already rated the movie,
+// previous score is replaced. Aggregated rating of the movie is adjusted
+// incrementally within the same transaction.
+func (r *Repository) RateMovie(ctx context.Context, rating *model.Rating) (*model.Movie, error) {
+	const op = "repository.sqlite.RateMovie"
+
+	var movie model.Movie
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		if err := r.checkMovie(ctx, tx, rating.MovieID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Find out previous score of the user
+		query, args, err := r.builder.Select("score").
+			From("ratings").
+			Where(sq.Eq{"movie_id": rating.MovieID, "user_id": rating.UserID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		var prevScore uint32
+		rated := true
+		if err := tx.GetContext(ctx, &prevScore, query, args...); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%s: failed to get previous rating: %w", op, err)
+			}
+			rated = false
+		}
+
+		// Save the score
+		sumDelta, countDelta := int64(rating.Score), 0
+		if rated {
+			sumDelta -= int64(prevScore)
+
+			query, args, err = r.builder.Update("ratings").
+				Set("score", rating.Score).
+				Set("updated_at", now()).
+				Where(sq.Eq{"movie_id": rating.MovieID, "user_id": rating.UserID}).
+				ToSql()
+		} else {
+			countDelta = 1
+
+			ratedAt := now()
+			query, args, err = r.builder.Insert("ratings").
+				Columns("movie_id", "user_id", "score", "created_at", "updated_at").
+				Values(rating.MovieID, rating.UserID, rating.Score, ratedAt, ratedAt).
+				ToSql()
+		}
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to save rating: %w", op, err)
+		}
+
+		// Adjust aggregated rating
+		query, args, err = r.builder.Update("movies").
+			Set("rating_sum", sq.Expr("rating_sum + ?", sumDelta)).
+			Set("rating_count", sq.Expr("rating_count + ?", countDelta)).
+			Where(sq.Eq{"movie_id": rating.MovieID}).
+			Suffix("RETURNING *").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &movie, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to update movie rating: %w", op, err)
+		}
+
+		if err := r.loadRelations(ctx, tx, &movie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventUpdated, &movie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.notifier.Broadcast()
+
+	return &movie, nil
+}
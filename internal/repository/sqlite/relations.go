@@ -0,0 +1,27 @@
+package sqliterepo
+
+import (
+	"context"
+	"movie-service/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// loadRelations fills in fields of movies which are stored outside
+// of movies table
+func (r *Repository) loadRelations(ctx context.Context, q sqlx.QueryerContext, movies ...*model.Movie) error {
+	if err := r.loadCollectionIDs(ctx, q, movies...); err != nil {
+		return err
+	}
+
+	return r.loadTags(ctx, q, movies...)
+}
+
+func moviePtrs(movies []model.Movie) []*model.Movie {
+	ptrs := make([]*model.Movie, 0, len(movies))
+	for i := range movies {
+		ptrs = append(ptrs, &movies[i])
+	}
+
+	return ptrs
+}
@@ -0,0 +1,74 @@
+package sqliterepo
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// Weights of similarity features, they sum up to 1. Same as in postgres.
+const (
+	weightSameGenre    = 0.35
+	weightSameDirector = 0.35
+	weightEra          = 0.15
+	weightTitle        = 0.15
+)
+
+// GetSimilarMovies returns movies most similar to the given one by genre,
+// director, release year and title, best matches first
+func (r *Repository) GetSimilarMovies(ctx context.Context, id string, limit uint64) ([]model.SimilarMovie, error) {
+	const op = "repository.sqlite.GetSimilarMovies"
+
+	movie, err := r.getMovie(ctx, r.db, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Candidates share at least one feature with the movie
+	candidates := sq.Select("movies.*").
+		Column(sq.Expr("unicode_lower(movies.genre) = unicode_lower(?) AS same_genre", movie.Genre)).
+		Column(sq.Expr("unicode_lower(movies.director) = unicode_lower(?) AS same_director", movie.Director)).
+		Column(sq.Expr("abs(movies.year - ?) AS year_diff", movie.Year)).
+		Column(sq.Expr("similarity(movies.title, ?) AS title_similarity", movie.Title)).
+		From("movies").
+		Where(sq.NotEq{"movies.movie_id": movie.ID}).
+		Where(sq.Or{
+			sq.Expr("unicode_lower(movies.genre) = unicode_lower(?)", movie.Genre),
+			sq.Expr("unicode_lower(movies.director) = unicode_lower(?)", movie.Director),
+			sq.Expr("movies.year BETWEEN ? AND ?", int64(movie.Year)-repo.SimilarEraYears, movie.Year+repo.SimilarEraYears),
+			sq.Expr("similarity(movies.title, ?) >= ?", movie.Title, repo.SimilarTitleThreshold),
+		})
+
+	query, args, err := r.builder.Select("*").
+		Column(sq.Expr(
+			"? * same_genre + ? * same_director + ? * max(0, 1 - year_diff / ?) + ? * title_similarity AS score",
+			weightSameGenre, weightSameDirector, weightEra, float64(repo.SimilarEraYears), weightTitle,
+		)).
+		FromSelect(candidates, "c").
+		OrderBy("score DESC", "movie_id").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	similar := make([]model.SimilarMovie, 0, limit)
+	err = r.db.SelectContext(ctx, &similar, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get similar movies: %w", op, err)
+	}
+
+	movies := make([]*model.Movie, 0, len(similar))
+	for i := range similar {
+		movies = append(movies, &similar[i].Movie)
+	}
+
+	if err := r.loadRelations(ctx, r.db, movies...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return similar, nil
+}
@@ -0,0 +1,374 @@
+// Package sqliterepo keeps data of the service in a single sqlite database
+// file. It mirrors postgres repository, so both behave the same way.
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	defaultCreateBatch = 50
+)
+
+// Repository expects connection opened by pkg/sqlite: every transaction takes
+// the write lock at once, so transactions changing data never interleave
+// and row locks of postgres are not needed.
+type Repository struct {
+	// db is the pool, or the transaction if repository is bound to one
+	db       querier
+	pool     *sqlx.DB
+	tx       *sqlx.Tx
+	depth    int
+	builder  sq.StatementBuilderType
+	notifier *repo.LocalNotifier
+
+	skipOutbox bool
+}
+
+// Option configures Repository
+type Option func(r *Repository)
+
+// WithoutOutbox stops putting movie events to the outbox. It is meant for
+// setups where no relay drains the outbox, which would grow without bound.
+func WithoutOutbox() Option {
+	return func(r *Repository) {
+		r.skipOutbox = true
+	}
+}
+
+func New(db *sqlx.DB, opts ...Option) *Repository {
+	r := &Repository{
+		db:       db,
+		pool:     db,
+		builder:  sq.StatementBuilder,
+		notifier: repo.NewLocalNotifier(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Notifier returns notifier which is woken up by events of the repository
+func (r *Repository) Notifier() *repo.LocalNotifier {
+	return r.notifier
+}
+
+func (r *Repository) GetMovie(ctx context.Context, id string) (*model.Movie, error) {
+	const op = "repository.sqlite.GetMovie"
+
+	movie, err := r.getMovie(ctx, r.db, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return movie, nil
+}
+
+func (r *Repository) getMovie(ctx context.Context, q sqlx.QueryerContext, id string) (*model.Movie, error) {
+	query, args, err := r.builder.Select("*").
+		From("movies").
+		Where(sq.Eq{"movie_id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var movie model.Movie
+	err = sqlx.GetContext(ctx, q, &movie, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get movie info by id: %w", repo.ErrMovieNotExists)
+		}
+
+		return nil, fmt.Errorf("failed to get movie info by id: %w", err)
+	}
+
+	if err := r.loadRelations(ctx, q, &movie); err != nil {
+		return nil, err
+	}
+
+	return &movie, nil
+}
+
+// GetMovies returns all movies matching the filter
+func (r *Repository) GetMovies(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error) {
+	const op = "repository.sqlite.GetMovies"
+
+	query, args, err := applyMovieFilter(r.builder.Select("*").From("movies"), filter).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var movies []model.Movie
+	err = r.db.SelectContext(ctx, &movies, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get info about movies: %w", op, err)
+	}
+
+	if err := r.loadRelations(ctx, r.db, moviePtrs(movies)...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return movies, nil
+}
+
+// ListMovies returns page of movies matching the filter ordered by title
+func (r *Repository) ListMovies(ctx context.Context, filter *model.MovieFilter, limit, offset uint64) ([]model.Movie, error) {
+	const op = "repository.sqlite.ListMovies"
+
+	query, args, err := applyMovieFilter(r.builder.Select("*").From("movies"), filter).
+		OrderBy("title", "movie_id").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	movies := make([]model.Movie, 0, limit)
+	err = r.db.SelectContext(ctx, &movies, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get page of movies: %w", op, err)
+	}
+
+	if err := r.loadRelations(ctx, r.db, moviePtrs(movies)...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return movies, nil
+}
+
+func (r *Repository) CreateMovie(ctx context.Context, movie *model.Movie) (string, error) {
+	const op = "repository.sqlite.CreateMovie"
+
+	var ids []string
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		var err error
+		ids, err = r.createMovies(ctx, tx, []model.Movie{*movie})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	r.notifier.Broadcast()
+
+	return ids[0], nil
+}
+
+// CreateMovies adds all movies in a single transaction. IDs are returned
+// in order of movies.
+func (r *Repository) CreateMovies(ctx context.Context, movies []model.Movie) ([]string, error) {
+	const op = "repository.sqlite.CreateMovies"
+
+	ids := make([]string, 0, len(movies))
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		for start := 0; start < len(movies); start += defaultCreateBatch {
+			end := min(start+defaultCreateBatch, len(movies))
+
+			batchIDs, err := r.createMovies(ctx, tx, movies[start:end])
+			if err != nil {
+				return fmt.Errorf("%s: failed to insert batch of movies: %w", op, err)
+			}
+			ids = append(ids, batchIDs...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.notifier.Broadcast()
+
+	return ids, nil
+}
+
+// createMovies inserts movies with a single statement and records event
+// about every created movie
+func (r *Repository) createMovies(ctx context.Context, tx *sqlx.Tx, movies []model.Movie) ([]string, error) {
+	created := make([]model.Movie, 0, len(movies))
+	builder := r.builder.Insert("movies").Columns("movie_id", "title", "genre", "director", "year")
+	for _, movie := range movies {
+		movie := model.Movie{
+			ID:            uuid.NewString(),
+			Title:         movie.Title,
+			Genre:         movie.Genre,
+			Director:      movie.Director,
+			Year:          movie.Year,
+			CollectionIDs: make([]string, 0),
+			Tags:          make([]string, 0),
+		}
+		builder = builder.Values(movie.ID, movie.Title, movie.Genre, movie.Director, movie.Year)
+		created = append(created, movie)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to add movie info: %w", err)
+	}
+
+	ids := make([]string, 0, len(created))
+	for i := range created {
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventCreated, &created[i]); err != nil {
+			return nil, err
+		}
+		ids = append(ids, created[i].ID)
+	}
+
+	return ids, nil
+}
+
+func (r *Repository) UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
+	const op = "repository.sqlite.UpdateMovie"
+
+	builder := r.builder.Update("movies")
+	if movie.Title != "" {
+		builder = builder.Set("title", movie.Title)
+	}
+	if movie.Genre != "" {
+		builder = builder.Set("genre", movie.Genre)
+	}
+	if movie.Director != "" {
+		builder = builder.Set("director", movie.Director)
+	}
+	if movie.Year != 0 {
+		builder = builder.Set("year", movie.Year)
+	}
+
+	query, args, err := builder.
+		Where(sq.Eq{"movie_id": id}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form query: %w", op, err)
+	}
+
+	var newMovie model.Movie
+	err = r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &newMovie, query, args...)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%s: failed to update movie info: %w", op, repo.ErrMovieNotExists)
+			}
+
+			return fmt.Errorf("%s: failed to update movie info: %w", op, err)
+		}
+
+		if err := r.loadRelations(ctx, tx, &newMovie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventUpdated, &newMovie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.notifier.Broadcast()
+
+	return &newMovie, nil
+}
+
+// Returning bool val indicates whether movie info was deleted or not
+func (r *Repository) DeleteMovie(ctx context.Context, id string) (bool, error) {
+	const op = "repository.sqlite.DeleteMovie"
+
+	var deleted bool
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		// Snapshot of the movie goes to the deletion event, so it is taken before
+		// relations are deleted along with the movie
+		movie, err := r.getMovie(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, repo.ErrMovieNotExists) {
+				return nil
+			}
+
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Movie tags are deleted along with the movie, so usage counts must be decreased
+		if err := r.adjustTagUsage(ctx, tx, movie.Tags, -1); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := r.builder.Delete("movies").
+			Where(sq.Eq{"movie_id": id}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form query: %w", op, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to delete movie info: %w", op, err)
+		}
+		deleted = true
+
+		if err := r.recordMovieEvent(ctx, tx, model.MovieEventDeleted, movie); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if deleted {
+		r.notifier.Broadcast()
+	}
+
+	return deleted, nil
+}
+
+// checkMovie makes sure the movie exists. Unlike postgres there is no need
+// to lock the row, as transaction already holds the write lock.
+func (r *Repository) checkMovie(ctx context.Context, tx *sqlx.Tx, id string) error {
+	query, args, err := r.builder.Select("movie_id").
+		From("movies").
+		Where(sq.Eq{"movie_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var movieID string
+	if err := tx.GetContext(ctx, &movieID, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get movie: %w", repo.ErrMovieNotExists)
+		}
+
+		return fmt.Errorf("failed to get movie: %w", err)
+	}
+
+	return nil
+}
+
+// now returns current time in UTC. Times are stored as text, so they are
+// compared correctly only if all of them are in the same time zone.
+func now() time.Time {
+	return time.Now().UTC()
+}
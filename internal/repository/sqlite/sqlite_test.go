@@ -0,0 +1,60 @@
+package sqliterepo_test
+
+import (
+	"context"
+	"movie-service/internal/config"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"movie-service/internal/repository/repotest"
+	sqliterepo "movie-service/internal/repository/sqlite"
+	"movie-service/pkg/sqlite"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return sqliterepo.New(openDB(t))
+	})
+}
+
+func TestWithoutOutbox(t *testing.T) {
+	ctx := context.Background()
+	r := sqliterepo.New(openDB(t), sqliterepo.WithoutOutbox())
+
+	movie := &model.Movie{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979}
+	if _, err := r.CreateMovie(ctx, movie); err != nil {
+		t.Fatalf("CreateMovie error = %v", err)
+	}
+
+	events, err := r.GetMovieEvents(ctx, 0, 10)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("GetMovieEvents = %+v, %v, want single event", events, err)
+	}
+
+	claimed, err := r.ClaimOutboxEvents(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEvents error = %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Errorf("ClaimOutboxEvents = %+v, want no events in the outbox", claimed)
+	}
+}
+
+func openDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	db, err := sqlite.New(config.SQLite{
+		Path:        filepath.Join(t.TempDir(), "movies.db"),
+		BusyTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
@@ -0,0 +1,215 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// Returning bool val indicates whether movie was added or it had already been
+// in the watchlist
+func (r *Repository) AddToWatchlist(ctx context.Context, userID, movieID string) (bool, error) {
+	const op = "repository.sqlite.AddToWatchlist"
+
+	query, args, err := r.builder.Insert("watchlist").
+		Columns("user_id", "movie_id", "added_at").
+		Select(
+			sq.Select().
+				Column("?", userID).
+				Column("movie_id").
+				Column("?", now()).
+				From("movies").
+				Where(sq.Eq{"movie_id": movieID}),
+		).
+		Suffix("ON CONFLICT (user_id, movie_id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to add movie to watchlist: %w", op, err)
+	}
+
+	num, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get number of inserted rows: %w", op, err)
+	}
+
+	if num > 0 {
+		return true, nil
+	}
+
+	// Nothing was inserted: either movie is already in the list or it doesn't exist
+	if _, err := r.GetMovie(ctx, movieID); err != nil {
+		return false, fmt.Errorf("%s: failed to add movie to watchlist: %w", op, err)
+	}
+
+	return false, nil
+}
+
+// Returning bool val indicates whether movie was removed or it hadn't been
+// in the watchlist
+func (r *Repository) RemoveFromWatchlist(ctx context.Context, userID, movieID string) (bool, error) {
+	const op = "repository.sqlite.RemoveFromWatchlist"
+
+	query, args, err := r.builder.Delete("watchlist").
+		Where(sq.Eq{"user_id": userID, "movie_id": movieID}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to remove movie from watchlist: %w", op, err)
+	}
+
+	num, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get number of deleted rows: %w", op, err)
+	}
+
+	return num > 0, nil
+}
+
+// ListWatchlist returns page of user's watchlist, recently added first
+func (r *Repository) ListWatchlist(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchlistEntry, error) {
+	const op = "repository.sqlite.ListWatchlist"
+
+	query, args, err := r.builder.Select("w.user_id", "w.movie_id", "w.added_at").
+		Columns(outerMovieColumns("m", "w.movie_id")...).
+		From("watchlist w").
+		LeftJoin("movies m ON m.movie_id = w.movie_id").
+		Where(sq.Eq{"w.user_id": userID}).
+		OrderBy("w.added_at DESC", "w.movie_id").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []struct {
+		model.WatchlistEntry
+		MovieDeleted bool `db:"movie_deleted"`
+	}
+	err = r.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get watchlist: %w", op, err)
+	}
+
+	entries := make([]model.WatchlistEntry, 0, len(rows))
+	movies := make([]*model.Movie, 0, len(rows))
+	for _, row := range rows {
+		if row.MovieDeleted {
+			row.Movie = nil
+		} else {
+			movies = append(movies, row.Movie)
+		}
+		entries = append(entries, row.WatchlistEntry)
+	}
+
+	if err := r.loadRelations(ctx, r.db, movies...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
+
+// RecordWatch adds entry to user's watch history. Zero WatchedAt is replaced
+// with current time.
+func (r *Repository) RecordWatch(ctx context.Context, entry *model.WatchHistoryEntry) (*model.WatchHistoryEntry, error) {
+	const op = "repository.sqlite.RecordWatch"
+
+	watchedAt := now()
+	if !entry.WatchedAt.IsZero() {
+		watchedAt = entry.WatchedAt.UTC()
+	}
+
+	query, args, err := r.builder.Insert("watch_history").
+		Columns("entry_id", "user_id", "movie_id", "watched_at", "progress").
+		Select(
+			sq.Select().
+				Column("?", uuid.NewString()).
+				Column("?", entry.UserID).
+				Column("movie_id").
+				Column("?", watchedAt).
+				Column("?", entry.Progress).
+				From("movies").
+				Where(sq.Eq{"movie_id": entry.MovieID}),
+		).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var newEntry model.WatchHistoryEntry
+	err = r.db.GetContext(ctx, &newEntry, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: failed to add watch history entry: %w", op, repo.ErrMovieNotExists)
+		}
+
+		return nil, fmt.Errorf("%s: failed to add watch history entry: %w", op, err)
+	}
+
+	newEntry.Movie, err = r.GetMovie(ctx, newEntry.MovieID)
+	if err != nil && !errors.Is(err, repo.ErrMovieNotExists) {
+		return nil, fmt.Errorf("%s: failed to get watched movie: %w", op, err)
+	}
+
+	return &newEntry, nil
+}
+
+// ListWatchHistory returns page of user's watch history, most recent first
+func (r *Repository) ListWatchHistory(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchHistoryEntry, error) {
+	const op = "repository.sqlite.ListWatchHistory"
+
+	query, args, err := r.builder.Select("h.entry_id", "h.user_id", "h.movie_id", "h.watched_at", "h.progress").
+		Columns(outerMovieColumns("m", "h.movie_id")...).
+		From("watch_history h").
+		LeftJoin("movies m ON m.movie_id = h.movie_id").
+		Where(sq.Eq{"h.user_id": userID}).
+		OrderBy("h.watched_at DESC", "h.entry_id").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []struct {
+		model.WatchHistoryEntry
+		MovieDeleted bool `db:"movie_deleted"`
+	}
+	err = r.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get watch history: %w", op, err)
+	}
+
+	entries := make([]model.WatchHistoryEntry, 0, len(rows))
+	movies := make([]*model.Movie, 0, len(rows))
+	for _, row := range rows {
+		if row.MovieDeleted {
+			row.Movie = nil
+		} else {
+			movies = append(movies, row.Movie)
+		}
+		entries = append(entries, row.WatchHistoryEntry)
+	}
+
+	if err := r.loadRelations(ctx, r.db, movies...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
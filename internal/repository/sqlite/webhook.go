@@ -0,0 +1,368 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// eventTypes is a filter of webhook stored as JSON array
+type eventTypes []model.MovieEventType
+
+func (t eventTypes) Value() (driver.Value, error) {
+	encoded, err := json.Marshal([]model.MovieEventType(t))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(encoded), nil
+}
+
+func (t *eventTypes) Scan(src any) error {
+	var encoded []byte
+	switch src := src.(type) {
+	case string:
+		encoded = []byte(src)
+	case []byte:
+		encoded = src
+	default:
+		return fmt.Errorf("unsupported type of event types: %T", src)
+	}
+
+	*t = make(eventTypes, 0)
+
+	return json.Unmarshal(encoded, (*[]model.MovieEventType)(t))
+}
+
+// webhookRow is a webhook with its event filter
+type webhookRow struct {
+	model.Webhook
+	EventTypes eventTypes `db:"event_types"`
+}
+
+func (row *webhookRow) toModel() model.Webhook {
+	webhook := row.Webhook
+	webhook.EventTypes = row.EventTypes
+
+	return webhook
+}
+
+func (r *Repository) CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	const op = "repository.sqlite.CreateWebhook"
+
+	query, args, err := r.builder.Insert("webhooks").
+		Columns("webhook_id", "url", "secret", "event_types", "created_at").
+		Values(uuid.NewString(), webhook.URL, webhook.Secret, eventTypes(webhook.EventTypes), now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var row webhookRow
+	if err := r.db.GetContext(ctx, &row, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to add webhook: %w", op, err)
+	}
+
+	created := row.toModel()
+
+	return &created, nil
+}
+
+func (r *Repository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	const op = "repository.sqlite.ListWebhooks"
+
+	query, args, err := r.builder.Select("*").
+		From("webhooks").
+		OrderBy("created_at", "webhook_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []webhookRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to get webhooks: %w", op, err)
+	}
+
+	webhooks := make([]model.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhooks = append(webhooks, row.toModel())
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes the webhook along with its deliveries
+func (r *Repository) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	const op = "repository.sqlite.DeleteWebhook"
+
+	query, args, err := r.builder.Delete("webhooks").
+		Where(sq.Eq{"webhook_id": id}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to delete webhook: %w", op, err)
+	}
+
+	num, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get number of deleted rows: %w", op, err)
+	}
+
+	return num > 0, nil
+}
+
+// ListDeliveries returns deliveries of the webhook, newest first.
+// Empty status matches deliveries in any status.
+func (r *Repository) ListDeliveries(ctx context.Context, webhookID string, status model.DeliveryStatus, limit, offset uint64) ([]model.WebhookDelivery, error) {
+	const op = "repository.sqlite.ListDeliveries"
+
+	if err := r.checkWebhook(ctx, r.db, webhookID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	builder := r.builder.Select("d.*", "e.event_type").
+		From("webhook_deliveries d").
+		Join("movie_events e ON e.seq = d.event_seq").
+		Where(sq.Eq{"d.webhook_id": webhookID})
+	if status != "" {
+		builder = builder.Where(sq.Eq{"d.status": status})
+	}
+
+	query, args, err := builder.
+		OrderBy("d.created_at DESC", "d.delivery_id").
+		Limit(limit).
+		Offset(offset).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0, limit)
+	if err := r.db.SelectContext(ctx, &deliveries, query, args...); err != nil {
+		return nil, fmt.Errorf("%s: failed to get deliveries: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// CreateDelivery schedules one more delivery of the event to the webhook
+// regardless of previous deliveries
+func (r *Repository) CreateDelivery(ctx context.Context, webhookID string, eventSeq uint64) (*model.WebhookDelivery, error) {
+	const op = "repository.sqlite.CreateDelivery"
+
+	var delivery model.WebhookDelivery
+	err := r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		if err := r.checkWebhook(ctx, tx, webhookID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		query, args, err := r.builder.Select("event_type").
+			From("movie_events").
+			Where(sq.Eq{"seq": eventSeq}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &delivery.EventType, query, args...); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%s: failed to get event: %w", op, repo.ErrEventNotExists)
+			}
+
+			return fmt.Errorf("%s: failed to get event: %w", op, err)
+		}
+
+		createdAt := now()
+		query, args, err = r.builder.Insert("webhook_deliveries").
+			Columns("delivery_id", "webhook_id", "event_seq", "created_at", "next_attempt_at").
+			Values(uuid.NewString(), webhookID, eventSeq, createdAt, createdAt).
+			Suffix("RETURNING *").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.GetContext(ctx, &delivery, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to add delivery: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// ClaimDeliveries takes up to limit pending deliveries which are due, oldest first.
+// Claimed deliveries are hidden from other dispatchers for lease.
+func (r *Repository) ClaimDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]model.WebhookDispatch, error) {
+	const op = "repository.sqlite.ClaimDeliveries"
+
+	claimedAt := now()
+	due := sq.Select("delivery_id").
+		From("webhook_deliveries").
+		Where(sq.Eq{"status": model.DeliveryStatusPending}).
+		Where(sq.LtOrEq{"next_attempt_at": claimedAt}).
+		OrderBy("next_attempt_at").
+		Limit(limit)
+
+	claim, claimArgs, err := r.builder.Update("webhook_deliveries").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", claimedAt.Add(lease)).
+		Where(sq.Expr("delivery_id IN (?)", due)).
+		Suffix("RETURNING delivery_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	var rows []struct {
+		model.WebhookDelivery
+		URL    string        `db:"url"`
+		Secret string        `db:"secret"`
+		Event  movieEventRow `db:"event"`
+	}
+	err = r.inTx(ctx, op, func(tx *sqlx.Tx) error {
+		var ids []string
+		if err := tx.SelectContext(ctx, &ids, claim, claimArgs...); err != nil {
+			return fmt.Errorf("%s: failed to claim deliveries: %w", op, err)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		query, args, err := r.builder.Select(
+			"d.*", "e.event_type", "w.url", "w.secret",
+			`e.seq AS "event.seq"`,
+			`e.event_type AS "event.event_type"`,
+			`e.movie_id AS "event.movie_id"`,
+			`e.movie AS "event.movie"`,
+			`e.occurred_at AS "event.occurred_at"`,
+		).
+			From("webhook_deliveries d").
+			Join("webhooks w ON w.webhook_id = d.webhook_id").
+			Join("movie_events e ON e.seq = d.event_seq").
+			Where(sq.Eq{"d.delivery_id": ids}).
+			OrderBy("d.created_at", "d.delivery_id").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+		}
+
+		if err := tx.SelectContext(ctx, &rows, query, args...); err != nil {
+			return fmt.Errorf("%s: failed to get claimed deliveries: %w", op, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dispatches := make([]model.WebhookDispatch, 0, len(rows))
+	for _, row := range rows {
+		event, err := row.Event.decode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		dispatches = append(dispatches, model.WebhookDispatch{
+			Delivery: row.WebhookDelivery,
+			URL:      row.URL,
+			Secret:   row.Secret,
+			Event:    event,
+		})
+	}
+
+	return dispatches, nil
+}
+
+// MarkDeliverySucceeded marks delivery as delivered with the response status of the endpoint
+func (r *Repository) MarkDeliverySucceeded(ctx context.Context, id string, responseStatus int) error {
+	const op = "repository.sqlite.MarkDeliverySucceeded"
+
+	query, args, err := r.builder.Update("webhook_deliveries").
+		Set("status", model.DeliveryStatusDelivered).
+		Set("response_status", responseStatus).
+		Set("last_error", "").
+		Set("delivered_at", now()).
+		Where(sq.Eq{"delivery_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to mark delivery succeeded: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkDeliveryFailed schedules next attempt of the delivery at retryAt,
+// or moves it to dead letters if retryAt is nil
+func (r *Repository) MarkDeliveryFailed(ctx context.Context, id string, retryAt *time.Time, reason string, responseStatus int) error {
+	const op = "repository.sqlite.MarkDeliveryFailed"
+
+	builder := r.builder.Update("webhook_deliveries").
+		Set("response_status", responseStatus).
+		Set("last_error", reason)
+	if retryAt != nil {
+		builder = builder.Set("next_attempt_at", retryAt.UTC())
+	} else {
+		builder = builder.Set("status", model.DeliveryStatusDead)
+	}
+
+	query, args, err := builder.
+		Where(sq.Eq{"delivery_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: failed to form sql query: %w", op, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: failed to mark delivery failed: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) checkWebhook(ctx context.Context, q sqlx.QueryerContext, id string) error {
+	query, args, err := r.builder.Select("webhook_id").
+		From("webhooks").
+		Where(sq.Eq{"webhook_id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to form sql query: %w", err)
+	}
+
+	var webhookID string
+	if err := sqlx.GetContext(ctx, q, &webhookID, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get webhook: %w", repo.ErrWebhookNotExists)
+		}
+
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// SimilarTitleThreshold is the default threshold of pg_trgm % operator
+	SimilarTitleThreshold = 0.3
+	// SimilarEraYears is the window of similar movies by release year. Movies
+	// released less than SimilarEraYears apart are of the same era and get
+	// score for it, the closer the more.
+	SimilarEraYears = 10
+)
+
+// NormalizeName does the same as normalize_name function of postgres schema:
+// strips diacritics, collapses whitespace and lowercases the name
+func NormalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.ToLower(strings.Join(strings.Fields(b.String()), " "))
+}
+
+// TrigramSimilarity computes similarity of strings the same way as
+// similarity function of pg_trgm: share of trigrams of padded words
+// common for both strings
+func TrigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+
+	common := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			common++
+		}
+	}
+
+	total := len(ta) + len(tb) - common
+	if total == 0 {
+		return 0
+	}
+
+	return float64(common) / float64(total)
+}
+
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,22 @@
+package repository
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Pedro Almodóvar", want: "pedro almodovar"},
+		{name: "  PEDRO \t almodovar ", want: "pedro almodovar"},
+		{name: "Bong Joon-ho", want: "bong joon-ho"},
+		{name: "Krzysztof Kieślowski", want: "krzysztof kieslowski"},
+		{name: "Alejandro González Iñárritu", want: "alejandro gonzalez inarritu"},
+		{name: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package movieservice
+
+import (
+	"context"
+	"movie-service/internal/model"
+)
+
+// exportBatch is number of movies read from storage at once during export
+const exportBatch = 500
+
+// ExportMovies passes all movies matching the filter to fn batch by batch
+func (s *Service) ExportMovies(ctx context.Context, filter *model.MovieFilter, fn func([]model.Movie) error) error {
+	return s.movieRepo.ExportMovies(ctx, filter, exportBatch, fn)
+}
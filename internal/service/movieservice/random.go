@@ -0,0 +1,27 @@
+package movieservice
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// movieOfTheDayNamespace makes pivots of movie of the day differ from
+// other name-based UUIDs
+var movieOfTheDayNamespace = uuid.MustParse("2d1f6a8e-54b1-4c43-9a4e-7f0c3b9e6d21")
+
+// GetRandomMovie picks random movie matching the filter
+func (s *Service) GetRandomMovie(ctx context.Context, filter *model.MovieFilter) (*model.Movie, error) {
+	return s.movieRepo.GetMovieAfter(ctx, uuid.NewString(), filter)
+}
+
+// GetMovieOfTheDay picks movie deterministically by date and seed, so every
+// instance of the service returns the same movie for the same catalog
+func (s *Service) GetMovieOfTheDay(ctx context.Context, date time.Time, seed string) (*model.Movie, error) {
+	name := date.Format(time.DateOnly) + "/" + seed
+	pivot := uuid.NewSHA1(movieOfTheDayNamespace, []byte(name))
+
+	return s.movieRepo.GetMovieAfter(ctx, pivot.String(), nil)
+}
@@ -0,0 +1,56 @@
+package movieservice
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"testing"
+	"time"
+)
+
+// pivotRepo returns movie with ID equal to pivot of GetMovieAfter, calls
+// of other methods panic
+type pivotRepo struct {
+	movieRepo
+}
+
+func (r *pivotRepo) GetMovieAfter(ctx context.Context, pivot string, filter *model.MovieFilter) (*model.Movie, error) {
+	return &model.Movie{ID: pivot}, nil
+}
+
+func TestGetMovieOfTheDay(t *testing.T) {
+	ctx := context.Background()
+	repo := &pivotRepo{}
+	s := New(repo, nil)
+
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	pivot := func(date time.Time, seed string) string {
+		t.Helper()
+
+		movie, err := s.GetMovieOfTheDay(ctx, date, seed)
+		if err != nil {
+			t.Fatalf("GetMovieOfTheDay error = %v", err)
+		}
+
+		return movie.ID
+	}
+
+	first := pivot(day, "")
+	if got := pivot(day, ""); got != first {
+		t.Errorf("pivot for the same date = %s, want %s", got, first)
+	}
+	// Only the date matters, not the time of the day
+	if got := pivot(day.Add(23*time.Hour), ""); got != first {
+		t.Errorf("pivot later the same day = %s, want %s", got, first)
+	}
+	if got := pivot(day.AddDate(0, 0, 1), ""); got == first {
+		t.Errorf("pivot for the next date = %s, want different from %s", got, first)
+	}
+	if got := pivot(day, "staff-picks"); got == first {
+		t.Errorf("pivot for another seed = %s, want different from %s", got, first)
+	}
+	// Pivot must not change between releases, otherwise instances running
+	// different versions disagree on the movie of the day
+	if want := "691b8c20-b9ac-564d-87e6-25170ca1fd94"; first != want {
+		t.Errorf("pivot = %s, want %s", first, want)
+	}
+}
@@ -1,45 +1,153 @@
 package movieservice
 
-import "movie-service/internal/model"
+import (
+	"context"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+)
 
 type movieRepo interface {
-	GetMovie(id string) (*model.Movie, error)
-	GetMovies() ([]model.Movie, error)
-	CreateMovie(movie *model.Movie) (string, error)
-	CreateMovies(movies []model.Movie) ([]string, error)
-	UpdateMovie(id string, movie *model.Movie) (*model.Movie, error)
-	DeleteMovie(id string) (bool, error)
+	// InTx runs operations spanning several calls of repository in a single
+	// transaction, see repository.TxManager
+	InTx(ctx context.Context, fn func(r repository.Repository) error) error
+	GetMovie(ctx context.Context, id string) (*model.Movie, error)
+	GetMovies(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error)
+	ListMovies(ctx context.Context, filter *model.MovieFilter, limit, offset uint64) ([]model.Movie, error)
+	CreateMovie(ctx context.Context, movie *model.Movie) (string, error)
+	CreateMovies(ctx context.Context, movies []model.Movie) ([]string, error)
+	UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error)
+	DeleteMovie(ctx context.Context, id string) (bool, error)
+	RateMovie(ctx context.Context, rating *model.Rating) (*model.Movie, error)
+	CreateReview(ctx context.Context, review *model.Review) (*model.Review, error)
+	ListReviews(ctx context.Context, movieID string, limit, offset uint64) ([]model.Review, error)
+	UpdateReview(ctx context.Context, review *model.Review) (*model.Review, error)
+	DeleteReview(ctx context.Context, id, userID string) (bool, error)
+	ModerateReview(ctx context.Context, id string, status model.ReviewStatus) (*model.Review, error)
+	CreateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error)
+	GetCollection(ctx context.Context, id string) (*model.Collection, error)
+	UpdateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error)
+	SetCollectionMovies(ctx context.Context, id string, movieIDs []string) (*model.Collection, error)
+	DeleteCollection(ctx context.Context, id string) (bool, error)
+	GetCollectionMovies(ctx context.Context, id string) ([]model.Movie, error)
+	AddTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error)
+	RemoveTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error)
+	ListTags(ctx context.Context, prefix string, limit uint64) ([]model.Tag, error)
+	GetCatalogStats(ctx context.Context, query *model.CatalogStatsQuery) (*model.CatalogStats, error)
+	GetSimilarMovies(ctx context.Context, id string, limit uint64) ([]model.SimilarMovie, error)
+	GetFilmography(ctx context.Context, director string) ([]model.Movie, error)
+	GetMovieAfter(ctx context.Context, pivot string, filter *model.MovieFilter) (*model.Movie, error)
+	GetMovieEvents(ctx context.Context, afterSeq uint64, limit uint64) ([]model.MovieEvent, error)
+	GetLastMovieEventSeq(ctx context.Context) (uint64, error)
+	ExportMovies(ctx context.Context, filter *model.MovieFilter, batch uint64, fn func([]model.Movie) error) error
+}
+
+type eventNotifier interface {
+	Subscribe() (<-chan struct{}, func())
 }
 
 type Service struct {
 	movieRepo movieRepo
+	notifier  eventNotifier
 }
 
-func New(movieRepo movieRepo) *Service {
+func New(movieRepo movieRepo, notifier eventNotifier) *Service {
 	return &Service{
 		movieRepo: movieRepo,
+		notifier:  notifier,
 	}
 }
 
-func (s *Service) GetMovie(id string) (*model.Movie, error) {
-	return s.movieRepo.GetMovie(id)
+func (s *Service) GetMovie(ctx context.Context, id string) (*model.Movie, error) {
+	return s.movieRepo.GetMovie(ctx, id)
+}
+
+func (s *Service) GetMovies(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error) {
+	return s.movieRepo.GetMovies(ctx, filter)
+}
+
+func (s *Service) ListMovies(ctx context.Context, filter *model.MovieFilter, limit, offset uint64) ([]model.Movie, error) {
+	return s.movieRepo.ListMovies(ctx, filter, limit, offset)
+}
+
+func (s *Service) CreateMovie(ctx context.Context, movie *model.Movie) (string, error) {
+	return s.movieRepo.CreateMovie(ctx, movie)
+}
+
+func (s *Service) CreateMovies(ctx context.Context, movies []model.Movie) ([]string, error) {
+	return s.movieRepo.CreateMovies(ctx, movies)
+}
+
+func (s *Service) UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
+	return s.movieRepo.UpdateMovie(ctx, id, movie)
+}
+func (s *Service) DeleteMovie(ctx context.Context, id string) (bool, error) {
+	return s.movieRepo.DeleteMovie(ctx, id)
+}
+
+func (s *Service) RateMovie(ctx context.Context, rating *model.Rating) (*model.Movie, error) {
+	return s.movieRepo.RateMovie(ctx, rating)
+}
+
+func (s *Service) CreateReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	return s.movieRepo.CreateReview(ctx, review)
+}
+
+func (s *Service) ListReviews(ctx context.Context, movieID string, limit, offset uint64) ([]model.Review, error) {
+	return s.movieRepo.ListReviews(ctx, movieID, limit, offset)
 }
 
-func (s *Service) GetMovies() ([]model.Movie, error) {
-	return s.movieRepo.GetMovies()
+func (s *Service) UpdateReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	return s.movieRepo.UpdateReview(ctx, review)
 }
 
-func (s *Service) CreateMovie(movie *model.Movie) (string, error) {
-	return s.movieRepo.CreateMovie(movie)
+func (s *Service) DeleteReview(ctx context.Context, id, userID string) (bool, error) {
+	return s.movieRepo.DeleteReview(ctx, id, userID)
 }
 
-func (s *Service) CreateMovies(movies []model.Movie) ([]string, error) {
-	return s.movieRepo.CreateMovies(movies)
+func (s *Service) ModerateReview(ctx context.Context, id string, status model.ReviewStatus) (*model.Review, error) {
+	return s.movieRepo.ModerateReview(ctx, id, status)
 }
 
-func (s *Service) UpdateMovie(id string, movie *model.Movie) (*model.Movie, error) {
-	return s.movieRepo.UpdateMovie(id, movie)
+func (s *Service) CreateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error) {
+	return s.movieRepo.CreateCollection(ctx, collection)
 }
-func (s *Service) DeleteMovie(id string) (bool, error) {
-	return s.movieRepo.DeleteMovie(id)
+
+func (s *Service) GetCollection(ctx context.Context, id string) (*model.Collection, error) {
+	return s.movieRepo.GetCollection(ctx, id)
+}
+
+func (s *Service) UpdateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error) {
+	return s.movieRepo.UpdateCollection(ctx, collection)
+}
+
+func (s *Service) SetCollectionMovies(ctx context.Context, id string, movieIDs []string) (*model.Collection, error) {
+	return s.movieRepo.SetCollectionMovies(ctx, id, movieIDs)
+}
+
+func (s *Service) DeleteCollection(ctx context.Context, id string) (bool, error) {
+	return s.movieRepo.DeleteCollection(ctx, id)
+}
+
+func (s *Service) GetCollectionMovies(ctx context.Context, id string) ([]model.Movie, error) {
+	return s.movieRepo.GetCollectionMovies(ctx, id)
+}
+
+func (s *Service) AddTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error) {
+	return s.movieRepo.AddTags(ctx, movieID, tags)
+}
+
+func (s *Service) RemoveTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error) {
+	return s.movieRepo.RemoveTags(ctx, movieID, tags)
+}
+
+func (s *Service) ListTags(ctx context.Context, prefix string, limit uint64) ([]model.Tag, error) {
+	return s.movieRepo.ListTags(ctx, prefix, limit)
+}
+
+func (s *Service) GetCatalogStats(ctx context.Context, query *model.CatalogStatsQuery) (*model.CatalogStats, error) {
+	return s.movieRepo.GetCatalogStats(ctx, query)
+}
+
+func (s *Service) GetFilmography(ctx context.Context, director string) ([]model.Movie, error) {
+	return s.movieRepo.GetFilmography(ctx, director)
 }
@@ -0,0 +1,48 @@
+package movieservice
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+)
+
+const (
+	reasonSameGenre    = "same genre"
+	reasonSameDirector = "same director"
+	reasonSameEra      = "same era"
+	reasonSimilarTitle = "similar title"
+)
+
+func (s *Service) GetSimilarMovies(ctx context.Context, id string, limit uint64) ([]model.SimilarMovie, error) {
+	movies, err := s.movieRepo.GetSimilarMovies(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range movies {
+		movies[i].Reasons = similarityReasons(&movies[i])
+	}
+
+	return movies, nil
+}
+
+// similarityReasons explains in human-readable form why movie was considered
+// similar. Era and title are reasons only if they add to the score of the movie.
+func similarityReasons(movie *model.SimilarMovie) []string {
+	reasons := make([]string, 0, 4)
+
+	if movie.SameDirector {
+		reasons = append(reasons, reasonSameDirector)
+	}
+	if movie.SameGenre {
+		reasons = append(reasons, reasonSameGenre)
+	}
+	if movie.YearDiff < repository.SimilarEraYears {
+		reasons = append(reasons, reasonSameEra)
+	}
+	if movie.TitleSimilarity >= repository.SimilarTitleThreshold {
+		reasons = append(reasons, reasonSimilarTitle)
+	}
+
+	return reasons
+}
@@ -0,0 +1,49 @@
+package movieservice
+
+import (
+	"movie-service/internal/model"
+	"movie-service/internal/repository"
+	"slices"
+	"testing"
+)
+
+func TestSimilarityReasons(t *testing.T) {
+	tests := []struct {
+		name  string
+		movie model.SimilarMovie
+		want  []string
+	}{
+		{
+			name:  "nothing in common",
+			movie: model.SimilarMovie{YearDiff: repository.SimilarEraYears, TitleSimilarity: 0.1},
+			want:  []string{},
+		},
+		{
+			name:  "every reason in order",
+			movie: model.SimilarMovie{SameGenre: true, SameDirector: true, YearDiff: 0, TitleSimilarity: 1},
+			want:  []string{reasonSameDirector, reasonSameGenre, reasonSameEra, reasonSimilarTitle},
+		},
+		{
+			name:  "era boundary",
+			movie: model.SimilarMovie{SameGenre: true, YearDiff: repository.SimilarEraYears - 1},
+			want:  []string{reasonSameGenre, reasonSameEra},
+		},
+		{
+			name:  "title threshold",
+			movie: model.SimilarMovie{YearDiff: repository.SimilarEraYears, TitleSimilarity: repository.SimilarTitleThreshold},
+			want:  []string{reasonSimilarTitle},
+		},
+		{
+			name:  "title below threshold",
+			movie: model.SimilarMovie{SameDirector: true, YearDiff: 20, TitleSimilarity: repository.SimilarTitleThreshold - 0.01},
+			want:  []string{reasonSameDirector},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := similarityReasons(&tt.movie); !slices.Equal(got, tt.want) {
+				t.Errorf("similarityReasons = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package movieservice
+
+import (
+	"context"
+	"movie-service/internal/model"
+	"time"
+)
+
+const (
+	// watchBatch is max number of events fetched at once
+	watchBatch = 100
+
+	// watchPollInterval is how often events are checked for in case
+	// notification is lost
+	watchPollInterval = 30 * time.Second
+)
+
+// WatchMovies passes movie events to send in order of their sequence numbers
+// until ctx is done or send fails. Events after afterSeq are passed; if afterSeq
+// is nil, only events which happen from now on are passed.
+func (s *Service) WatchMovies(ctx context.Context, afterSeq *uint64, send func(*model.MovieEvent) error) error {
+	// Subscribe before reading events, so that no notification is missed between
+	// reading the last event and waiting for the next one
+	wakeup, unsubscribe := s.notifier.Subscribe()
+	defer unsubscribe()
+
+	var seq uint64
+	if afterSeq != nil {
+		seq = *afterSeq
+	} else {
+		last, err := s.movieRepo.GetLastMovieEventSeq(ctx)
+		if err != nil {
+			return err
+		}
+		seq = last
+	}
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Send everything which is available now
+		for {
+			events, err := s.movieRepo.GetMovieEvents(ctx, seq, watchBatch)
+			if err != nil {
+				return err
+			}
+
+			for i := range events {
+				if err := send(&events[i]); err != nil {
+					return err
+				}
+				seq = events[i].Seq
+			}
+
+			if len(events) < watchBatch {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wakeup:
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package movieservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"movie-service/internal/model"
+	memoryrepo "movie-service/internal/repository/memory"
+	"testing"
+	"time"
+)
+
+func createMovies(t *testing.T, repo *memoryrepo.Repository, n int) {
+	t.Helper()
+
+	for i := range n {
+		movie := &model.Movie{Title: fmt.Sprintf("Movie %03d", i), Genre: "Drama", Director: "Director", Year: 2000}
+		if _, err := repo.CreateMovie(context.Background(), movie); err != nil {
+			t.Fatalf("CreateMovie error = %v", err)
+		}
+	}
+}
+
+// watch runs WatchMovies in background and returns channel of sequence
+// numbers of sent events and channel of its result
+func watch(ctx context.Context, s *Service, afterSeq *uint64) (<-chan uint64, <-chan error) {
+	seqs := make(chan uint64, 1000)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchMovies(ctx, afterSeq, func(event *model.MovieEvent) error {
+			seqs <- event.Seq
+			return nil
+		})
+	}()
+
+	return seqs, done
+}
+
+// wantSeqs waits for events with sequence numbers from..to in order
+func wantSeqs(t *testing.T, seqs <-chan uint64, from, to uint64) {
+	t.Helper()
+
+	for want := from; want <= to; want++ {
+		select {
+		case got := <-seqs:
+			if got != want {
+				t.Fatalf("got event %d, want %d", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %d", want)
+		}
+	}
+}
+
+func TestWatchMovies(t *testing.T) {
+	repo := memoryrepo.New()
+	s := New(repo, repo.Notifier())
+	createMovies(t, repo, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	afterSeq := uint64(1)
+	seqs, done := watch(ctx, s, &afterSeq)
+
+	// Events after afterSeq are replayed, then new ones follow as they happen
+	wantSeqs(t, seqs, 2, 3)
+	createMovies(t, repo, 2)
+	wantSeqs(t, seqs, 4, 5)
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("WatchMovies error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWatchMoviesFromNow(t *testing.T) {
+	repo := memoryrepo.New()
+	s := New(repo, repo.Notifier())
+	createMovies(t, repo, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	seqs, _ := watch(ctx, s, nil)
+
+	// Watcher may start reading after some of new movies are created, so
+	// movies are created until it sends the first event
+	for {
+		createMovies(t, repo, 1)
+
+		select {
+		case seq := <-seqs:
+			// Past events are skipped
+			if seq <= 3 {
+				t.Fatalf("got past event %d, want events after 3", seq)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestWatchMoviesBacklog(t *testing.T) {
+	repo := memoryrepo.New()
+	s := New(repo, repo.Notifier())
+	createMovies(t, repo, 2*watchBatch+10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	afterSeq := uint64(0)
+	seqs, _ := watch(ctx, s, &afterSeq)
+
+	// Backlog larger than a batch is sent without waiting for notifications
+	wantSeqs(t, seqs, 1, 2*watchBatch+10)
+}
+
+func TestWatchMoviesSendFails(t *testing.T) {
+	repo := memoryrepo.New()
+	s := New(repo, repo.Notifier())
+	createMovies(t, repo, 3)
+
+	errSend := errors.New("stream is closed")
+	sent := 0
+	afterSeq := uint64(0)
+	err := s.WatchMovies(context.Background(), &afterSeq, func(event *model.MovieEvent) error {
+		sent++
+		return errSend
+	})
+	if !errors.Is(err, errSend) || sent != 1 {
+		t.Errorf("WatchMovies error = %v after %d events, want %v after 1 event", err, sent, errSend)
+	}
+}
@@ -0,0 +1,44 @@
+package watchlistservice
+
+import (
+	"context"
+	"movie-service/internal/model"
+)
+
+type watchlistRepo interface {
+	AddToWatchlist(ctx context.Context, userID, movieID string) (bool, error)
+	RemoveFromWatchlist(ctx context.Context, userID, movieID string) (bool, error)
+	ListWatchlist(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchlistEntry, error)
+	RecordWatch(ctx context.Context, entry *model.WatchHistoryEntry) (*model.WatchHistoryEntry, error)
+	ListWatchHistory(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchHistoryEntry, error)
+}
+
+type Service struct {
+	watchlistRepo watchlistRepo
+}
+
+func New(watchlistRepo watchlistRepo) *Service {
+	return &Service{
+		watchlistRepo: watchlistRepo,
+	}
+}
+
+func (s *Service) AddToWatchlist(ctx context.Context, userID, movieID string) (bool, error) {
+	return s.watchlistRepo.AddToWatchlist(ctx, userID, movieID)
+}
+
+func (s *Service) RemoveFromWatchlist(ctx context.Context, userID, movieID string) (bool, error) {
+	return s.watchlistRepo.RemoveFromWatchlist(ctx, userID, movieID)
+}
+
+func (s *Service) ListWatchlist(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchlistEntry, error) {
+	return s.watchlistRepo.ListWatchlist(ctx, userID, limit, offset)
+}
+
+func (s *Service) RecordWatch(ctx context.Context, entry *model.WatchHistoryEntry) (*model.WatchHistoryEntry, error) {
+	return s.watchlistRepo.RecordWatch(ctx, entry)
+}
+
+func (s *Service) ListWatchHistory(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchHistoryEntry, error) {
+	return s.watchlistRepo.ListWatchHistory(ctx, userID, limit, offset)
+}
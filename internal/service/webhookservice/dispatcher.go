@@ -0,0 +1,189 @@
+package webhookservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"movie-service/internal/model"
+	"movie-service/internal/outbox"
+	"movie-service/pkg/sl"
+	"net/http"
+	"time"
+)
+
+// maxErrorBody is how much of failed response body is kept for debugging
+const maxErrorBody = 512
+
+type deliveryRepo interface {
+	ClaimDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]model.WebhookDispatch, error)
+	MarkDeliverySucceeded(ctx context.Context, id string, responseStatus int) error
+	MarkDeliveryFailed(ctx context.Context, id string, retryAt *time.Time, reason string, responseStatus int) error
+}
+
+type eventNotifier interface {
+	Subscribe() (<-chan struct{}, func())
+}
+
+type DispatcherConfig struct {
+	BatchSize    uint64
+	PollInterval time.Duration
+	Lease        time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	// MaxAttempts is number of attempts after which delivery becomes dead
+	MaxAttempts uint32
+}
+
+// Dispatcher sends scheduled deliveries to webhooks. Request body is the event
+// as JSON signed with the webhook secret, see Sign.
+type Dispatcher struct {
+	log      *slog.Logger
+	repo     deliveryRepo
+	notifier eventNotifier
+	client   *http.Client
+	cfg      DispatcherConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewDispatcher(
+	log *slog.Logger,
+	repo deliveryRepo,
+	notifier eventNotifier,
+	client *http.Client,
+	cfg DispatcherConfig,
+) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Dispatcher{
+		log:      log.With(slog.String("component", "webhookservice.Dispatcher")),
+		repo:     repo,
+		notifier: notifier,
+		client:   client,
+		cfg:      cfg,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Run dispatches deliveries until Stop is called
+func (d *Dispatcher) Run() {
+	defer close(d.done)
+
+	// Deliveries are scheduled along with movie events
+	wakeup, unsubscribe := d.notifier.Subscribe()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatch()
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-wakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops dispatching and waits for deliveries in progress to finish
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	<-d.done
+}
+
+// dispatch sends all deliveries which are due
+func (d *Dispatcher) dispatch() {
+	for d.ctx.Err() == nil {
+		dispatches, err := d.repo.ClaimDeliveries(d.ctx, d.cfg.BatchSize, d.cfg.Lease)
+		if err != nil {
+			d.log.Error("Failed to claim deliveries", sl.Err(err))
+			return
+		}
+
+		for i := range dispatches {
+			d.deliver(&dispatches[i])
+		}
+
+		if uint64(len(dispatches)) < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(dispatch *model.WebhookDispatch) {
+	// Result of the attempt is saved even if dispatcher is being stopped
+	ctx := context.WithoutCancel(d.ctx)
+	delivery := &dispatch.Delivery
+	log := d.log.With(
+		slog.String("delivery_id", delivery.ID),
+		slog.String("webhook_id", delivery.WebhookID),
+		slog.Any("attempt", delivery.Attempts),
+	)
+
+	responseStatus, err := d.send(dispatch)
+	if err == nil {
+		if err := d.repo.MarkDeliverySucceeded(ctx, delivery.ID, responseStatus); err != nil {
+			// Delivery will be sent again once lease expires
+			log.Error("Failed to mark delivery succeeded", sl.Err(err))
+		}
+
+		return
+	}
+
+	var retryAt *time.Time
+	if delivery.Attempts < d.cfg.MaxAttempts {
+		at := time.Now().Add(outbox.Backoff(delivery.Attempts, d.cfg.MinBackoff, d.cfg.MaxBackoff))
+		retryAt = &at
+		log.Warn("Failed to deliver event", sl.Err(err), slog.Time("retry_at", at))
+	} else {
+		log.Warn("Failed to deliver event, giving up", sl.Err(err))
+	}
+
+	if err := d.repo.MarkDeliveryFailed(ctx, delivery.ID, retryAt, err.Error(), responseStatus); err != nil {
+		log.Error("Failed to mark delivery failed", sl.Err(err))
+	}
+}
+
+// send posts the event to the webhook and returns response status.
+// Any response status other than 2xx is treated as failure.
+func (d *Dispatcher) send(dispatch *model.WebhookDispatch) (int, error) {
+	body, err := json.Marshal(outbox.NewMessage(&dispatch.Event))
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, dispatch.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(dispatch.Secret, body))
+	req.Header.Set(EventHeader, string(dispatch.Event.Type))
+	req.Header.Set(DeliveryHeader, dispatch.Delivery.ID)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	// Drain the rest so that connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s: %s", resp.Status, respBody)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,148 @@
+package webhookservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"movie-service/internal/model"
+	"movie-service/internal/outbox"
+	memoryrepo "movie-service/internal/repository/memory"
+	"movie-service/internal/service/webhookservice"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcher(t *testing.T) {
+	const secret = "secret-of-webhook"
+
+	tests := []struct {
+		name string
+		// statuses answered by the endpoint in turn, the last one repeats
+		statuses     []int
+		wantStatus   model.DeliveryStatus
+		wantAttempts uint32
+		wantResponse int
+	}{
+		{name: "delivered", statuses: []int{http.StatusOK}, wantStatus: model.DeliveryStatusDelivered, wantAttempts: 1, wantResponse: http.StatusOK},
+		{
+			name:         "retried after server errors",
+			statuses:     []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusNoContent},
+			wantStatus:   model.DeliveryStatusDelivered,
+			wantAttempts: 3,
+			wantResponse: http.StatusNoContent,
+		},
+		{
+			name:         "dead after max attempts",
+			statuses:     []int{http.StatusServiceUnavailable},
+			wantStatus:   model.DeliveryStatusDead,
+			wantAttempts: 3,
+			wantResponse: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests int
+			)
+			endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("failed to read request body: %v", err)
+				}
+
+				signature := r.Header.Get(webhookservice.SignatureHeader)
+				if !strings.HasPrefix(signature, "sha256=") || !webhookservice.Verify(secret, body, signature) {
+					t.Errorf("%s = %q is not valid signature of body", webhookservice.SignatureHeader, signature)
+				}
+				if got := r.Header.Get(webhookservice.EventHeader); got != string(model.MovieEventCreated) {
+					t.Errorf("%s = %q, want %q", webhookservice.EventHeader, got, model.MovieEventCreated)
+				}
+
+				var msg outbox.Message
+				if err := json.Unmarshal(body, &msg); err != nil {
+					t.Errorf("failed to decode body: %v", err)
+				}
+				if msg.Movie.Title != "Alien" {
+					t.Errorf("title of movie in body = %q, want %q", msg.Movie.Title, "Alien")
+				}
+
+				mu.Lock()
+				status := tt.statuses[min(requests, len(tt.statuses)-1)]
+				requests++
+				mu.Unlock()
+
+				w.WriteHeader(status)
+			}))
+			defer endpoint.Close()
+
+			ctx := context.Background()
+			repo := memoryrepo.New()
+			webhook, err := repo.CreateWebhook(ctx, &model.Webhook{URL: endpoint.URL, Secret: secret})
+			if err != nil {
+				t.Fatalf("CreateWebhook error = %v", err)
+			}
+			if _, err := repo.CreateMovie(ctx, &model.Movie{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979}); err != nil {
+				t.Fatalf("CreateMovie error = %v", err)
+			}
+
+			d := webhookservice.NewDispatcher(
+				slog.New(slog.NewTextHandler(io.Discard, nil)),
+				repo,
+				repo.Notifier(),
+				endpoint.Client(),
+				webhookservice.DispatcherConfig{
+					BatchSize:    10,
+					PollInterval: time.Millisecond,
+					Lease:        time.Minute,
+					MinBackoff:   time.Millisecond,
+					MaxBackoff:   time.Millisecond,
+					MaxAttempts:  3,
+				},
+			)
+			go d.Run()
+			defer d.Stop()
+
+			// Wait until delivery leaves pending status
+			var delivery model.WebhookDelivery
+			for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+				deliveries, err := repo.ListDeliveries(ctx, webhook.ID, "", 10, 0)
+				if err != nil {
+					t.Fatalf("ListDeliveries error = %v", err)
+				}
+				if len(deliveries) != 1 {
+					t.Fatalf("got %d deliveries, want 1", len(deliveries))
+				}
+
+				delivery = deliveries[0]
+				if delivery.Status != model.DeliveryStatusPending {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("delivery is still pending after %d attempts", delivery.Attempts)
+				}
+			}
+
+			if delivery.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", delivery.Status, tt.wantStatus)
+			}
+			if delivery.Attempts != tt.wantAttempts {
+				t.Errorf("Attempts = %d, want %d", delivery.Attempts, tt.wantAttempts)
+			}
+			if delivery.ResponseStatus != tt.wantResponse {
+				t.Errorf("ResponseStatus = %d, want %d", delivery.ResponseStatus, tt.wantResponse)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if requests != int(tt.wantAttempts) {
+				t.Errorf("endpoint got %d requests, want %d", requests, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package webhookservice
+
+import (
+	"context"
+	"movie-service/internal/model"
+)
+
+type webhookRepo interface {
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) (bool, error)
+	ListDeliveries(ctx context.Context, webhookID string, status model.DeliveryStatus, limit, offset uint64) ([]model.WebhookDelivery, error)
+	CreateDelivery(ctx context.Context, webhookID string, eventSeq uint64) (*model.WebhookDelivery, error)
+}
+
+type Service struct {
+	webhookRepo webhookRepo
+}
+
+func New(webhookRepo webhookRepo) *Service {
+	return &Service{
+		webhookRepo: webhookRepo,
+	}
+}
+
+func (s *Service) CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	return s.webhookRepo.CreateWebhook(ctx, webhook)
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	return s.webhookRepo.ListWebhooks(ctx)
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	return s.webhookRepo.DeleteWebhook(ctx, id)
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, webhookID string, status model.DeliveryStatus, limit, offset uint64) ([]model.WebhookDelivery, error) {
+	return s.webhookRepo.ListDeliveries(ctx, webhookID, status, limit, offset)
+}
+
+// RedeliverEvent schedules one more delivery of the event to the webhook. It works
+// for events which were delivered already as well as for dead deliveries.
+func (s *Service) RedeliverEvent(ctx context.Context, webhookID string, eventSeq uint64) (*model.WebhookDelivery, error) {
+	return s.webhookRepo.CreateDelivery(ctx, webhookID, eventSeq)
+}
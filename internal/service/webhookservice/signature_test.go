@@ -0,0 +1,42 @@
+package webhookservice_test
+
+import (
+	"movie-service/internal/service/webhookservice"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// Digest is computed with: printf '{"sequence":1}' | openssl dgst -sha256 -hmac secret
+	const want = "sha256=88201002024db60acf662a6ab8d74e497cef28e379ce1a4a903c4382553c0a8c"
+
+	if got := webhookservice.Sign("secret", []byte(`{"sequence":1}`)); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "secret-of-webhook"
+	body := []byte(`{"sequence":1,"type":"created"}`)
+	signature := webhookservice.Sign(secret, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, body: body, signature: signature, want: true},
+		{name: "tampered body", secret: secret, body: []byte(`{"sequence":1,"type":"deleted"}`), signature: signature},
+		{name: "wrong secret", secret: "another-secret", body: body, signature: signature},
+		{name: "missing prefix", secret: secret, body: body, signature: signature[len("sha256="):]},
+		{name: "empty signature", secret: secret, body: body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := webhookservice.Verify(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package dto
+
+import "movie-service/internal/model"
+
+type CreateCollectionRequest struct {
+	Name        string               `validate:"required,max=256"`
+	Description string               `validate:"max=4096"`
+	Kind        model.CollectionKind `validate:"required,oneof=franchise curated"`
+	MovieIDs    []string             `validate:"unique,dive,uuid"`
+}
+
+func (req *CreateCollectionRequest) ToModel() *model.Collection {
+	return &model.Collection{
+		Name:        req.Name,
+		Description: req.Description,
+		Kind:        req.Kind,
+		MovieIDs:    req.MovieIDs,
+	}
+}
+
+type UpdateCollectionRequest struct {
+	ID          string               `validate:"required,uuid"`
+	Name        string               `validate:"omitempty,max=256"`
+	Description string               `validate:"omitempty,max=4096"`
+	Kind        model.CollectionKind `validate:"omitempty,oneof=franchise curated"`
+}
+
+func (req *UpdateCollectionRequest) ToModel() *model.Collection {
+	return &model.Collection{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Kind:        req.Kind,
+	}
+}
+
+type SetCollectionMoviesRequest struct {
+	ID       string   `validate:"required,uuid"`
+	MovieIDs []string `validate:"unique,dive,uuid"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"movie-service/internal/model"
+	"strconv"
+	"strings"
+)
+
+const (
+	ExportFormatCSV   = "csv"
+	ExportFormatJSONL = "jsonl"
+)
+
+type ExportMoviesRequest struct {
+	Format string `validate:"omitempty,oneof=csv jsonl"`
+	Filter MovieFilter
+}
+
+// MovieRecord is a movie as it is written to exported files
+type MovieRecord struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Genre         string   `json:"genre"`
+	Director      string   `json:"director"`
+	Year          uint32   `json:"year"`
+	AverageRating float64  `json:"average_rating"`
+	RatingCount   uint32   `json:"rating_count"`
+	ReviewCount   uint32   `json:"review_count"`
+	Tags          []string `json:"tags"`
+	CollectionIDs []string `json:"collection_ids"`
+}
+
+// MovieRecordHeader is a header of CSV export, columns go in order of CSV record
+var MovieRecordHeader = []string{
+	"id", "title", "genre", "director", "year",
+	"average_rating", "rating_count", "review_count", "tags", "collection_ids",
+}
+
+func NewMovieRecord(movie *model.Movie) *MovieRecord {
+	return &MovieRecord{
+		ID:            movie.ID,
+		Title:         movie.Title,
+		Genre:         movie.Genre,
+		Director:      movie.Director,
+		Year:          movie.Year,
+		AverageRating: movie.AverageRating(),
+		RatingCount:   movie.RatingCount,
+		ReviewCount:   movie.ReviewCount,
+		Tags:          movie.Tags,
+		CollectionIDs: movie.CollectionIDs,
+	}
+}
+
+// CSV returns fields of CSV record. Lists are joined by semicolon.
+func (r *MovieRecord) CSV() []string {
+	return []string{
+		r.ID,
+		r.Title,
+		r.Genre,
+		r.Director,
+		strconv.FormatUint(uint64(r.Year), 10),
+		strconv.FormatFloat(r.AverageRating, 'f', -1, 64),
+		strconv.FormatUint(uint64(r.RatingCount), 10),
+		strconv.FormatUint(uint64(r.ReviewCount), 10),
+		strings.Join(r.Tags, ";"),
+		strings.Join(r.CollectionIDs, ";"),
+	}
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"encoding/json"
+	"movie-service/internal/model"
+	"slices"
+	"testing"
+)
+
+func TestMovieRecord(t *testing.T) {
+	movie := &model.Movie{
+		ID:            "6f1c3f9e-4d2a-4b8e-9c1d-2a3b4c5d6e7f",
+		Title:         "Crouching Tiger, Hidden Dragon",
+		Genre:         "Action",
+		Director:      "Ang Lee",
+		Year:          2000,
+		RatingSum:     17,
+		RatingCount:   2,
+		ReviewCount:   1,
+		Tags:          []string{"martial-arts", "wuxia"},
+		CollectionIDs: []string{"a", "b"},
+	}
+	record := NewMovieRecord(movie)
+
+	want := []string{
+		movie.ID, "Crouching Tiger, Hidden Dragon", "Action", "Ang Lee", "2000",
+		"8.5", "2", "1", "martial-arts;wuxia", "a;b",
+	}
+	if got := record.CSV(); !slices.Equal(got, want) {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+	if len(record.CSV()) != len(MovieRecordHeader) {
+		t.Errorf("CSV has %d fields, header has %d", len(record.CSV()), len(MovieRecordHeader))
+	}
+
+	// JSON keys are the same as CSV header
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	for _, column := range MovieRecordHeader {
+		if _, ok := fields[column]; !ok {
+			t.Errorf("JSON %s has no %q field", data, column)
+		}
+	}
+	if len(fields) != len(MovieRecordHeader) {
+		t.Errorf("JSON has %d fields, header has %d", len(fields), len(MovieRecordHeader))
+	}
+}
+
+func TestMovieRecordNotRated(t *testing.T) {
+	record := NewMovieRecord(&model.Movie{Title: "Heat", Year: 1995})
+
+	got := record.CSV()
+	if got[5] != "0" || got[8] != "" || got[9] != "" {
+		t.Errorf("CSV = %q, want zero rating and empty lists", got)
+	}
+}
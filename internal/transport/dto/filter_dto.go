@@ -0,0 +1,23 @@
+package dto
+
+import "movie-service/internal/model"
+
+type MovieFilter struct {
+	TagsAll  []string `validate:"max=20,unique,dive,required,max=64"`
+	TagsAny  []string `validate:"max=20,unique,dive,required,max=64"`
+	Genre    string   `validate:"max=256"`
+	Director string   `validate:"max=256"`
+	YearFrom uint32   `validate:"omitempty,gte=1911"`
+	YearTo   uint32   `validate:"omitempty,gte=1911,gtefield=YearFrom"`
+}
+
+func (f *MovieFilter) ToModel() *model.MovieFilter {
+	return &model.MovieFilter{
+		TagsAll:  f.TagsAll,
+		TagsAny:  f.TagsAny,
+		Genre:    f.Genre,
+		Director: f.Director,
+		YearFrom: f.YearFrom,
+		YearTo:   f.YearTo,
+	}
+}
@@ -0,0 +1,18 @@
+package dto
+
+const (
+	ImportContentTypeCSV   = "text/csv"
+	ImportContentTypeJSONL = "application/x-ndjson"
+)
+
+type ImportMoviesFileRequest struct {
+	ContentType string `validate:"required,oneof=text/csv application/x-ndjson"`
+	Data        []byte `validate:"required"`
+	DryRun      bool
+}
+
+// ImportRowError describes why the row of imported file is rejected
+type ImportRowError struct {
+	Line    uint32
+	Message string
+}
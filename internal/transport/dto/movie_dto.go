@@ -34,3 +34,17 @@ func (req *UpdateMovieRequest) ToModel() *model.Movie {
 		Year:     req.Year,
 	}
 }
+
+type RateMovieRequest struct {
+	MovieID string `validate:"required,uuid"`
+	UserID  string `validate:"required,max=128"`
+	Score   uint32 `validate:"required,gte=1,lte=10"`
+}
+
+func (req *RateMovieRequest) ToModel() *model.Rating {
+	return &model.Rating{
+		MovieID: req.MovieID,
+		UserID:  req.UserID,
+		Score:   req.Score,
+	}
+}
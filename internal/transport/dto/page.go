@@ -0,0 +1,29 @@
+package dto
+
+const (
+	defaultPageSize = 20
+)
+
+// Page describes requested page of a list. Pages are numbered from 1.
+type Page struct {
+	Page     uint32 `validate:"omitempty,gte=1"`
+	PageSize uint32 `validate:"omitempty,lte=100"`
+}
+
+// Limit returns max number of items on the page
+func (p *Page) Limit() uint64 {
+	if p.PageSize == 0 {
+		return defaultPageSize
+	}
+
+	return uint64(p.PageSize)
+}
+
+// Offset returns number of items on previous pages
+func (p *Page) Offset() uint64 {
+	if p.Page == 0 {
+		return 0
+	}
+
+	return uint64(p.Page-1) * p.Limit()
+}
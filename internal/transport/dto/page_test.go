@@ -0,0 +1,27 @@
+package dto
+
+import "testing"
+
+func TestPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       Page
+		wantLimit  uint64
+		wantOffset uint64
+	}{
+		{name: "defaults", page: Page{}, wantLimit: defaultPageSize, wantOffset: 0},
+		{name: "first page", page: Page{Page: 1, PageSize: 10}, wantLimit: 10, wantOffset: 0},
+		{name: "third page", page: Page{Page: 3, PageSize: 10}, wantLimit: 10, wantOffset: 20},
+		{name: "default size", page: Page{Page: 2}, wantLimit: defaultPageSize, wantOffset: defaultPageSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.Limit(); got != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", got, tt.wantLimit)
+			}
+			if got := tt.page.Offset(); got != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d", got, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type GetMovieOfTheDayRequest struct {
+	Date string `validate:"omitempty,datetime=2006-01-02"`
+	Seed string `validate:"max=256"`
+}
+
+// DateOrToday returns requested date or current UTC date if it's not set.
+// Date must be validated beforehand.
+func (req *GetMovieOfTheDayRequest) DateOrToday() time.Time {
+	if req.Date == "" {
+		return time.Now().UTC().Truncate(24 * time.Hour)
+	}
+
+	date, _ := time.Parse(time.DateOnly, req.Date)
+
+	return date
+}
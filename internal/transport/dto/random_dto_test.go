@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateOrToday(t *testing.T) {
+	req := &GetMovieOfTheDayRequest{Date: "2024-03-01"}
+	if got, want := req.DateOrToday(), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("DateOrToday = %v, want %v", got, want)
+	}
+
+	before := time.Now().UTC()
+	got := (&GetMovieOfTheDayRequest{}).DateOrToday()
+	after := time.Now().UTC()
+
+	// Today may change between calls of time.Now around midnight
+	if today := before.Truncate(24 * time.Hour); !got.Equal(today) && !got.Equal(after.Truncate(24*time.Hour)) {
+		t.Errorf("DateOrToday without date = %v, want %v", got, today)
+	}
+	if got.Location() != time.UTC {
+		t.Errorf("DateOrToday location = %v, want UTC", got.Location())
+	}
+}
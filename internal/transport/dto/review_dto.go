@@ -0,0 +1,46 @@
+package dto
+
+import "movie-service/internal/model"
+
+type CreateReviewRequest struct {
+	MovieID string `validate:"required,uuid"`
+	UserID  string `validate:"required,max=128"`
+	Text    string `validate:"required,max=10000"`
+}
+
+func (req *CreateReviewRequest) ToModel() *model.Review {
+	return &model.Review{
+		MovieID: req.MovieID,
+		UserID:  req.UserID,
+		Text:    req.Text,
+	}
+}
+
+type ListReviewsRequest struct {
+	MovieID string `validate:"required,uuid"`
+	Page
+}
+
+type UpdateReviewRequest struct {
+	ID     string `validate:"required,uuid"`
+	UserID string `validate:"required,max=128"`
+	Text   string `validate:"required,max=10000"`
+}
+
+func (req *UpdateReviewRequest) ToModel() *model.Review {
+	return &model.Review{
+		ID:     req.ID,
+		UserID: req.UserID,
+		Text:   req.Text,
+	}
+}
+
+type DeleteReviewRequest struct {
+	ID     string `validate:"required,uuid"`
+	UserID string `validate:"required,max=128"`
+}
+
+type ModerateReviewRequest struct {
+	ID     string             `validate:"required,uuid"`
+	Status model.ReviewStatus `validate:"required,oneof=pending approved rejected"`
+}
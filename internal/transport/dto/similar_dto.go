@@ -0,0 +1,19 @@
+package dto
+
+const (
+	defaultSimilarLimit = 10
+)
+
+type GetSimilarMoviesRequest struct {
+	MovieID string `validate:"required,uuid"`
+	Limit   uint32 `validate:"lte=100"`
+}
+
+// LimitOrDefault returns max number of similar movies to return
+func (req *GetSimilarMoviesRequest) LimitOrDefault() uint64 {
+	if req.Limit == 0 {
+		return defaultSimilarLimit
+	}
+
+	return uint64(req.Limit)
+}
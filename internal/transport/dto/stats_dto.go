@@ -0,0 +1,36 @@
+package dto
+
+import "movie-service/internal/model"
+
+const (
+	defaultTopLimit   = 10
+	defaultYearBucket = 10
+)
+
+type GetCatalogStatsRequest struct {
+	Filter     MovieFilter
+	Facets     []model.Facet `validate:"unique,dive,oneof=genre director tag year rating"`
+	TopLimit   uint32        `validate:"lte=100"`
+	YearBucket uint32        `validate:"lte=100"`
+}
+
+func (req *GetCatalogStatsRequest) ToModel() *model.CatalogStatsQuery {
+	query := &model.CatalogStatsQuery{
+		Filter:     *req.Filter.ToModel(),
+		Facets:     req.Facets,
+		TopLimit:   uint64(req.TopLimit),
+		YearBucket: req.YearBucket,
+	}
+
+	if len(query.Facets) == 0 {
+		query.Facets = model.AllFacets
+	}
+	if query.TopLimit == 0 {
+		query.TopLimit = defaultTopLimit
+	}
+	if query.YearBucket == 0 {
+		query.YearBucket = defaultYearBucket
+	}
+
+	return query
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"movie-service/internal/model"
+	"slices"
+	"testing"
+)
+
+func TestGetCatalogStatsToModel(t *testing.T) {
+	tests := []struct {
+		name           string
+		req            GetCatalogStatsRequest
+		wantFacets     []model.Facet
+		wantTopLimit   uint64
+		wantYearBucket uint32
+	}{
+		{
+			name:           "defaults",
+			req:            GetCatalogStatsRequest{},
+			wantFacets:     model.AllFacets,
+			wantTopLimit:   defaultTopLimit,
+			wantYearBucket: defaultYearBucket,
+		},
+		{
+			name:           "explicit",
+			req:            GetCatalogStatsRequest{Facets: []model.Facet{model.FacetYear}, TopLimit: 3, YearBucket: 5},
+			wantFacets:     []model.Facet{model.FacetYear},
+			wantTopLimit:   3,
+			wantYearBucket: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.req.ToModel()
+			if !slices.Equal(query.Facets, tt.wantFacets) {
+				t.Errorf("Facets = %v, want %v", query.Facets, tt.wantFacets)
+			}
+			if query.TopLimit != tt.wantTopLimit || query.YearBucket != tt.wantYearBucket {
+				t.Errorf("TopLimit, YearBucket = %d, %d, want %d, %d",
+					query.TopLimit, query.YearBucket, tt.wantTopLimit, tt.wantYearBucket)
+			}
+		})
+	}
+}
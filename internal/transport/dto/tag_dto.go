@@ -0,0 +1,50 @@
+package dto
+
+import "strings"
+
+const (
+	defaultTagsLimit = 50
+)
+
+type ListMoviesRequest struct {
+	Filter MovieFilter
+	Page
+}
+
+type TagsRequest struct {
+	MovieID string   `validate:"required,uuid"`
+	Tags    []string `validate:"required,min=1,max=50,unique,dive,required,max=64"`
+}
+
+type ListTagsRequest struct {
+	Prefix string `validate:"max=64"`
+	Limit  uint32 `validate:"lte=1000"`
+}
+
+// LimitOrDefault returns max number of tags to list
+func (req *ListTagsRequest) LimitOrDefault() uint64 {
+	if req.Limit == 0 {
+		return defaultTagsLimit
+	}
+
+	return uint64(req.Limit)
+}
+
+// NormalizeTags brings tags to canonical form: lower case with words
+// separated by hyphens, e.g. "Cult Classic" becomes "cult-classic"
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		normalized = append(normalized, NormalizeTag(tag))
+	}
+
+	return normalized
+}
+
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
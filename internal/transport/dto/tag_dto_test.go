@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "nil", tags: nil, want: nil},
+		{name: "empty", tags: []string{}, want: []string{}},
+		{name: "words", tags: []string{"Cult Classic", "noir"}, want: []string{"cult-classic", "noir"}},
+		{name: "whitespace", tags: []string{"  Based \t on   Book "}, want: []string{"based-on-book"}},
+		{name: "blank", tags: []string{"   "}, want: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeTags(tt.tags)
+			if (got == nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("NormalizeTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListTagsLimitOrDefault(t *testing.T) {
+	tests := []struct {
+		limit uint32
+		want  uint64
+	}{
+		{limit: 0, want: defaultTagsLimit},
+		{limit: 1, want: 1},
+		{limit: 1000, want: 1000},
+	}
+	for _, tt := range tests {
+		req := &ListTagsRequest{Limit: tt.limit}
+		if got := req.LimitOrDefault(); got != tt.want {
+			t.Errorf("LimitOrDefault with limit %d = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"movie-service/internal/model"
+	"time"
+)
+
+type WatchlistRequest struct {
+	UserID  string `validate:"required,max=128"`
+	MovieID string `validate:"required,uuid"`
+}
+
+type ListByUserRequest struct {
+	UserID string `validate:"required,max=128"`
+	Page
+}
+
+type RecordWatchRequest struct {
+	UserID    string `validate:"required,max=128"`
+	MovieID   string `validate:"required,uuid"`
+	WatchedAt time.Time
+	Progress  uint32 `validate:"lte=100"`
+}
+
+func (req *RecordWatchRequest) ToModel() *model.WatchHistoryEntry {
+	return &model.WatchHistoryEntry{
+		UserID:    req.UserID,
+		MovieID:   req.MovieID,
+		WatchedAt: req.WatchedAt,
+		Progress:  req.Progress,
+	}
+}
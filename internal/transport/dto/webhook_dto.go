@@ -0,0 +1,28 @@
+package dto
+
+import "movie-service/internal/model"
+
+type CreateWebhookRequest struct {
+	URL        string                 `validate:"required,http_url,max=2048"`
+	Secret     string                 `validate:"required,min=16,max=256"`
+	EventTypes []model.MovieEventType `validate:"unique,dive,oneof=created updated deleted"`
+}
+
+func (req *CreateWebhookRequest) ToModel() *model.Webhook {
+	return &model.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	}
+}
+
+type ListDeliveriesRequest struct {
+	WebhookID string               `validate:"required,uuid"`
+	Status    model.DeliveryStatus `validate:"omitempty,oneof=pending delivered dead"`
+	Page
+}
+
+type RedeliverEventRequest struct {
+	WebhookID string `validate:"required,uuid"`
+	EventSeq  uint64 `validate:"required"`
+}
@@ -0,0 +1,245 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	repo "movie-service/internal/repository"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (srv *server) CreateCollection(ctx context.Context, in *pb.CreateCollectionRequest) (*pb.CreateCollectionResponse, error) {
+	const op = "transport.grpc.CreateCollection"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	collection := pbToCreateCollection(in)
+	log.Debug("Converted CreateCollectionRequest to dto", slog.Any("Request", collection))
+
+	// Create request validation
+	log.Debug("Validating CreateCollectionRequest")
+	if err := srv.validate.Struct(collection); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Save collection through the service layer
+	log.Debug("Creating collection")
+	newCollection, err := srv.service.CreateCollection(ctx, collection.ToModel())
+	if err != nil {
+		log.Error("Failed to create collection", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to add collection")
+	}
+
+	log.Debug("Successfully created collection", slog.String("collection_id", newCollection.ID))
+
+	return &pb.CreateCollectionResponse{Collection: collectionToPb(newCollection)}, nil
+}
+
+func (srv *server) GetCollection(ctx context.Context, in *pb.GetCollectionRequest) (*pb.GetCollectionResponse, error) {
+	const op = "transport.grpc.GetCollection"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	id := in.GetId()
+	log.Debug("Got collection ID", slog.String("ID", id))
+
+	// Check whether it's valid uuid
+	log.Debug("Validating collection ID")
+	if err := srv.validate.Var(id, "uuid"); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Get collection through the service layer
+	log.Debug("Getting collection by ID")
+	collection, err := srv.service.GetCollection(ctx, id)
+	if err != nil {
+		log.Error("Failed to get collection", sl.Err(err))
+
+		if errors.Is(err, repo.ErrCollectionNotExists) {
+			return nil, status.Error(codes.NotFound, "collection not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get collection")
+	}
+
+	log.Debug("Successfully found collection", slog.Any("Collection", collection))
+
+	return &pb.GetCollectionResponse{Collection: collectionToPb(collection)}, nil
+}
+
+func (srv *server) UpdateCollection(ctx context.Context, in *pb.UpdateCollectionRequest) (*pb.UpdateCollectionResponse, error) {
+	const op = "transport.grpc.UpdateCollection"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	collection := pbToUpdateCollection(in)
+	log.Debug("Converted UpdateCollectionRequest to dto", slog.Any("Request", collection))
+
+	// Update request validation
+	log.Debug("Validating UpdateCollectionRequest")
+	if err := srv.validate.Struct(collection); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Update collection through the service layer
+	log.Debug("Updating collection")
+	newCollection, err := srv.service.UpdateCollection(ctx, collection.ToModel())
+	if err != nil {
+		log.Error("Failed to update collection", sl.Err(err))
+
+		if errors.Is(err, repo.ErrCollectionNotExists) {
+			return nil, status.Error(codes.NotFound, "collection not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to update collection")
+	}
+
+	log.Debug("Successfully updated collection", slog.Any("Collection", newCollection))
+
+	return &pb.UpdateCollectionResponse{Collection: collectionToPb(newCollection)}, nil
+}
+
+func (srv *server) SetCollectionMovies(ctx context.Context, in *pb.SetCollectionMoviesRequest) (*pb.SetCollectionMoviesResponse, error) {
+	const op = "transport.grpc.SetCollectionMovies"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := pbToSetCollectionMovies(in)
+	log.Debug("Converted SetCollectionMoviesRequest to dto", slog.Any("Request", req))
+
+	// Set request validation
+	log.Debug("Validating SetCollectionMoviesRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Replace movies of collection through the service layer
+	log.Debug("Setting movies of collection")
+	collection, err := srv.service.SetCollectionMovies(ctx, req.ID, req.MovieIDs)
+	if err != nil {
+		log.Error("Failed to set movies of collection", sl.Err(err))
+
+		if errors.Is(err, repo.ErrCollectionNotExists) {
+			return nil, status.Error(codes.NotFound, "collection not found")
+		}
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to set movies of collection")
+	}
+
+	log.Debug("Successfully set movies of collection", slog.Any("Collection", collection))
+
+	return &pb.SetCollectionMoviesResponse{Collection: collectionToPb(collection)}, nil
+}
+
+func (srv *server) DeleteCollection(ctx context.Context, in *pb.DeleteCollectionRequest) (*pb.DeleteCollectionResponse, error) {
+	const op = "transport.grpc.DeleteCollection"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	id := in.GetId()
+	log.Debug("Got collection ID", slog.String("ID", id))
+
+	// Check whether it's valid uuid
+	log.Debug("Validating collection ID")
+	if err := srv.validate.Var(id, "uuid"); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Delete collection through the service layer
+	log.Debug("Deleting collection by ID")
+	ok, err := srv.service.DeleteCollection(ctx, id)
+	if err != nil {
+		log.Error("Failed to delete collection", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to delete collection")
+	}
+
+	if ok {
+		log.Debug("Successfully deleted collection")
+	} else {
+		log.Debug("No collection with this ID was found", slog.String("ID", id))
+	}
+
+	return &pb.DeleteCollectionResponse{Success: ok}, nil
+}
+
+func (srv *server) ListCollectionMovies(in *pb.ListCollectionMoviesRequest, stream pb.MovieService_ListCollectionMoviesServer) error {
+	const op = "transport.grpc.ListCollectionMovies"
+	ctx := stream.Context()
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	id := in.GetId()
+	log.Debug("Got collection ID", slog.String("ID", id))
+
+	// Check whether it's valid uuid
+	log.Debug("Validating collection ID")
+	if err := srv.validate.Var(id, "uuid"); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	log.Debug("Getting movies of collection")
+	movies, err := srv.service.GetCollectionMovies(ctx, id)
+	if err != nil {
+		log.Error("Failed to get movies of collection", sl.Err(err))
+
+		if errors.Is(err, repo.ErrCollectionNotExists) {
+			return status.Error(codes.NotFound, "collection not found")
+		}
+
+		return status.Error(codes.Internal, "failed to get movies of collection")
+	}
+
+	log.Debug("Starting stream...")
+	for _, movie := range movies {
+		if err := stream.Send(&pb.GetMovieResponse{Movie: toPb(&movie)}); err != nil {
+			log.Error("Error during streaming movies of collection")
+			return err
+		}
+	}
+	log.Debug("Finished stream")
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package moviegrpc
+
+import (
+	"context"
+	"movie-service/pkg/pb"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateCollection(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	alien := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	aliens := createMovie(t, srv, "Aliens", "Sci-Fi", "James Cameron", 1986)
+
+	const unknown = "00000000-0000-0000-0000-000000000000"
+	franchise := pb.CollectionKind_COLLECTION_KIND_FRANCHISE
+
+	tests := []struct {
+		name     string
+		req      *pb.CreateCollectionRequest
+		wantCode codes.Code
+	}{
+		{name: "ordered movies", req: &pb.CreateCollectionRequest{Name: "Alien", Kind: franchise, MovieIds: []string{aliens, alien}}},
+		{name: "empty", req: &pb.CreateCollectionRequest{Name: "Favourites", Kind: pb.CollectionKind_COLLECTION_KIND_CURATED}},
+		{name: "missing name", req: &pb.CreateCollectionRequest{Kind: franchise}, wantCode: codes.InvalidArgument},
+		{name: "unspecified kind", req: &pb.CreateCollectionRequest{Name: "Alien"}, wantCode: codes.InvalidArgument},
+		{name: "duplicate movies", req: &pb.CreateCollectionRequest{Name: "Alien", Kind: franchise, MovieIds: []string{alien, alien}}, wantCode: codes.InvalidArgument},
+		{name: "invalid movie id", req: &pb.CreateCollectionRequest{Name: "Alien", Kind: franchise, MovieIds: []string{"alien"}}, wantCode: codes.InvalidArgument},
+		{name: "unknown movie", req: &pb.CreateCollectionRequest{Name: "Alien", Kind: franchise, MovieIds: []string{alien, unknown}}, wantCode: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.CreateCollection(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("CreateCollection code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			collection := resp.GetCollection()
+			if collection.GetId() == "" || collection.GetKind() != tt.req.GetKind() {
+				t.Errorf("CreateCollection = %v, want collection of kind %v with ID", collection, tt.req.GetKind())
+			}
+			if !slices.Equal(collection.GetMovieIds(), tt.req.GetMovieIds()) {
+				t.Errorf("MovieIds = %v, want %v", collection.GetMovieIds(), tt.req.GetMovieIds())
+			}
+		})
+	}
+}
+
+func TestCollectionMovies(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	alien := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	aliens := createMovie(t, srv, "Aliens", "Sci-Fi", "James Cameron", 1986)
+	alien3 := createMovie(t, srv, "Alien 3", "Sci-Fi", "David Fincher", 1992)
+
+	created, err := srv.CreateCollection(ctx, &pb.CreateCollectionRequest{
+		Name:     "Alien",
+		Kind:     pb.CollectionKind_COLLECTION_KIND_FRANCHISE,
+		MovieIds: []string{alien, aliens},
+	})
+	if err != nil {
+		t.Fatalf("CreateCollection error = %v", err)
+	}
+	id := created.GetCollection().GetId()
+
+	// Movies are replaced as a whole and keep the new order
+	set, err := srv.SetCollectionMovies(ctx, &pb.SetCollectionMoviesRequest{Id: id, MovieIds: []string{alien3, alien}})
+	if err != nil {
+		t.Fatalf("SetCollectionMovies error = %v", err)
+	}
+	if want := []string{alien3, alien}; !slices.Equal(set.GetCollection().GetMovieIds(), want) {
+		t.Errorf("MovieIds = %v, want %v", set.GetCollection().GetMovieIds(), want)
+	}
+
+	stream := &fakeStream[pb.GetMovieResponse]{ctx: ctx}
+	if err := srv.ListCollectionMovies(&pb.ListCollectionMoviesRequest{Id: id}, stream); err != nil {
+		t.Fatalf("ListCollectionMovies error = %v", err)
+	}
+	var titles []string
+	for _, resp := range stream.sent {
+		titles = append(titles, resp.GetMovie().GetTitle())
+		if !slices.Contains(resp.GetMovie().GetCollectionIds(), id) {
+			t.Errorf("CollectionIds of %s = %v, want %s", resp.GetMovie().GetTitle(), resp.GetMovie().GetCollectionIds(), id)
+		}
+	}
+	if want := []string{"Alien 3", "Alien"}; !slices.Equal(titles, want) {
+		t.Errorf("ListCollectionMovies = %v, want %v", titles, want)
+	}
+
+	// Partial update keeps fields which are not set
+	updated, err := srv.UpdateCollection(ctx, &pb.UpdateCollectionRequest{Id: id, Description: "Xenomorphs"})
+	if err != nil {
+		t.Fatalf("UpdateCollection error = %v", err)
+	}
+	if c := updated.GetCollection(); c.GetName() != "Alien" || c.GetDescription() != "Xenomorphs" || c.GetKind() != pb.CollectionKind_COLLECTION_KIND_FRANCHISE {
+		t.Errorf("UpdateCollection = %v, want name and kind kept", c)
+	}
+
+	const unknown = "00000000-0000-0000-0000-000000000000"
+	if _, err := srv.SetCollectionMovies(ctx, &pb.SetCollectionMoviesRequest{Id: unknown}); status.Code(err) != codes.NotFound {
+		t.Errorf("SetCollectionMovies of unknown collection code = %v, want %v", status.Code(err), codes.NotFound)
+	}
+	if _, err := srv.SetCollectionMovies(ctx, &pb.SetCollectionMoviesRequest{Id: id, MovieIds: []string{unknown}}); status.Code(err) != codes.NotFound {
+		t.Errorf("SetCollectionMovies with unknown movie code = %v, want %v", status.Code(err), codes.NotFound)
+	}
+
+	deleted, err := srv.DeleteCollection(ctx, &pb.DeleteCollectionRequest{Id: id})
+	if err != nil || !deleted.GetSuccess() {
+		t.Fatalf("DeleteCollection = %v, %v, want success", deleted, err)
+	}
+	err = srv.ListCollectionMovies(&pb.ListCollectionMoviesRequest{Id: id}, &fakeStream[pb.GetMovieResponse]{ctx: ctx})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("ListCollectionMovies of deleted collection code = %v, want %v", status.Code(err), codes.NotFound)
+	}
+}
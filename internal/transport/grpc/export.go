@@ -0,0 +1,115 @@
+package moviegrpc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"movie-service/internal/model"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var exportContentTypes = map[string]string{
+	dto.ExportFormatCSV:   "text/csv; charset=utf-8",
+	dto.ExportFormatJSONL: "application/x-ndjson",
+}
+
+// ExportMovies streams movies as CSV or JSON Lines. Every chunk holds whole
+// lines separated by line feed without trailing one, since gateway puts line
+// feed after every chunk of the stream.
+func (srv *server) ExportMovies(in *pb.ExportMoviesRequest, stream pb.MovieService_ExportMoviesServer) error {
+	const op = "transport.grpc.ExportMovies"
+	ctx := stream.Context()
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.ExportMoviesRequest{Format: in.GetFormat(), Filter: pbToFilter(in.GetFilter())}
+	log.Debug("Converted ExportMoviesRequest to dto", slog.Any("ExportMoviesRequest", req))
+
+	// Add request validation
+	log.Debug("Validating ExportMoviesRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	format := req.Format
+	if format == "" {
+		format = dto.ExportFormatCSV
+	}
+	contentType := exportContentTypes[format]
+
+	var buf bytes.Buffer
+	var sendErr error
+	send := func() error {
+		// Drop trailing line feed, gateway adds it
+		data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		sendErr = stream.Send(&httpbody.HttpBody{ContentType: contentType, Data: data})
+		buf.Reset()
+
+		return sendErr
+	}
+
+	csvWriter := csv.NewWriter(&buf)
+	if format == dto.ExportFormatCSV {
+		if err := csvWriter.Write(dto.MovieRecordHeader); err != nil {
+			return status.Error(codes.Internal, "failed to export movies")
+		}
+		csvWriter.Flush()
+
+		if err := send(); err != nil {
+			log.Error("Error during streaming export", sl.Err(err))
+			return err
+		}
+	}
+
+	log.Debug("Starting stream...")
+	count := 0
+	err := srv.service.ExportMovies(ctx, req.Filter.ToModel(), func(movies []model.Movie) error {
+		for i := range movies {
+			record := dto.NewMovieRecord(&movies[i])
+
+			switch format {
+			case dto.ExportFormatCSV:
+				if err := csvWriter.Write(record.CSV()); err != nil {
+					return fmt.Errorf("failed to encode movie: %w", err)
+				}
+			case dto.ExportFormatJSONL:
+				line, err := json.Marshal(record)
+				if err != nil {
+					return fmt.Errorf("failed to encode movie: %w", err)
+				}
+				buf.Write(line)
+				buf.WriteByte('\n')
+			}
+		}
+		csvWriter.Flush()
+		count += len(movies)
+
+		return send()
+	})
+	if err != nil {
+		if sendErr != nil {
+			log.Error("Error during streaming export", sl.Err(sendErr))
+			return sendErr
+		}
+
+		log.Error("Failed to export movies", sl.Err(err))
+
+		return status.Error(codes.Internal, "failed to export movies")
+	}
+	log.Debug("Finished stream", slog.Int("count", count))
+
+	return nil
+}
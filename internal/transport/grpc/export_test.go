@@ -0,0 +1,107 @@
+package moviegrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"slices"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportBody joins chunks of export the same way as gateway does, with line
+// feed after every chunk
+func exportBody(t *testing.T, stream *fakeStream[httpbody.HttpBody], contentType string) []byte {
+	t.Helper()
+
+	var body bytes.Buffer
+	for _, chunk := range stream.sent {
+		if chunk.GetContentType() != contentType {
+			t.Errorf("ContentType = %q, want %q", chunk.GetContentType(), contentType)
+		}
+		if bytes.HasSuffix(chunk.GetData(), []byte("\n")) {
+			t.Errorf("chunk %q ends with line feed", chunk.GetData())
+		}
+		body.Write(chunk.GetData())
+		body.WriteByte('\n')
+	}
+
+	return body.Bytes()
+}
+
+func TestExportMoviesCSV(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	tiger := createMovie(t, srv, `Crouching Tiger, "Hidden" Dragon`, "Action", "Ang Lee", 2000)
+	createMovie(t, srv, "Heat", "Crime", "Michael Mann", 1995)
+
+	if _, err := srv.AddTags(ctx, &pb.AddTagsRequest{MovieId: tiger, Tags: []string{"wuxia", "martial arts"}}); err != nil {
+		t.Fatalf("AddTags error = %v", err)
+	}
+
+	stream := &fakeStream[httpbody.HttpBody]{ctx: ctx}
+	if err := srv.ExportMovies(&pb.ExportMoviesRequest{Filter: &pb.MovieFilter{Genre: "action"}}, stream); err != nil {
+		t.Fatalf("ExportMovies error = %v", err)
+	}
+
+	// CSV is the default format, quoting survives joining of chunks
+	records, err := csv.NewReader(bytes.NewReader(exportBody(t, stream, "text/csv; charset=utf-8"))).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want header and single movie", len(records))
+	}
+	if !slices.Equal(records[0], dto.MovieRecordHeader) {
+		t.Errorf("header = %q, want %q", records[0], dto.MovieRecordHeader)
+	}
+
+	want := []string{tiger, `Crouching Tiger, "Hidden" Dragon`, "Action", "Ang Lee", "2000", "0", "0", "0", "martial-arts;wuxia", ""}
+	if !slices.Equal(records[1], want) {
+		t.Errorf("record = %q, want %q", records[1], want)
+	}
+}
+
+func TestExportMoviesJSONL(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	alien := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	if _, err := srv.RateMovie(ctx, &pb.RateMovieRequest{MovieId: alien, UserId: "alice", Score: 9}); err != nil {
+		t.Fatalf("RateMovie error = %v", err)
+	}
+
+	stream := &fakeStream[httpbody.HttpBody]{ctx: ctx}
+	if err := srv.ExportMovies(&pb.ExportMoviesRequest{Format: dto.ExportFormatJSONL}, stream); err != nil {
+		t.Fatalf("ExportMovies error = %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(exportBody(t, stream, "application/x-ndjson"), []byte("\n")), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want single movie", len(lines))
+	}
+
+	var record dto.MovieRecord
+	if err := json.Unmarshal(lines[0], &record); err != nil {
+		t.Fatalf("failed to decode %s: %v", lines[0], err)
+	}
+	if record.ID != alien || record.Title != "Alien" || record.AverageRating != 9 || record.RatingCount != 1 {
+		t.Errorf("record = %+v, want rated Alien", record)
+	}
+}
+
+func TestExportMoviesInvalidFormat(t *testing.T) {
+	stream := &fakeStream[httpbody.HttpBody]{ctx: context.Background()}
+	err := newMemoryServer().ExportMovies(&pb.ExportMoviesRequest{Format: "xml"}, stream)
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Errorf("ExportMovies code = %v, want %v", got, codes.InvalidArgument)
+	}
+	if len(stream.sent) != 0 {
+		t.Errorf("sent %d chunks, want none", len(stream.sent))
+	}
+}
@@ -0,0 +1,50 @@
+package moviegrpc
+
+import (
+	"context"
+	"log/slog"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (srv *server) GetFilmography(ctx context.Context, in *pb.GetFilmographyRequest) (*pb.GetFilmographyResponse, error) {
+	const op = "transport.grpc.GetFilmography"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	director := strings.TrimSpace(in.GetDirector())
+	log.Debug("Got director", slog.String("director", director))
+
+	// Check whether director name is present
+	log.Debug("Validating director")
+	if err := srv.validate.Var(director, "required,max=256"); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Get movies of director through the service layer
+	log.Debug("Getting filmography")
+	movies, err := srv.service.GetFilmography(ctx, director)
+	if err != nil {
+		log.Error("Failed to get filmography", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to get filmography")
+	}
+
+	log.Debug("Successfully got filmography", slog.Int("count", len(movies)))
+
+	resp := &pb.GetFilmographyResponse{Movies: make([]*pb.Movie, 0, len(movies))}
+	for _, movie := range movies {
+		resp.Movies = append(resp.Movies, toPb(&movie))
+	}
+
+	return resp, nil
+}
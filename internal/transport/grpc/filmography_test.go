@@ -0,0 +1,52 @@
+package moviegrpc
+
+import (
+	"context"
+	"movie-service/pkg/pb"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetFilmography(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	createMovie(t, srv, "Volver", "Drama", "Pedro Almodóvar", 2006)
+	createMovie(t, srv, "Talk to Her", "Drama", "pedro  almodovar", 2002)
+	createMovie(t, srv, "Heat", "Crime", "Michael Mann", 1995)
+
+	tests := []struct {
+		name     string
+		director string
+		want     []string
+		wantCode codes.Code
+	}{
+		{name: "diacritics and case are ignored", director: " PEDRO ALMODOVAR ", want: []string{"Talk to Her", "Volver"}},
+		{name: "exact name", director: "Michael Mann", want: []string{"Heat"}},
+		{name: "unknown director", director: "Nobody", want: []string{}},
+		{name: "blank director", director: "  ", wantCode: codes.InvalidArgument},
+		{name: "director too long", director: strings.Repeat("a", 257), wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.GetFilmography(ctx, &pb.GetFilmographyRequest{Director: tt.director})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("GetFilmography code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			got := make([]string, 0, len(resp.GetMovies()))
+			for _, movie := range resp.GetMovies() {
+				got = append(got, movie.GetTitle())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetFilmography titles = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package moviegrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"movie-service/internal/model"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxImportErrors is max number of row errors reported back
+	maxImportErrors = 100
+
+	// maxImportLine is max length of JSON Lines row
+	maxImportLine = 1 << 20
+)
+
+// importRow is a parsed row of imported file. Err is set if row can't be parsed.
+type importRow struct {
+	line uint32
+	req  dto.CreateMovieRequest
+	err  error
+}
+
+// ImportMoviesFile creates movies from CSV or JSON Lines file. Every row is validated
+// as CreateMovieRequest and movies are created only if all rows are valid.
+func (srv *server) ImportMoviesFile(ctx context.Context, in *pb.ImportMoviesFileRequest) (*pb.ImportMoviesFileResponse, error) {
+	const op = "transport.grpc.ImportMoviesFile"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.ImportMoviesFileRequest{
+		ContentType: in.GetFile().GetContentType(),
+		Data:        in.GetFile().GetData(),
+		DryRun:      in.GetDryRun(),
+	}
+	if mediaType, _, err := mime.ParseMediaType(req.ContentType); err == nil {
+		req.ContentType = mediaType
+	}
+	log.Debug("Got file for import",
+		slog.String("ContentType", req.ContentType),
+		slog.Int("size", len(req.Data)),
+		slog.Bool("DryRun", req.DryRun),
+	)
+
+	// Add request validation
+	log.Debug("Validating ImportMoviesFileRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Parse the file
+	var rows []importRow
+	var err error
+	switch req.ContentType {
+	case dto.ImportContentTypeCSV:
+		rows, err = parseCSVRows(req.Data)
+	case dto.ImportContentTypeJSONL:
+		rows, err = parseJSONLRows(req.Data)
+	}
+	if err != nil {
+		log.Error("Failed to parse file", sl.Err(err))
+
+		return nil, status.Errorf(codes.InvalidArgument, "invalid file: %v", err)
+	}
+
+	// Validate every row
+	movies := make([]model.Movie, 0, len(rows))
+	rowErrors := make([]dto.ImportRowError, 0)
+	for _, row := range rows {
+		err := row.err
+		if err == nil {
+			err = srv.validate.Struct(&row.req)
+		}
+
+		if err != nil {
+			rowErrors = append(rowErrors, dto.ImportRowError{Line: row.line, Message: importErrorMessage(err)})
+			continue
+		}
+
+		movies = append(movies, *row.req.ToModel())
+	}
+	log.Debug("Validated rows", slog.Int("total", len(rows)), slog.Int("invalid", len(rowErrors)))
+
+	resp := &pb.ImportMoviesFileResponse{
+		TotalRows: uint32(len(rows)),
+		ValidRows: uint32(len(movies)),
+		Errors:    make([]*pb.ImportRowError, 0, min(len(rowErrors), maxImportErrors)),
+	}
+	for _, rowErr := range rowErrors[:min(len(rowErrors), maxImportErrors)] {
+		resp.Errors = append(resp.Errors, &pb.ImportRowError{Line: rowErr.Line, Message: rowErr.Message})
+	}
+
+	if len(rowErrors) > 0 || req.DryRun || len(movies) == 0 {
+		return resp, nil
+	}
+
+	// Create movies through the service layer
+	log.Debug("Creating movies", slog.Int("count", len(movies)))
+	ids, err := srv.service.CreateMovies(ctx, movies)
+	if err != nil {
+		log.Error("Failed to create movies", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to import movies")
+	}
+	resp.Ids = ids
+
+	log.Debug("Successfully imported movies", slog.Int("count", len(ids)))
+
+	return resp, nil
+}
+
+// parseCSVRows parses CSV file with header. Columns are matched by name
+// ignoring case, unknown columns are skipped.
+func parseCSVRows(data []byte) ([]importRow, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("header is missing")
+		}
+
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"title", "genre", "director", "year"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("column %q is missing", name)
+		}
+	}
+
+	rows := make([]importRow, 0)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		var row importRow
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("failed to read row: %w", err)
+			}
+
+			row.line, row.err = uint32(parseErr.StartLine), parseErr.Err
+			rows = append(rows, row)
+			continue
+		}
+
+		line, _ := r.FieldPos(0)
+		row.line = uint32(line)
+
+		if len(record) != len(header) {
+			row.err = fmt.Errorf("expected %d fields, got %d", len(header), len(record))
+			rows = append(rows, row)
+			continue
+		}
+
+		row.req = dto.CreateMovieRequest{
+			Title:    record[columns["title"]],
+			Genre:    record[columns["genre"]],
+			Director: record[columns["director"]],
+		}
+		if year := strings.TrimSpace(record[columns["year"]]); year != "" {
+			parsed, err := strconv.ParseUint(year, 10, 32)
+			if err != nil {
+				row.err = fmt.Errorf("year: %q is not a valid year", year)
+			}
+			row.req.Year = uint32(parsed)
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// parseJSONLRows parses JSON Lines file. Empty lines are skipped,
+// unknown fields are ignored.
+func parseJSONLRows(data []byte) ([]importRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportLine)
+
+	rows := make([]importRow, 0)
+	var line uint32
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var record struct {
+			Title    string `json:"title"`
+			Genre    string `json:"genre"`
+			Director string `json:"director"`
+			Year     uint32 `json:"year"`
+		}
+		row := importRow{line: line}
+		if err := json.Unmarshal(text, &record); err != nil {
+			row.err = err
+		}
+		row.req = dto.CreateMovieRequest{
+			Title:    record.Title,
+			Genre:    record.Genre,
+			Director: record.Director,
+			Year:     record.Year,
+		}
+
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read line %d: %w", line+1, err)
+	}
+
+	return rows, nil
+}
+
+// importErrorMessage describes error of the row for the client
+func importErrorMessage(err error) string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err.Error()
+	}
+
+	msgs := make([]string, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		msg := fmt.Sprintf("%s: failed on %q", strings.ToLower(fieldErr.Field()), fieldErr.Tag())
+		if fieldErr.Param() != "" {
+			msg += " " + fieldErr.Param()
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,261 @@
+package moviegrpc
+
+import (
+	"context"
+	"fmt"
+	"movie-service/internal/model"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseCSVRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []importRow
+		wantErr string
+	}{
+		{
+			name: "columns are matched by name",
+			data: "Year, DIRECTOR ,rating,title,genre\n1979,Ridley Scott,8.5,Alien,Sci-Fi\n",
+			want: []importRow{
+				{line: 2, req: dto.CreateMovieRequest{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979}},
+			},
+		},
+		{
+			name: "quoted fields",
+			data: "title,genre,director,year\n\"Alien, Director's Cut\",Sci-Fi,Ridley Scott,2003\n",
+			want: []importRow{
+				{line: 2, req: dto.CreateMovieRequest{Title: "Alien, Director's Cut", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 2003}},
+			},
+		},
+		{
+			name: "empty year is left for validation",
+			data: "title,genre,director,year\nAlien,Sci-Fi,Ridley Scott,\n",
+			want: []importRow{
+				{line: 2, req: dto.CreateMovieRequest{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott"}},
+			},
+		},
+		{name: "empty file", data: "", wantErr: "header is missing"},
+		{name: "missing column", data: "title,genre,year\nAlien,Sci-Fi,1979\n", wantErr: `column "director" is missing`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseCSVRows([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseCSVRows error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCSVRows error = %v", err)
+			}
+
+			if !slices.Equal(rows, tt.want) {
+				t.Errorf("parseCSVRows = %+v, want %+v", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVRowErrors(t *testing.T) {
+	data := "title,genre,director,year\n" +
+		"Alien,Sci-Fi,Ridley Scott,1979\n" +
+		"Aliens,Sci-Fi\n" +
+		"Alien 3,Sci-Fi,David Fincher,nineteen\n" +
+		"\"Alien\" Resurrection,Sci-Fi,Jean-Pierre Jeunet,1997\n"
+
+	rows, err := parseCSVRows([]byte(data))
+	if err != nil {
+		t.Fatalf("parseCSVRows error = %v", err)
+	}
+
+	wantErrs := []string{"", "expected 4 fields, got 2", `year: "nineteen" is not a valid year`, "extraneous or missing \" in quoted-field"}
+	if len(rows) != len(wantErrs) {
+		t.Fatalf("parseCSVRows returned %d rows, want %d", len(rows), len(wantErrs))
+	}
+	for i, row := range rows {
+		if want := uint32(i + 2); row.line != want {
+			t.Errorf("line of row %d = %d, want %d", i, row.line, want)
+		}
+
+		got := ""
+		if row.err != nil {
+			got = row.err.Error()
+		}
+		if got != wantErrs[i] {
+			t.Errorf("error of row %d = %q, want %q", i, got, wantErrs[i])
+		}
+	}
+}
+
+func TestParseJSONLRows(t *testing.T) {
+	data := `{"title":"Alien","genre":"Sci-Fi","director":"Ridley Scott","year":1979,"rating":8.5}` + "\n" +
+		"\n" +
+		`{"title":"Aliens",` + "\n" +
+		`  {"title":"Alien 3","genre":"Sci-Fi","director":"David Fincher","year":1992}  `
+
+	rows, err := parseJSONLRows([]byte(data))
+	if err != nil {
+		t.Fatalf("parseJSONLRows error = %v", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("parseJSONLRows returned %d rows, want 3", len(rows))
+	}
+	if want := (importRow{line: 1, req: dto.CreateMovieRequest{Title: "Alien", Genre: "Sci-Fi", Director: "Ridley Scott", Year: 1979}}); rows[0] != want {
+		t.Errorf("row 0 = %+v, want %+v", rows[0], want)
+	}
+	if rows[1].line != 3 || rows[1].err == nil {
+		t.Errorf("row 1 = %+v, want error at line 3", rows[1])
+	}
+	if want := (importRow{line: 4, req: dto.CreateMovieRequest{Title: "Alien 3", Genre: "Sci-Fi", Director: "David Fincher", Year: 1992}}); rows[2] != want {
+		t.Errorf("row 2 = %+v, want %+v", rows[2], want)
+	}
+}
+
+func TestImportMoviesFile(t *testing.T) {
+	const header = "title,genre,director,year\n"
+	valid := header + "Alien,Sci-Fi,Ridley Scott,1979\nAliens,Sci-Fi,James Cameron,1986\n"
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		dryRun      bool
+		want        *pb.ImportMoviesFileResponse
+		wantCreated []string
+		wantCode    codes.Code
+	}{
+		{
+			name:        "movies are created",
+			contentType: "text/csv; charset=utf-8",
+			data:        valid,
+			want:        &pb.ImportMoviesFileResponse{TotalRows: 2, ValidRows: 2, Ids: []string{"id-1", "id-2"}},
+			wantCreated: []string{"Alien", "Aliens"},
+		},
+		{
+			name:        "dry run makes no writes",
+			contentType: "text/csv",
+			data:        valid,
+			dryRun:      true,
+			want:        &pb.ImportMoviesFileResponse{TotalRows: 2, ValidRows: 2},
+		},
+		{
+			name:        "nothing is created if any row is invalid",
+			contentType: "application/x-ndjson",
+			data: `{"title":"Alien","genre":"Sci-Fi","director":"Ridley Scott","year":1979}` + "\n" +
+				`{"title":"Aliens","genre":"Sci-Fi","year":1986}` + "\n" +
+				`{"title":"Metropolis","genre":"Sci-Fi","director":"Fritz Lang","year":1927}` + "\n" +
+				`{"title":"Intolerance","genre":"Drama","director":"D. W. Griffith","year":1910}` + "\n",
+			want: &pb.ImportMoviesFileResponse{
+				TotalRows: 4,
+				ValidRows: 2,
+				Errors: []*pb.ImportRowError{
+					{Line: 2, Message: `director: failed on "required"`},
+					{Line: 4, Message: `year: failed on "gte" 1911`},
+				},
+			},
+		},
+		{name: "unsupported content type", contentType: "application/json", data: "[]", wantCode: codes.InvalidArgument},
+		{name: "empty file", contentType: "text/csv", wantCode: codes.InvalidArgument},
+		{name: "invalid header", contentType: "text/csv", data: "name,year\n", wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []string
+			srv := newServer(&fakeService{
+				createMovies: func(ctx context.Context, movies []model.Movie) ([]string, error) {
+					ids := make([]string, 0, len(movies))
+					for i, movie := range movies {
+						created = append(created, movie.Title)
+						ids = append(ids, fmt.Sprintf("id-%d", i+1))
+					}
+
+					return ids, nil
+				},
+			})
+
+			resp, err := srv.ImportMoviesFile(context.Background(), &pb.ImportMoviesFileRequest{
+				File:   &httpbody.HttpBody{ContentType: tt.contentType, Data: []byte(tt.data)},
+				DryRun: tt.dryRun,
+			})
+			if tt.wantCode != codes.OK {
+				if got := status.Code(err); got != tt.wantCode {
+					t.Fatalf("ImportMoviesFile code = %v, want %v (error %v)", got, tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportMoviesFile error = %v", err)
+			}
+
+			wantResponse(t, resp, tt.want)
+			if !slices.Equal(created, tt.wantCreated) {
+				t.Errorf("created movies = %v, want %v", created, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestImportMoviesFileErrorsCap(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("title,genre,director,year\n")
+	for range maxImportErrors + 50 {
+		b.WriteString("Alien,Sci-Fi,Ridley Scott,1900\n")
+	}
+
+	srv := newServer(&fakeService{
+		createMovies: func(ctx context.Context, movies []model.Movie) ([]string, error) {
+			t.Error("CreateMovies is called for invalid file")
+			return nil, nil
+		},
+	})
+	resp, err := srv.ImportMoviesFile(context.Background(), &pb.ImportMoviesFileRequest{
+		File: &httpbody.HttpBody{ContentType: "text/csv", Data: []byte(b.String())},
+	})
+	if err != nil {
+		t.Fatalf("ImportMoviesFile error = %v", err)
+	}
+
+	if resp.GetTotalRows() != maxImportErrors+50 || resp.GetValidRows() != 0 {
+		t.Errorf("TotalRows, ValidRows = %d, %d, want %d, 0", resp.GetTotalRows(), resp.GetValidRows(), maxImportErrors+50)
+	}
+	if len(resp.GetErrors()) != maxImportErrors {
+		t.Fatalf("got %d errors, want %d", len(resp.GetErrors()), maxImportErrors)
+	}
+	if last := resp.GetErrors()[maxImportErrors-1].GetLine(); last != maxImportErrors+1 {
+		t.Errorf("line of the last error = %d, want %d", last, maxImportErrors+1)
+	}
+}
+
+func wantResponse(t *testing.T, got, want *pb.ImportMoviesFileResponse) {
+	t.Helper()
+
+	if got.GetTotalRows() != want.GetTotalRows() || got.GetValidRows() != want.GetValidRows() {
+		t.Errorf("TotalRows, ValidRows = %d, %d, want %d, %d", got.GetTotalRows(), got.GetValidRows(), want.GetTotalRows(), want.GetValidRows())
+	}
+	if !slices.Equal(got.GetIds(), want.GetIds()) {
+		t.Errorf("Ids = %v, want %v", got.GetIds(), want.GetIds())
+	}
+
+	rowErr := func(e *pb.ImportRowError) string { return fmt.Sprintf("%d: %s", e.GetLine(), e.GetMessage()) }
+	gotErrs, wantErrs := make([]string, 0), make([]string, 0)
+	for _, e := range got.GetErrors() {
+		gotErrs = append(gotErrs, rowErr(e))
+	}
+	for _, e := range want.GetErrors() {
+		wantErrs = append(wantErrs, rowErr(e))
+	}
+	if !slices.Equal(gotErrs, wantErrs) {
+		t.Errorf("Errors = %q, want %q", gotErrs, wantErrs)
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type key string
@@ -30,7 +32,7 @@ func LoggingUnaryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
 		log.Info(
 			"Got new unary request",
 			slog.String("Method", info.FullMethod),
-			slog.Any("Body", req),
+			slog.Any("Body", logBody(req)),
 		)
 
 		// Pass it to handler through the context
@@ -41,7 +43,7 @@ func LoggingUnaryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
 		defer func() {
 			log.Info(
 				"Request completed",
-				slog.Any("Response", m),
+				slog.Any("Response", logBody(m)),
 				slog.String("duration", time.Since(start).String()),
 			)
 		}()
@@ -97,7 +99,80 @@ func LoggingStreamInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
 			log.Info("Finished stream")
 		}
 
-		return nil
+		return err
+	}
+}
+
+// ContextUnaryInterceptor reports requests failed because their context was
+// cancelled or timed out with Canceled and DeadlineExceeded codes instead of
+// generic Internal error returned by handler.
+func ContextUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		m, err := handler(ctx, req)
+
+		return m, contextError(ctx, err)
+	}
+}
+
+// ContextStreamInterceptor is ContextUnaryInterceptor for streams
+func ContextStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		err := handler(srv, ss)
+
+		return contextError(ss.Context(), err)
+	}
+}
+
+// contextError replaces Internal and Unknown errors with the status of
+// context error if context is done
+func contextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	switch status.Code(err) {
+	case codes.Internal, codes.Unknown:
+		return status.FromContextError(ctx.Err()).Err()
+	default:
+		return err
+	}
+}
+
+// SessionUnaryInterceptor passes context of every request through session,
+// which prepares it for storage, e.g. to read its own writes
+func SessionUnaryInterceptor(session func(ctx context.Context) context.Context) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		return handler(session(ctx), req)
+	}
+}
+
+// SessionStreamInterceptor is SessionUnaryInterceptor for streams
+func SessionStreamInterceptor(session func(ctx context.Context) context.Context) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &serverStreamWrapper{
+			ServerStream: ss,
+			ctx:          session(ss.Context()),
+		})
 	}
 }
 
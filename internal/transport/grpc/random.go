@@ -0,0 +1,90 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	repo "movie-service/internal/repository"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (srv *server) GetRandomMovie(ctx context.Context, in *pb.GetRandomMovieRequest) (*pb.GetRandomMovieResponse, error) {
+	const op = "transport.grpc.GetRandomMovie"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	filter := pbToFilter(in.GetFilter())
+	log.Debug("Converted MovieFilter to dto", slog.Any("Filter", filter))
+
+	// Filter validation
+	log.Debug("Validating MovieFilter")
+	if err := srv.validate.Struct(filter); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Pick random movie through the service layer
+	log.Debug("Getting random movie")
+	movie, err := srv.service.GetRandomMovie(ctx, filter.ToModel())
+	if err != nil {
+		log.Error("Failed to get random movie", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "no movie matches filter")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get random movie")
+	}
+
+	log.Debug("Successfully got random movie", slog.Any("Movie", movie))
+
+	return &pb.GetRandomMovieResponse{Movie: toPb(movie)}, nil
+}
+
+func (srv *server) GetMovieOfTheDay(ctx context.Context, in *pb.GetMovieOfTheDayRequest) (*pb.GetMovieOfTheDayResponse, error) {
+	const op = "transport.grpc.GetMovieOfTheDay"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.GetMovieOfTheDayRequest{Date: in.GetDate(), Seed: in.GetSeed()}
+	log.Debug("Converted GetMovieOfTheDayRequest to dto", slog.Any("Request", req))
+
+	// Request validation
+	log.Debug("Validating GetMovieOfTheDayRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Pick movie of the day through the service layer
+	date := req.DateOrToday()
+	log.Debug("Getting movie of the day", slog.Time("date", date))
+	movie, err := srv.service.GetMovieOfTheDay(ctx, date, req.Seed)
+	if err != nil {
+		log.Error("Failed to get movie of the day", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "catalog is empty")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get movie of the day")
+	}
+
+	log.Debug("Successfully got movie of the day", slog.Any("Movie", movie))
+
+	return &pb.GetMovieOfTheDayResponse{Movie: toPb(movie), Date: date.Format(time.DateOnly)}, nil
+}
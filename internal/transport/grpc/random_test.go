@@ -0,0 +1,55 @@
+package moviegrpc
+
+import (
+	"context"
+	"movie-service/pkg/pb"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetMovieOfTheDay(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+
+	_, err := srv.GetMovieOfTheDay(ctx, &pb.GetMovieOfTheDayRequest{Date: "2024-03-01"})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Fatalf("GetMovieOfTheDay of empty catalog code = %v, want %v", got, codes.NotFound)
+	}
+
+	for _, title := range []string{"Alien", "Aliens", "Heat", "Volver"} {
+		createMovie(t, srv, title, "Drama", "Director", 2000)
+	}
+
+	tests := []struct {
+		name     string
+		req      *pb.GetMovieOfTheDayRequest
+		wantCode codes.Code
+	}{
+		{name: "date", req: &pb.GetMovieOfTheDayRequest{Date: "2024-03-01"}},
+		{name: "date and seed", req: &pb.GetMovieOfTheDayRequest{Date: "2024-03-01", Seed: "staff-picks"}},
+		{name: "today", req: &pb.GetMovieOfTheDayRequest{}},
+		{name: "invalid date", req: &pb.GetMovieOfTheDayRequest{Date: "01.03.2024"}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.GetMovieOfTheDay(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("GetMovieOfTheDay code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			// The same request picks the same movie
+			again, err := srv.GetMovieOfTheDay(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("GetMovieOfTheDay error = %v", err)
+			}
+			if again.GetMovie().GetId() != resp.GetMovie().GetId() {
+				t.Errorf("GetMovieOfTheDay picked %s, then %s, want the same movie", resp.GetMovie().GetId(), again.GetMovie().GetId())
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package moviegrpc
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const redacted = "[REDACTED]"
+
+// redactedFields are fields of messages which are never logged, e.g. secret
+// of webhook signatures
+var redactedFields = map[protoreflect.Name]bool{
+	"secret": true,
+}
+
+// logBody returns message for logs with values of redactedFields hidden.
+// Bytes, e.g. files uploaded for import, are replaced by their size.
+// Values which are not protobuf messages are returned as is.
+func logBody(m any) any {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return m
+	}
+	if !msg.ProtoReflect().IsValid() {
+		return nil
+	}
+
+	return messageValue(msg.ProtoReflect())
+}
+
+func messageValue(m protoreflect.Message) map[string]any {
+	fields := make(map[string]any)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		switch {
+		case redactedFields[fd.Name()]:
+			fields[name] = redacted
+		case fd.IsList():
+			list := v.List()
+			values := make([]any, list.Len())
+			for i := range list.Len() {
+				values[i] = fieldValue(fd, list.Get(i))
+			}
+			fields[name] = values
+		case fd.IsMap():
+			values := make(map[string]any)
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				values[k.String()] = fieldValue(fd.MapValue(), v)
+				return true
+			})
+			fields[name] = values
+		default:
+			fields[name] = fieldValue(fd, v)
+		}
+
+		return true
+	})
+
+	return fields
+}
+
+// fieldValue converts single value of the field, element of list or value of map
+func fieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageValue(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+
+		return int32(v.Enum())
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("%d bytes", len(v.Bytes()))
+	default:
+		return v.Interface()
+	}
+}
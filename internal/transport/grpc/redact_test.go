@@ -0,0 +1,70 @@
+package moviegrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"movie-service/pkg/pb"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+)
+
+func TestLogBody(t *testing.T) {
+	const secret = "very-secret-signing-key"
+
+	tests := []struct {
+		name string
+		body any
+		want string
+	}{
+		{
+			name: "secret is redacted",
+			body: &pb.CreateWebhookRequest{
+				Url:        "https://example.com/hook",
+				Secret:     secret,
+				EventTypes: []pb.MovieEventType{pb.MovieEventType_MOVIE_EVENT_TYPE_CREATED},
+			},
+			want: `{"event_types":["MOVIE_EVENT_TYPE_CREATED"],"secret":"[REDACTED]","url":"https://example.com/hook"}`,
+		},
+		{
+			name: "nested messages",
+			body: &pb.CreateWebhookResponse{Webhook: &pb.Webhook{Id: "42", Url: "https://example.com/hook"}},
+			want: `{"webhook":{"id":"42","url":"https://example.com/hook"}}`,
+		},
+		{
+			name: "uploaded file",
+			body: &pb.ImportMoviesFileRequest{
+				File:   &httpbody.HttpBody{ContentType: "text/csv", Data: []byte("title,genre,director,year\n")},
+				DryRun: true,
+			},
+			want: `{"dry_run":true,"file":{"content_type":"text/csv","data":"26 bytes"}}`,
+		},
+		{
+			name: "nil message",
+			body: (*pb.CreateWebhookRequest)(nil),
+			want: `null`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			slog.New(slog.NewJSONHandler(&buf, nil)).Info("request", slog.Any("Body", logBody(tt.body)))
+
+			if strings.Contains(buf.String(), secret) {
+				t.Fatalf("log contains secret: %s", buf.String())
+			}
+
+			var record struct {
+				Body json.RawMessage
+			}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("failed to parse log record: %v", err)
+			}
+			if got := string(record.Body); got != tt.want {
+				t.Errorf("Body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	repo "movie-service/internal/repository"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (srv *server) CreateReview(ctx context.Context, in *pb.CreateReviewRequest) (*pb.CreateReviewResponse, error) {
+	const op = "transport.grpc.CreateReview"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	review := pbToCreateReview(in)
+	log.Debug("Converted CreateReviewRequest to dto", slog.Any("Request", review))
+
+	// Create request validation
+	log.Debug("Validating CreateReviewRequest")
+	if err := srv.validate.Struct(review); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Save review through the service layer
+	log.Debug("Creating review")
+	newReview, err := srv.service.CreateReview(ctx, review.ToModel())
+	if err != nil {
+		log.Error("Failed to create review", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to add review")
+	}
+
+	log.Debug("Successfully created review", slog.String("review_id", newReview.ID))
+
+	return &pb.CreateReviewResponse{Review: reviewToPb(newReview)}, nil
+}
+
+func (srv *server) ListReviews(ctx context.Context, in *pb.ListReviewsRequest) (*pb.ListReviewsResponse, error) {
+	const op = "transport.grpc.ListReviews"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := pbToListReviews(in)
+	log.Debug("Converted ListReviewsRequest to dto", slog.Any("Request", req))
+
+	// List request validation
+	log.Debug("Validating ListReviewsRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Get approved reviews through the service layer
+	log.Debug("Getting page of reviews")
+	reviews, err := srv.service.ListReviews(ctx, req.MovieID, req.Limit(), req.Offset())
+	if err != nil {
+		log.Error("Failed to get reviews", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to get reviews")
+	}
+
+	log.Debug("Successfully got reviews", slog.Int("count", len(reviews)))
+
+	resp := &pb.ListReviewsResponse{Reviews: make([]*pb.Review, 0, len(reviews))}
+	for _, review := range reviews {
+		resp.Reviews = append(resp.Reviews, reviewToPb(&review))
+	}
+
+	return resp, nil
+}
+
+func (srv *server) UpdateReview(ctx context.Context, in *pb.UpdateReviewRequest) (*pb.UpdateReviewResponse, error) {
+	const op = "transport.grpc.UpdateReview"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	review := pbToUpdateReview(in)
+	log.Debug("Converted UpdateReviewRequest to dto", slog.Any("Request", review))
+
+	// Update request validation
+	log.Debug("Validating UpdateReviewRequest")
+	if err := srv.validate.Struct(review); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Update review through the service layer
+	log.Debug("Updating review")
+	newReview, err := srv.service.UpdateReview(ctx, review.ToModel())
+	if err != nil {
+		log.Error("Failed to update review", sl.Err(err))
+
+		if errors.Is(err, repo.ErrReviewNotExists) {
+			return nil, status.Error(codes.NotFound, "review not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to update review")
+	}
+
+	log.Debug("Successfully updated review", slog.Any("Review", newReview))
+
+	return &pb.UpdateReviewResponse{Review: reviewToPb(newReview)}, nil
+}
+
+func (srv *server) DeleteReview(ctx context.Context, in *pb.DeleteReviewRequest) (*pb.DeleteReviewResponse, error) {
+	const op = "transport.grpc.DeleteReview"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	review := pbToDeleteReview(in)
+	log.Debug("Converted DeleteReviewRequest to dto", slog.Any("Request", review))
+
+	// Delete request validation
+	log.Debug("Validating DeleteReviewRequest")
+	if err := srv.validate.Struct(review); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Delete review through the service layer
+	log.Debug("Deleting review by ID")
+	ok, err := srv.service.DeleteReview(ctx, review.ID, review.UserID)
+	if err != nil {
+		log.Error("Failed to delete review", sl.Err(err))
+
+		if errors.Is(err, repo.ErrReviewNotOwned) {
+			return nil, status.Error(codes.PermissionDenied, "review is written by another user")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to delete review")
+	}
+
+	if ok {
+		log.Debug("Successfully deleted review")
+	} else {
+		log.Debug("No review with this ID was found", slog.String("ID", review.ID))
+	}
+
+	return &pb.DeleteReviewResponse{Success: ok}, nil
+}
+
+func (srv *server) ModerateReview(ctx context.Context, in *pb.ModerateReviewRequest) (*pb.ModerateReviewResponse, error) {
+	const op = "transport.grpc.ModerateReview"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := pbToModerateReview(in)
+	log.Debug("Converted ModerateReviewRequest to dto", slog.Any("Request", req))
+
+	// Moderate request validation
+	log.Debug("Validating ModerateReviewRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Change review status through the service layer
+	log.Debug("Moderating review")
+	review, err := srv.service.ModerateReview(ctx, req.ID, req.Status)
+	if err != nil {
+		log.Error("Failed to moderate review", sl.Err(err))
+
+		if errors.Is(err, repo.ErrReviewNotExists) {
+			return nil, status.Error(codes.NotFound, "review not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to moderate review")
+	}
+
+	log.Debug("Successfully moderated review", slog.Any("Review", review))
+
+	return &pb.ModerateReviewResponse{Review: reviewToPb(review)}, nil
+}
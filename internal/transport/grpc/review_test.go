@@ -0,0 +1,115 @@
+package moviegrpc
+
+import (
+	"context"
+	"movie-service/pkg/pb"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestModerateReview(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	movieID := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	created, err := srv.CreateReview(ctx, &pb.CreateReviewRequest{MovieId: movieID, UserId: "alice", Text: "Scary"})
+	if err != nil {
+		t.Fatalf("CreateReview error = %v", err)
+	}
+	id := created.GetReview().GetId()
+	if got := created.GetReview().GetStatus(); got != pb.ReviewStatus_REVIEW_STATUS_PENDING {
+		t.Fatalf("Status of new review = %v, want pending", got)
+	}
+
+	tests := []struct {
+		name       string
+		req        *pb.ModerateReviewRequest
+		wantCode   codes.Code
+		wantListed int
+	}{
+		{name: "approve", req: &pb.ModerateReviewRequest{Id: id, Status: pb.ReviewStatus_REVIEW_STATUS_APPROVED}, wantListed: 1},
+		{name: "reject approved", req: &pb.ModerateReviewRequest{Id: id, Status: pb.ReviewStatus_REVIEW_STATUS_REJECTED}},
+		{name: "approve rejected", req: &pb.ModerateReviewRequest{Id: id, Status: pb.ReviewStatus_REVIEW_STATUS_APPROVED}, wantListed: 1},
+		{name: "back to moderation", req: &pb.ModerateReviewRequest{Id: id, Status: pb.ReviewStatus_REVIEW_STATUS_PENDING}},
+		{name: "unspecified status", req: &pb.ModerateReviewRequest{Id: id}, wantCode: codes.InvalidArgument},
+		{name: "unknown status", req: &pb.ModerateReviewRequest{Id: id, Status: 42}, wantCode: codes.InvalidArgument},
+		{name: "invalid id", req: &pb.ModerateReviewRequest{Id: "review", Status: pb.ReviewStatus_REVIEW_STATUS_APPROVED}, wantCode: codes.InvalidArgument},
+		{
+			name:     "unknown review",
+			req:      &pb.ModerateReviewRequest{Id: "00000000-0000-0000-0000-000000000000", Status: pb.ReviewStatus_REVIEW_STATUS_APPROVED},
+			wantCode: codes.NotFound,
+		},
+	}
+	// Cases go in order, every one starts from status left by the previous
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.ModerateReview(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("ModerateReview code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			if got := resp.GetReview().GetStatus(); got != tt.req.GetStatus() {
+				t.Errorf("Status = %v, want %v", got, tt.req.GetStatus())
+			}
+
+			// Only approved reviews are public
+			listed, err := srv.ListReviews(ctx, &pb.ListReviewsRequest{MovieId: movieID})
+			if err != nil {
+				t.Fatalf("ListReviews error = %v", err)
+			}
+			if len(listed.GetReviews()) != tt.wantListed {
+				t.Errorf("ListReviews returned %d reviews, want %d", len(listed.GetReviews()), tt.wantListed)
+			}
+
+			movie, err := srv.GetMovie(ctx, &pb.GetMovieRequest{Id: movieID})
+			if err != nil {
+				t.Fatalf("GetMovie error = %v", err)
+			}
+			if got := movie.GetMovie().GetReviewCount(); got != uint32(tt.wantListed) {
+				t.Errorf("ReviewCount = %d, want %d", got, tt.wantListed)
+			}
+		})
+	}
+}
+
+func TestDeleteReview(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	movieID := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	created, err := srv.CreateReview(ctx, &pb.CreateReviewRequest{MovieId: movieID, UserId: "alice", Text: "Scary"})
+	if err != nil {
+		t.Fatalf("CreateReview error = %v", err)
+	}
+	id := created.GetReview().GetId()
+
+	tests := []struct {
+		name        string
+		req         *pb.DeleteReviewRequest
+		wantCode    codes.Code
+		wantSuccess bool
+	}{
+		{name: "no user", req: &pb.DeleteReviewRequest{Id: id}, wantCode: codes.InvalidArgument},
+		{name: "invalid id", req: &pb.DeleteReviewRequest{Id: "review", UserId: "alice"}, wantCode: codes.InvalidArgument},
+		{name: "another user", req: &pb.DeleteReviewRequest{Id: id, UserId: "bob"}, wantCode: codes.PermissionDenied},
+		{name: "author", req: &pb.DeleteReviewRequest{Id: id, UserId: "alice"}, wantSuccess: true},
+		{name: "deleted review", req: &pb.DeleteReviewRequest{Id: id, UserId: "alice"}},
+	}
+	// Cases go in order, the review is deleted by its author only once
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.DeleteReview(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("DeleteReview code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if got := resp.GetSuccess(); got != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v", got, tt.wantSuccess)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	repo "movie-service/internal/repository"
 	"movie-service/pkg/pb"
 	"movie-service/pkg/sl"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"google.golang.org/grpc"
@@ -17,12 +18,35 @@ import (
 )
 
 type Service interface {
-	GetMovie(id string) (*model.Movie, error)
-	GetMovies() ([]model.Movie, error)
-	CreateMovie(movie *model.Movie) (string, error)
-	CreateMovies(movies []model.Movie) ([]string, error)
-	UpdateMovie(id string, movie *model.Movie) (*model.Movie, error)
-	DeleteMovie(id string) (bool, error)
+	GetMovie(ctx context.Context, id string) (*model.Movie, error)
+	GetMovies(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error)
+	ListMovies(ctx context.Context, filter *model.MovieFilter, limit, offset uint64) ([]model.Movie, error)
+	CreateMovie(ctx context.Context, movie *model.Movie) (string, error)
+	CreateMovies(ctx context.Context, movies []model.Movie) ([]string, error)
+	UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error)
+	DeleteMovie(ctx context.Context, id string) (bool, error)
+	RateMovie(ctx context.Context, rating *model.Rating) (*model.Movie, error)
+	CreateReview(ctx context.Context, review *model.Review) (*model.Review, error)
+	ListReviews(ctx context.Context, movieID string, limit, offset uint64) ([]model.Review, error)
+	UpdateReview(ctx context.Context, review *model.Review) (*model.Review, error)
+	DeleteReview(ctx context.Context, id, userID string) (bool, error)
+	ModerateReview(ctx context.Context, id string, status model.ReviewStatus) (*model.Review, error)
+	CreateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error)
+	GetCollection(ctx context.Context, id string) (*model.Collection, error)
+	UpdateCollection(ctx context.Context, collection *model.Collection) (*model.Collection, error)
+	SetCollectionMovies(ctx context.Context, id string, movieIDs []string) (*model.Collection, error)
+	DeleteCollection(ctx context.Context, id string) (bool, error)
+	GetCollectionMovies(ctx context.Context, id string) ([]model.Movie, error)
+	AddTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error)
+	RemoveTags(ctx context.Context, movieID string, tags []string) (*model.Movie, error)
+	ListTags(ctx context.Context, prefix string, limit uint64) ([]model.Tag, error)
+	GetCatalogStats(ctx context.Context, query *model.CatalogStatsQuery) (*model.CatalogStats, error)
+	GetSimilarMovies(ctx context.Context, id string, limit uint64) ([]model.SimilarMovie, error)
+	GetFilmography(ctx context.Context, director string) ([]model.Movie, error)
+	GetRandomMovie(ctx context.Context, filter *model.MovieFilter) (*model.Movie, error)
+	GetMovieOfTheDay(ctx context.Context, date time.Time, seed string) (*model.Movie, error)
+	WatchMovies(ctx context.Context, afterSeq *uint64, send func(*model.MovieEvent) error) error
+	ExportMovies(ctx context.Context, filter *model.MovieFilter, fn func([]model.Movie) error) error
 }
 
 type server struct {
@@ -61,7 +85,7 @@ func (srv *server) CreateMovie(ctx context.Context, in *pb.CreateMovieRequest) (
 
 	// Add info about new movie to repository through the service layer
 	log.Debug("Creating movie")
-	newID, err := srv.service.CreateMovie(newMovie.ToModel())
+	newID, err := srv.service.CreateMovie(ctx, newMovie.ToModel())
 	if err != nil {
 		log.Error("Failed to create movie", sl.Err(err))
 
@@ -94,7 +118,7 @@ func (srv *server) GetMovie(ctx context.Context, in *pb.GetMovieRequest) (*pb.Ge
 
 	// Get movie info from repository through the service layer
 	log.Debug("Getting movie info by ID")
-	movie, err := srv.service.GetMovie(id)
+	movie, err := srv.service.GetMovie(ctx, id)
 	if err != nil {
 		log.Error("Failed to get movie info", sl.Err(err))
 
@@ -110,6 +134,44 @@ func (srv *server) GetMovie(ctx context.Context, in *pb.GetMovieRequest) (*pb.Ge
 	return &pb.GetMovieResponse{Movie: toPb(movie)}, nil
 }
 
+func (srv *server) ListMovies(ctx context.Context, in *pb.ListMoviesRequest) (*pb.ListMoviesResponse, error) {
+	const op = "transport.grpc.ListMovies"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := pbToListMovies(in)
+	log.Debug("Converted ListMoviesRequest to dto", slog.Any("Request", req))
+
+	// List request validation
+	log.Debug("Validating ListMoviesRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Get page of movies through the service layer
+	log.Debug("Getting page of movies")
+	movies, err := srv.service.ListMovies(ctx, req.Filter.ToModel(), req.Limit(), req.Offset())
+	if err != nil {
+		log.Error("Failed to get page of movies", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to get movies")
+	}
+
+	log.Debug("Successfully got page of movies", slog.Int("count", len(movies)))
+
+	resp := &pb.ListMoviesResponse{Movies: make([]*pb.Movie, 0, len(movies))}
+	for _, movie := range movies {
+		resp.Movies = append(resp.Movies, toPb(&movie))
+	}
+
+	return resp, nil
+}
+
 func (srv *server) UpdateMovie(ctx context.Context, in *pb.UpdateMovieRequest) (*pb.UpdateMovieResponse, error) {
 	const op = "transport.grpc.UpdateMovie"
 
@@ -131,7 +193,7 @@ func (srv *server) UpdateMovie(ctx context.Context, in *pb.UpdateMovieRequest) (
 
 	// Update movie info in repository through the service layer
 	log.Debug("Updating movie info")
-	newMovie, err := srv.service.UpdateMovie(movie.ID, movie.ToModel())
+	newMovie, err := srv.service.UpdateMovie(ctx, movie.ID, movie.ToModel())
 	if err != nil {
 		log.Error("Failed to update movie info", sl.Err(err))
 
@@ -168,7 +230,7 @@ func (srv *server) DeleteMovie(ctx context.Context, in *pb.DeleteMovieRequest) (
 
 	// Delete movie info from repository through the service layer
 	log.Debug("Deleting movie info by ID")
-	ok, err := srv.service.DeleteMovie(id)
+	ok, err := srv.service.DeleteMovie(ctx, id)
 	if err != nil && !errors.Is(err, repo.ErrMovieNotExists) {
 		log.Error("Failed to delete movie info", sl.Err(err))
 
@@ -184,6 +246,43 @@ func (srv *server) DeleteMovie(ctx context.Context, in *pb.DeleteMovieRequest) (
 	return &pb.DeleteMovieResponse{Success: ok}, nil
 }
 
+func (srv *server) RateMovie(ctx context.Context, in *pb.RateMovieRequest) (*pb.RateMovieResponse, error) {
+	const op = "transport.grpc.RateMovie"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	rating := pbToRate(in)
+	log.Debug("Converted RateMovieRequest to dto", slog.Any("Request", rating))
+
+	// Rate request validation
+	log.Debug("Validating RateMovieRequest")
+	if err := srv.validate.Struct(rating); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Save rating through the service layer
+	log.Debug("Rating movie")
+	movie, err := srv.service.RateMovie(ctx, rating.ToModel())
+	if err != nil {
+		log.Error("Failed to rate movie", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to rate movie")
+	}
+
+	log.Debug("Successfully rated movie", slog.Any("Movie", movie))
+
+	return &pb.RateMovieResponse{Movie: toPb(movie)}, nil
+}
+
 func (srv *server) GetMovies(in *pb.GetMoviesRequest, stream pb.MovieService_GetMoviesServer) error {
 	const op = "transport.grpc.GetMovies"
 	ctx := stream.Context()
@@ -193,13 +292,24 @@ func (srv *server) GetMovies(in *pb.GetMoviesRequest, stream pb.MovieService_Get
 		slog.Any("request_id", ctx.Value(reqIDKey)),
 	)
 
+	filter := pbToFilter(in.GetFilter())
+	log.Debug("Converted MovieFilter to dto", slog.Any("Filter", filter))
+
+	// Filter validation
+	log.Debug("Validating MovieFilter")
+	if err := srv.validate.Struct(filter); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+
 	log.Debug("Getting all movies info from db")
 	// TODO: change for dynamic reading (get rid of variant all-movies-in-slice)
-	movies, err := srv.service.GetMovies()
+	movies, err := srv.service.GetMovies(ctx, filter.ToModel())
 	if err != nil {
 		log.Error("Failed to get info about all movies", sl.Err(err))
 
-		return nil
+		return status.Error(codes.Internal, "failed to get movies")
 	}
 
 	log.Debug("Starting stream...")
@@ -240,7 +350,7 @@ func (srv *server) CreateMovies(stream pb.MovieService_CreateMoviesServer) error
 
 		newMovie := pbToCreate(pbNewMovie)
 
-		id, err := srv.service.CreateMovie(newMovie.ToModel())
+		id, err := srv.service.CreateMovie(ctx, newMovie.ToModel())
 		if err != nil {
 			log.Error("Failed to save new movie info")
 			return err
@@ -0,0 +1,175 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"movie-service/internal/model"
+	memoryrepo "movie-service/internal/repository/memory"
+	"movie-service/internal/service/movieservice"
+	"movie-service/pkg/pb"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeService implements Service by functions set by test, calls of other
+// methods panic
+type fakeService struct {
+	Service
+	getMovies    func(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error)
+	createMovies func(ctx context.Context, movies []model.Movie) ([]string, error)
+}
+
+func (s *fakeService) GetMovies(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error) {
+	return s.getMovies(ctx, filter)
+}
+
+func (s *fakeService) CreateMovies(ctx context.Context, movies []model.Movie) ([]string, error) {
+	return s.createMovies(ctx, movies)
+}
+
+// fakeStream is server side of stream, which keeps sent messages
+type fakeStream[T any] struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*T
+}
+
+func (s *fakeStream[T]) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream[T]) Send(m *T) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func newServer(service Service) *server {
+	return &server{
+		l:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+// newMemoryServer returns server backed by the real service over in-memory
+// repository
+func newMemoryServer() *server {
+	repo := memoryrepo.New()
+	return newServer(movieservice.New(repo, repo.Notifier()))
+}
+
+func createMovie(t *testing.T, srv *server, title, genre, director string, year uint32) string {
+	t.Helper()
+
+	resp, err := srv.CreateMovie(context.Background(), &pb.CreateMovieRequest{Title: title, Genre: genre, Director: director, Year: year})
+	if err != nil {
+		t.Fatalf("CreateMovie(%q) error = %v", title, err)
+	}
+
+	return resp.GetId()
+}
+
+func TestGetMoviesErrors(t *testing.T) {
+	// Service fails the way repositories do when context is done
+	srv := newServer(&fakeService{
+		getMovies: func(ctx context.Context, filter *model.MovieFilter) ([]model.Movie, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("repository.GetMovies: %w", err)
+			}
+
+			return nil, errors.New("connection refused")
+		},
+	})
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{name: "service error", ctx: context.Background(), want: codes.Internal},
+		{name: "cancelled", ctx: cancelled, want: codes.Canceled},
+		{name: "deadline exceeded", ctx: expired, want: codes.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &fakeStream[pb.GetMovieResponse]{ctx: tt.ctx}
+			err := ContextStreamInterceptor()(srv, stream, &grpc.StreamServerInfo{}, func(_ any, _ grpc.ServerStream) error {
+				return srv.GetMovies(&pb.GetMoviesRequest{}, stream)
+			})
+
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("GetMovies code = %v, want %v (error %v)", got, tt.want, err)
+			}
+			if len(stream.sent) != 0 {
+				t.Errorf("GetMovies sent %d movies, want none", len(stream.sent))
+			}
+		})
+	}
+}
+
+func TestRateMovie(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	id := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	tests := []struct {
+		name      string
+		req       *pb.RateMovieRequest
+		wantCode  codes.Code
+		wantAvg   float64
+		wantCount uint32
+	}{
+		{name: "first score", req: &pb.RateMovieRequest{MovieId: id, UserId: "alice", Score: 8}, wantAvg: 8, wantCount: 1},
+		{name: "second user", req: &pb.RateMovieRequest{MovieId: id, UserId: "bob", Score: 5}, wantAvg: 6.5, wantCount: 2},
+		{name: "rerating replaces score", req: &pb.RateMovieRequest{MovieId: id, UserId: "alice", Score: 10}, wantAvg: 7.5, wantCount: 2},
+		{name: "score below range", req: &pb.RateMovieRequest{MovieId: id, UserId: "carol", Score: 0}, wantCode: codes.InvalidArgument},
+		{name: "score above range", req: &pb.RateMovieRequest{MovieId: id, UserId: "carol", Score: 11}, wantCode: codes.InvalidArgument},
+		{name: "missing user", req: &pb.RateMovieRequest{MovieId: id, Score: 5}, wantCode: codes.InvalidArgument},
+		{name: "invalid movie id", req: &pb.RateMovieRequest{MovieId: "alien", UserId: "carol", Score: 5}, wantCode: codes.InvalidArgument},
+		{
+			name:     "unknown movie",
+			req:      &pb.RateMovieRequest{MovieId: "00000000-0000-0000-0000-000000000000", UserId: "carol", Score: 5},
+			wantCode: codes.NotFound,
+		},
+	}
+	// Cases go in order, every one builds on aggregates left by the previous
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.RateMovie(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("RateMovie code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			movie := resp.GetMovie()
+			if movie.GetAverageRating() != tt.wantAvg || movie.GetRatingCount() != tt.wantCount {
+				t.Errorf("AverageRating, RatingCount = %v, %d, want %v, %d",
+					movie.GetAverageRating(), movie.GetRatingCount(), tt.wantAvg, tt.wantCount)
+			}
+		})
+	}
+
+	// Rejected scores leave aggregates as they were
+	resp, err := srv.GetMovie(ctx, &pb.GetMovieRequest{Id: id})
+	if err != nil {
+		t.Fatalf("GetMovie error = %v", err)
+	}
+	if movie := resp.GetMovie(); movie.GetAverageRating() != 7.5 || movie.GetRatingCount() != 2 {
+		t.Errorf("AverageRating, RatingCount = %v, %d, want 7.5, 2", movie.GetAverageRating(), movie.GetRatingCount())
+	}
+}
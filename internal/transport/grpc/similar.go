@@ -0,0 +1,56 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	repo "movie-service/internal/repository"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (srv *server) GetSimilarMovies(ctx context.Context, in *pb.GetSimilarMoviesRequest) (*pb.GetSimilarMoviesResponse, error) {
+	const op = "transport.grpc.GetSimilarMovies"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.GetSimilarMoviesRequest{MovieID: in.GetMovieId(), Limit: in.GetLimit()}
+	log.Debug("Converted GetSimilarMoviesRequest to dto", slog.Any("Request", req))
+
+	// Similar request validation
+	log.Debug("Validating GetSimilarMoviesRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Find similar movies through the service layer
+	log.Debug("Getting similar movies")
+	movies, err := srv.service.GetSimilarMovies(ctx, req.MovieID, req.LimitOrDefault())
+	if err != nil {
+		log.Error("Failed to get similar movies", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get similar movies")
+	}
+
+	log.Debug("Successfully got similar movies", slog.Int("count", len(movies)))
+
+	resp := &pb.GetSimilarMoviesResponse{Movies: make([]*pb.SimilarMovie, 0, len(movies))}
+	for _, movie := range movies {
+		resp.Movies = append(resp.Movies, similarToPb(&movie))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,44 @@
+package moviegrpc
+
+import (
+	"context"
+	"log/slog"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (srv *server) GetCatalogStats(ctx context.Context, in *pb.GetCatalogStatsRequest) (*pb.GetCatalogStatsResponse, error) {
+	const op = "transport.grpc.GetCatalogStats"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := pbToGetCatalogStats(in)
+	log.Debug("Converted GetCatalogStatsRequest to dto", slog.Any("Request", req))
+
+	// Stats request validation
+	log.Debug("Validating GetCatalogStatsRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Compute statistics through the service layer
+	log.Debug("Computing catalog statistics")
+	stats, err := srv.service.GetCatalogStats(ctx, req.ToModel())
+	if err != nil {
+		log.Error("Failed to compute catalog statistics", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to get catalog statistics")
+	}
+
+	log.Debug("Successfully computed catalog statistics", slog.Uint64("total_movies", stats.TotalMovies))
+
+	return catalogStatsToPb(stats), nil
+}
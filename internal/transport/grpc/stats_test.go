@@ -0,0 +1,125 @@
+package moviegrpc
+
+import (
+	"context"
+	"fmt"
+	"movie-service/pkg/pb"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetCatalogStats(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	alien := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	aliens := createMovie(t, srv, "Aliens", "Sci-Fi", "James Cameron", 1986)
+	createMovie(t, srv, "Blade Runner", "Sci-Fi", "Ridley Scott", 1982)
+	createMovie(t, srv, "Heat", "Crime", "Michael Mann", 1995)
+
+	if _, err := srv.AddTags(ctx, &pb.AddTagsRequest{MovieId: alien, Tags: []string{"space", "horror"}}); err != nil {
+		t.Fatalf("AddTags error = %v", err)
+	}
+	if _, err := srv.AddTags(ctx, &pb.AddTagsRequest{MovieId: aliens, Tags: []string{"space"}}); err != nil {
+		t.Fatalf("AddTags error = %v", err)
+	}
+	for _, rating := range []*pb.RateMovieRequest{
+		{MovieId: alien, UserId: "alice", Score: 8},
+		{MovieId: alien, UserId: "bob", Score: 9},
+		{MovieId: aliens, UserId: "alice", Score: 8},
+	} {
+		if _, err := srv.RateMovie(ctx, rating); err != nil {
+			t.Fatalf("RateMovie error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		req       *pb.GetCatalogStatsRequest
+		wantTotal uint64
+		// want holds buckets of every facet formatted by formatBuckets
+		want     map[pb.Facet][]string
+		wantCode codes.Code
+	}{
+		{
+			name:      "all facets by default",
+			req:       &pb.GetCatalogStatsRequest{},
+			wantTotal: 4,
+			want: map[pb.Facet][]string{
+				pb.Facet_FACET_GENRE:    {"Sci-Fi:3", "Crime:1"},
+				pb.Facet_FACET_DIRECTOR: {"Ridley Scott:2", "James Cameron:1", "Michael Mann:1"},
+				pb.Facet_FACET_TAG:      {"space:2", "horror:1"},
+				pb.Facet_FACET_YEAR:     {"1970-1979:1", "1980-1989:2", "1990-1999:1"},
+				pb.Facet_FACET_RATING:   {"8-8:2"},
+			},
+		},
+		{
+			name: "top limit and year bucket",
+			req: &pb.GetCatalogStatsRequest{
+				Facets:     []pb.Facet{pb.Facet_FACET_DIRECTOR, pb.Facet_FACET_YEAR},
+				TopLimit:   1,
+				YearBucket: 5,
+			},
+			wantTotal: 4,
+			want: map[pb.Facet][]string{
+				pb.Facet_FACET_DIRECTOR: {"Ridley Scott:2"},
+				pb.Facet_FACET_YEAR:     {"1975-1979:1", "1980-1984:1", "1985-1989:1", "1995-1999:1"},
+			},
+		},
+		{
+			name: "filtered",
+			req: &pb.GetCatalogStatsRequest{
+				Filter: &pb.MovieFilter{TagsAny: []string{"Space"}},
+				Facets: []pb.Facet{pb.Facet_FACET_DIRECTOR},
+			},
+			wantTotal: 2,
+			want: map[pb.Facet][]string{
+				pb.Facet_FACET_DIRECTOR: {"James Cameron:1", "Ridley Scott:1"},
+			},
+		},
+		{name: "unspecified facet", req: &pb.GetCatalogStatsRequest{Facets: []pb.Facet{pb.Facet_FACET_UNSPECIFIED}}, wantCode: codes.InvalidArgument},
+		{name: "duplicate facets", req: &pb.GetCatalogStatsRequest{Facets: []pb.Facet{pb.Facet_FACET_TAG, pb.Facet_FACET_TAG}}, wantCode: codes.InvalidArgument},
+		{name: "top limit too large", req: &pb.GetCatalogStatsRequest{TopLimit: 101}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.GetCatalogStats(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("GetCatalogStats code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			if resp.GetTotalMovies() != tt.wantTotal {
+				t.Errorf("TotalMovies = %d, want %d", resp.GetTotalMovies(), tt.wantTotal)
+			}
+			if len(resp.GetFacets()) != len(tt.want) {
+				t.Fatalf("got %d facets, want %d", len(resp.GetFacets()), len(tt.want))
+			}
+			for _, facet := range resp.GetFacets() {
+				if got, want := formatBuckets(facet), tt.want[facet.GetFacet()]; !slices.Equal(got, want) {
+					t.Errorf("%v buckets = %q, want %q", facet.GetFacet(), got, want)
+				}
+			}
+		})
+	}
+}
+
+// formatBuckets formats buckets as "value:count", or as "from-to:count"
+// for histograms
+func formatBuckets(facet *pb.FacetCounts) []string {
+	buckets := make([]string, 0, len(facet.GetBuckets()))
+	for _, bucket := range facet.GetBuckets() {
+		switch facet.GetFacet() {
+		case pb.Facet_FACET_YEAR, pb.Facet_FACET_RATING:
+			buckets = append(buckets, fmt.Sprintf("%d-%d:%d", bucket.GetFrom(), bucket.GetTo(), bucket.GetCount()))
+		default:
+			buckets = append(buckets, fmt.Sprintf("%s:%d", bucket.GetValue(), bucket.GetCount()))
+		}
+	}
+
+	return buckets
+}
@@ -0,0 +1,126 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	repo "movie-service/internal/repository"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (srv *server) AddTags(ctx context.Context, in *pb.AddTagsRequest) (*pb.AddTagsResponse, error) {
+	const op = "transport.grpc.AddTags"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.TagsRequest{MovieID: in.GetMovieId(), Tags: dto.NormalizeTags(in.GetTags())}
+	log.Debug("Converted AddTagsRequest to dto", slog.Any("Request", req))
+
+	// Add request validation
+	log.Debug("Validating AddTagsRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Tag movie through the service layer
+	log.Debug("Adding tags to movie")
+	movie, err := srv.service.AddTags(ctx, req.MovieID, req.Tags)
+	if err != nil {
+		log.Error("Failed to add tags", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to add tags")
+	}
+
+	log.Debug("Successfully added tags", slog.Any("Movie", movie))
+
+	return &pb.AddTagsResponse{Movie: toPb(movie)}, nil
+}
+
+func (srv *server) RemoveTags(ctx context.Context, in *pb.RemoveTagsRequest) (*pb.RemoveTagsResponse, error) {
+	const op = "transport.grpc.RemoveTags"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.TagsRequest{MovieID: in.GetMovieId(), Tags: dto.NormalizeTags(in.GetTags())}
+	log.Debug("Converted RemoveTagsRequest to dto", slog.Any("Request", req))
+
+	// Remove request validation
+	log.Debug("Validating RemoveTagsRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Untag movie through the service layer
+	log.Debug("Removing tags from movie")
+	movie, err := srv.service.RemoveTags(ctx, req.MovieID, req.Tags)
+	if err != nil {
+		log.Error("Failed to remove tags", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to remove tags")
+	}
+
+	log.Debug("Successfully removed tags", slog.Any("Movie", movie))
+
+	return &pb.RemoveTagsResponse{Movie: toPb(movie)}, nil
+}
+
+func (srv *server) ListTags(ctx context.Context, in *pb.ListTagsRequest) (*pb.ListTagsResponse, error) {
+	const op = "transport.grpc.ListTags"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.ListTagsRequest{Prefix: dto.NormalizeTag(in.GetPrefix()), Limit: in.GetLimit()}
+	log.Debug("Converted ListTagsRequest to dto", slog.Any("Request", req))
+
+	// List request validation
+	log.Debug("Validating ListTagsRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Get tags with usage counts through the service layer
+	log.Debug("Getting tags")
+	tags, err := srv.service.ListTags(ctx, req.Prefix, req.LimitOrDefault())
+	if err != nil {
+		log.Error("Failed to get tags", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to get tags")
+	}
+
+	log.Debug("Successfully got tags", slog.Int("count", len(tags)))
+
+	resp := &pb.ListTagsResponse{Tags: make([]*pb.Tag, 0, len(tags))}
+	for _, tag := range tags {
+		resp.Tags = append(resp.Tags, tagToPb(&tag))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,170 @@
+package moviegrpc
+
+import (
+	"context"
+	"fmt"
+	"movie-service/pkg/pb"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAddTags(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	alien := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	const unknown = "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name     string
+		req      *pb.AddTagsRequest
+		wantTags []string
+		wantCode codes.Code
+	}{
+		{name: "normalized", req: &pb.AddTagsRequest{MovieId: alien, Tags: []string{"Cult Classic", "space"}}, wantTags: []string{"cult-classic", "space"}},
+		{name: "already added", req: &pb.AddTagsRequest{MovieId: alien, Tags: []string{"SPACE", "horror"}}, wantTags: []string{"cult-classic", "horror", "space"}},
+		{name: "duplicates after normalization", req: &pb.AddTagsRequest{MovieId: alien, Tags: []string{"Cult Classic", "cult-classic"}}, wantCode: codes.InvalidArgument},
+		{name: "blank tag", req: &pb.AddTagsRequest{MovieId: alien, Tags: []string{" "}}, wantCode: codes.InvalidArgument},
+		{name: "no tags", req: &pb.AddTagsRequest{MovieId: alien}, wantCode: codes.InvalidArgument},
+		{name: "invalid id", req: &pb.AddTagsRequest{MovieId: "alien", Tags: []string{"space"}}, wantCode: codes.InvalidArgument},
+		{name: "unknown movie", req: &pb.AddTagsRequest{MovieId: unknown, Tags: []string{"space"}}, wantCode: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.AddTags(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("AddTags code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			if got := resp.GetMovie().GetTags(); !slices.Equal(got, tt.wantTags) {
+				t.Errorf("Tags = %q, want %q", got, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestRemoveTags(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	alien := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+
+	if _, err := srv.AddTags(ctx, &pb.AddTagsRequest{MovieId: alien, Tags: []string{"cult-classic", "space"}}); err != nil {
+		t.Fatalf("AddTags error = %v", err)
+	}
+
+	// Tags are normalized before removal and missing ones are ignored
+	resp, err := srv.RemoveTags(ctx, &pb.RemoveTagsRequest{MovieId: alien, Tags: []string{"Cult Classic", "horror"}})
+	if err != nil {
+		t.Fatalf("RemoveTags error = %v", err)
+	}
+	if got, want := resp.GetMovie().GetTags(), []string{"space"}; !slices.Equal(got, want) {
+		t.Errorf("Tags = %q, want %q", got, want)
+	}
+
+	_, err = srv.RemoveTags(ctx, &pb.RemoveTagsRequest{MovieId: "00000000-0000-0000-0000-000000000000", Tags: []string{"space"}})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("RemoveTags of unknown movie code = %v, want %v", got, codes.NotFound)
+	}
+}
+
+func TestListTags(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	alien := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	aliens := createMovie(t, srv, "Aliens", "Sci-Fi", "James Cameron", 1986)
+	heat := createMovie(t, srv, "Heat", "Crime", "Michael Mann", 1995)
+
+	tags := map[string][]string{
+		alien:  {"space", "horror"},
+		aliens: {"space", "action"},
+		heat:   {"action", "heist"},
+	}
+	for id, movieTags := range tags {
+		if _, err := srv.AddTags(ctx, &pb.AddTagsRequest{MovieId: id, Tags: movieTags}); err != nil {
+			t.Fatalf("AddTags error = %v", err)
+		}
+	}
+	// Unused tags are not listed
+	if _, err := srv.RemoveTags(ctx, &pb.RemoveTagsRequest{MovieId: alien, Tags: []string{"horror"}}); err != nil {
+		t.Fatalf("RemoveTags error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		req      *pb.ListTagsRequest
+		want     []string
+		wantCode codes.Code
+	}{
+		{name: "most used first", req: &pb.ListTagsRequest{}, want: []string{"action:2", "space:2", "heist:1"}},
+		{name: "prefix", req: &pb.ListTagsRequest{Prefix: "H"}, want: []string{"heist:1"}},
+		{name: "limit", req: &pb.ListTagsRequest{Limit: 1}, want: []string{"action:2"}},
+		{name: "limit too large", req: &pb.ListTagsRequest{Limit: 1001}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.ListTags(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("ListTags code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			got := make([]string, 0, len(resp.GetTags()))
+			for _, tag := range resp.GetTags() {
+				got = append(got, fmt.Sprintf("%s:%d", tag.GetName(), tag.GetCount()))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ListTags = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListMoviesByTags(t *testing.T) {
+	ctx := context.Background()
+	srv := newMemoryServer()
+	alien := createMovie(t, srv, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	aliens := createMovie(t, srv, "Aliens", "Sci-Fi", "James Cameron", 1986)
+	createMovie(t, srv, "Heat", "Crime", "Michael Mann", 1995)
+
+	for id, tags := range map[string][]string{alien: {"space", "horror"}, aliens: {"space", "action"}} {
+		if _, err := srv.AddTags(ctx, &pb.AddTagsRequest{MovieId: id, Tags: tags}); err != nil {
+			t.Fatalf("AddTags error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter *pb.MovieFilter
+		want   []string
+	}{
+		{name: "all of tags", filter: &pb.MovieFilter{TagsAll: []string{"Space", "horror"}}, want: []string{"Alien"}},
+		{name: "any of tags", filter: &pb.MovieFilter{TagsAny: []string{"horror", "ACTION"}}, want: []string{"Alien", "Aliens"}},
+		{name: "all and any", filter: &pb.MovieFilter{TagsAll: []string{"space"}, TagsAny: []string{"action"}}, want: []string{"Aliens"}},
+		{name: "unknown tag", filter: &pb.MovieFilter{TagsAll: []string{"noir"}}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.ListMovies(ctx, &pb.ListMoviesRequest{Filter: tt.filter})
+			if err != nil {
+				t.Fatalf("ListMovies error = %v", err)
+			}
+
+			got := make([]string, 0, len(resp.GetMovies()))
+			for _, movie := range resp.GetMovies() {
+				got = append(got, movie.GetTitle())
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ListMovies titles = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
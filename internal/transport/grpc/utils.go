@@ -4,6 +4,9 @@ import (
 	"movie-service/internal/model"
 	"movie-service/internal/transport/dto"
 	"movie-service/pkg/pb"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func toPb(movie *model.Movie) *pb.Movie {
@@ -13,6 +16,12 @@ func toPb(movie *model.Movie) *pb.Movie {
 		Genre:    movie.Genre,
 		Director: movie.Director,
 		Year:     movie.Year,
+
+		AverageRating: movie.AverageRating(),
+		RatingCount:   movie.RatingCount,
+		ReviewCount:   movie.ReviewCount,
+		CollectionIds: movie.CollectionIDs,
+		Tags:          movie.Tags,
 	}
 }
 
@@ -34,3 +43,343 @@ func pbToUpdate(in *pb.UpdateMovieRequest) *dto.UpdateMovieRequest {
 		Year:     in.GetYear(),
 	}
 }
+
+func pbToRate(in *pb.RateMovieRequest) *dto.RateMovieRequest {
+	return &dto.RateMovieRequest{
+		MovieID: in.GetMovieId(),
+		UserID:  in.GetUserId(),
+		Score:   in.GetScore(),
+	}
+}
+
+var reviewStatusToPb = map[model.ReviewStatus]pb.ReviewStatus{
+	model.ReviewStatusPending:  pb.ReviewStatus_REVIEW_STATUS_PENDING,
+	model.ReviewStatusApproved: pb.ReviewStatus_REVIEW_STATUS_APPROVED,
+	model.ReviewStatusRejected: pb.ReviewStatus_REVIEW_STATUS_REJECTED,
+}
+
+var pbToReviewStatus = map[pb.ReviewStatus]model.ReviewStatus{
+	pb.ReviewStatus_REVIEW_STATUS_PENDING:  model.ReviewStatusPending,
+	pb.ReviewStatus_REVIEW_STATUS_APPROVED: model.ReviewStatusApproved,
+	pb.ReviewStatus_REVIEW_STATUS_REJECTED: model.ReviewStatusRejected,
+}
+
+func reviewToPb(review *model.Review) *pb.Review {
+	return &pb.Review{
+		Id:        review.ID,
+		MovieId:   review.MovieID,
+		UserId:    review.UserID,
+		Text:      review.Text,
+		Status:    reviewStatusToPb[review.Status],
+		CreatedAt: timestamppb.New(review.CreatedAt),
+		UpdatedAt: timestamppb.New(review.UpdatedAt),
+	}
+}
+
+func pbToCreateReview(in *pb.CreateReviewRequest) *dto.CreateReviewRequest {
+	return &dto.CreateReviewRequest{
+		MovieID: in.GetMovieId(),
+		UserID:  in.GetUserId(),
+		Text:    in.GetText(),
+	}
+}
+
+func pbToListReviews(in *pb.ListReviewsRequest) *dto.ListReviewsRequest {
+	return &dto.ListReviewsRequest{
+		MovieID: in.GetMovieId(),
+		Page: dto.Page{
+			Page:     in.GetPage(),
+			PageSize: in.GetPageSize(),
+		},
+	}
+}
+
+func pbToUpdateReview(in *pb.UpdateReviewRequest) *dto.UpdateReviewRequest {
+	return &dto.UpdateReviewRequest{
+		ID:     in.GetId(),
+		UserID: in.GetUserId(),
+		Text:   in.GetText(),
+	}
+}
+
+func pbToDeleteReview(in *pb.DeleteReviewRequest) *dto.DeleteReviewRequest {
+	return &dto.DeleteReviewRequest{
+		ID:     in.GetId(),
+		UserID: in.GetUserId(),
+	}
+}
+
+func pbToModerateReview(in *pb.ModerateReviewRequest) *dto.ModerateReviewRequest {
+	return &dto.ModerateReviewRequest{
+		ID:     in.GetId(),
+		Status: pbToReviewStatus[in.GetStatus()],
+	}
+}
+
+func watchlistEntryToPb(entry *model.WatchlistEntry) *pb.WatchlistEntry {
+	pbEntry := &pb.WatchlistEntry{
+		MovieId:      entry.MovieID,
+		AddedAt:      timestamppb.New(entry.AddedAt),
+		MovieDeleted: entry.Movie == nil,
+	}
+	if entry.Movie != nil {
+		pbEntry.Movie = toPb(entry.Movie)
+	}
+
+	return pbEntry
+}
+
+func watchHistoryEntryToPb(entry *model.WatchHistoryEntry) *pb.WatchHistoryEntry {
+	pbEntry := &pb.WatchHistoryEntry{
+		Id:           entry.ID,
+		MovieId:      entry.MovieID,
+		WatchedAt:    timestamppb.New(entry.WatchedAt),
+		Progress:     entry.Progress,
+		MovieDeleted: entry.Movie == nil,
+	}
+	if entry.Movie != nil {
+		pbEntry.Movie = toPb(entry.Movie)
+	}
+
+	return pbEntry
+}
+
+func pbToRecordWatch(in *pb.RecordWatchRequest) *dto.RecordWatchRequest {
+	req := &dto.RecordWatchRequest{
+		UserID:   in.GetUserId(),
+		MovieID:  in.GetMovieId(),
+		Progress: in.GetProgress(),
+	}
+	if in.GetWatchedAt() != nil {
+		req.WatchedAt = in.GetWatchedAt().AsTime()
+	}
+
+	return req
+}
+
+var collectionKindToPb = map[model.CollectionKind]pb.CollectionKind{
+	model.CollectionKindFranchise: pb.CollectionKind_COLLECTION_KIND_FRANCHISE,
+	model.CollectionKindCurated:   pb.CollectionKind_COLLECTION_KIND_CURATED,
+}
+
+var pbToCollectionKind = map[pb.CollectionKind]model.CollectionKind{
+	pb.CollectionKind_COLLECTION_KIND_FRANCHISE: model.CollectionKindFranchise,
+	pb.CollectionKind_COLLECTION_KIND_CURATED:   model.CollectionKindCurated,
+}
+
+func collectionToPb(collection *model.Collection) *pb.Collection {
+	return &pb.Collection{
+		Id:          collection.ID,
+		Name:        collection.Name,
+		Description: collection.Description,
+		Kind:        collectionKindToPb[collection.Kind],
+		MovieIds:    collection.MovieIDs,
+		CreatedAt:   timestamppb.New(collection.CreatedAt),
+		UpdatedAt:   timestamppb.New(collection.UpdatedAt),
+	}
+}
+
+func pbToCreateCollection(in *pb.CreateCollectionRequest) *dto.CreateCollectionRequest {
+	return &dto.CreateCollectionRequest{
+		Name:        in.GetName(),
+		Description: in.GetDescription(),
+		Kind:        pbToCollectionKind[in.GetKind()],
+		MovieIDs:    in.GetMovieIds(),
+	}
+}
+
+func pbToUpdateCollection(in *pb.UpdateCollectionRequest) *dto.UpdateCollectionRequest {
+	return &dto.UpdateCollectionRequest{
+		ID:          in.GetId(),
+		Name:        in.GetName(),
+		Description: in.GetDescription(),
+		Kind:        pbToCollectionKind[in.GetKind()],
+	}
+}
+
+func pbToSetCollectionMovies(in *pb.SetCollectionMoviesRequest) *dto.SetCollectionMoviesRequest {
+	return &dto.SetCollectionMoviesRequest{
+		ID:       in.GetId(),
+		MovieIDs: in.GetMovieIds(),
+	}
+}
+
+func pbToFilter(in *pb.MovieFilter) dto.MovieFilter {
+	return dto.MovieFilter{
+		TagsAll:  dto.NormalizeTags(in.GetTagsAll()),
+		TagsAny:  dto.NormalizeTags(in.GetTagsAny()),
+		Genre:    strings.TrimSpace(in.GetGenre()),
+		Director: strings.TrimSpace(in.GetDirector()),
+		YearFrom: in.GetYearFrom(),
+		YearTo:   in.GetYearTo(),
+	}
+}
+
+func pbToListMovies(in *pb.ListMoviesRequest) *dto.ListMoviesRequest {
+	return &dto.ListMoviesRequest{
+		Filter: pbToFilter(in.GetFilter()),
+		Page: dto.Page{
+			Page:     in.GetPage(),
+			PageSize: in.GetPageSize(),
+		},
+	}
+}
+
+func tagToPb(tag *model.Tag) *pb.Tag {
+	return &pb.Tag{
+		Name:  tag.Name,
+		Count: tag.UsageCount,
+	}
+}
+
+var facetToPb = map[model.Facet]pb.Facet{
+	model.FacetGenre:    pb.Facet_FACET_GENRE,
+	model.FacetDirector: pb.Facet_FACET_DIRECTOR,
+	model.FacetTag:      pb.Facet_FACET_TAG,
+	model.FacetYear:     pb.Facet_FACET_YEAR,
+	model.FacetRating:   pb.Facet_FACET_RATING,
+}
+
+var pbToFacet = map[pb.Facet]model.Facet{
+	pb.Facet_FACET_GENRE:    model.FacetGenre,
+	pb.Facet_FACET_DIRECTOR: model.FacetDirector,
+	pb.Facet_FACET_TAG:      model.FacetTag,
+	pb.Facet_FACET_YEAR:     model.FacetYear,
+	pb.Facet_FACET_RATING:   model.FacetRating,
+}
+
+func pbToGetCatalogStats(in *pb.GetCatalogStatsRequest) *dto.GetCatalogStatsRequest {
+	req := &dto.GetCatalogStatsRequest{
+		Filter:     pbToFilter(in.GetFilter()),
+		TopLimit:   in.GetTopLimit(),
+		YearBucket: in.GetYearBucket(),
+	}
+	for _, facet := range in.GetFacets() {
+		req.Facets = append(req.Facets, pbToFacet[facet])
+	}
+
+	return req
+}
+
+func catalogStatsToPb(stats *model.CatalogStats) *pb.GetCatalogStatsResponse {
+	resp := &pb.GetCatalogStatsResponse{
+		TotalMovies: stats.TotalMovies,
+		Facets:      make([]*pb.FacetCounts, 0, len(stats.Facets)),
+	}
+	for _, facet := range stats.Facets {
+		counts := &pb.FacetCounts{
+			Facet:   facetToPb[facet.Facet],
+			Buckets: make([]*pb.FacetBucket, 0, len(facet.Buckets)),
+		}
+		for _, bucket := range facet.Buckets {
+			counts.Buckets = append(counts.Buckets, &pb.FacetBucket{
+				Value: bucket.Value,
+				Count: bucket.Count,
+				From:  bucket.From,
+				To:    bucket.To,
+			})
+		}
+		resp.Facets = append(resp.Facets, counts)
+	}
+
+	return resp
+}
+
+func similarToPb(movie *model.SimilarMovie) *pb.SimilarMovie {
+	return &pb.SimilarMovie{
+		Movie:   toPb(&movie.Movie),
+		Score:   movie.Score,
+		Reasons: movie.Reasons,
+	}
+}
+
+var movieEventTypeToPb = map[model.MovieEventType]pb.MovieEventType{
+	model.MovieEventCreated: pb.MovieEventType_MOVIE_EVENT_TYPE_CREATED,
+	model.MovieEventUpdated: pb.MovieEventType_MOVIE_EVENT_TYPE_UPDATED,
+	model.MovieEventDeleted: pb.MovieEventType_MOVIE_EVENT_TYPE_DELETED,
+}
+
+func movieEventToPb(event *model.MovieEvent) *pb.MovieEvent {
+	return &pb.MovieEvent{
+		Sequence:   event.Seq,
+		Type:       movieEventTypeToPb[event.Type],
+		Movie:      toPb(&event.Movie),
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}
+}
+
+var pbToMovieEventType = map[pb.MovieEventType]model.MovieEventType{
+	pb.MovieEventType_MOVIE_EVENT_TYPE_CREATED: model.MovieEventCreated,
+	pb.MovieEventType_MOVIE_EVENT_TYPE_UPDATED: model.MovieEventUpdated,
+	pb.MovieEventType_MOVIE_EVENT_TYPE_DELETED: model.MovieEventDeleted,
+}
+
+var deliveryStatusToPb = map[model.DeliveryStatus]pb.DeliveryStatus{
+	model.DeliveryStatusPending:   pb.DeliveryStatus_DELIVERY_STATUS_PENDING,
+	model.DeliveryStatusDelivered: pb.DeliveryStatus_DELIVERY_STATUS_DELIVERED,
+	model.DeliveryStatusDead:      pb.DeliveryStatus_DELIVERY_STATUS_DEAD,
+}
+
+var pbToDeliveryStatus = map[pb.DeliveryStatus]model.DeliveryStatus{
+	pb.DeliveryStatus_DELIVERY_STATUS_PENDING:   model.DeliveryStatusPending,
+	pb.DeliveryStatus_DELIVERY_STATUS_DELIVERED: model.DeliveryStatusDelivered,
+	pb.DeliveryStatus_DELIVERY_STATUS_DEAD:      model.DeliveryStatusDead,
+}
+
+func webhookToPb(webhook *model.Webhook) *pb.Webhook {
+	eventTypes := make([]pb.MovieEventType, 0, len(webhook.EventTypes))
+	for _, t := range webhook.EventTypes {
+		eventTypes = append(eventTypes, movieEventTypeToPb[t])
+	}
+
+	return &pb.Webhook{
+		Id:         webhook.ID,
+		Url:        webhook.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  timestamppb.New(webhook.CreatedAt),
+	}
+}
+
+func deliveryToPb(delivery *model.WebhookDelivery) *pb.WebhookDelivery {
+	out := &pb.WebhookDelivery{
+		Id:             delivery.ID,
+		WebhookId:      delivery.WebhookID,
+		EventSequence:  delivery.EventSeq,
+		EventType:      movieEventTypeToPb[delivery.EventType],
+		Status:         deliveryStatusToPb[delivery.Status],
+		Attempts:       delivery.Attempts,
+		LastError:      delivery.LastError,
+		ResponseStatus: int32(delivery.ResponseStatus),
+		CreatedAt:      timestamppb.New(delivery.CreatedAt),
+		NextAttemptAt:  timestamppb.New(delivery.NextAttemptAt),
+	}
+	if delivery.DeliveredAt != nil {
+		out.DeliveredAt = timestamppb.New(*delivery.DeliveredAt)
+	}
+
+	return out
+}
+
+func pbToCreateWebhook(in *pb.CreateWebhookRequest) *dto.CreateWebhookRequest {
+	eventTypes := make([]model.MovieEventType, 0, len(in.GetEventTypes()))
+	for _, t := range in.GetEventTypes() {
+		eventTypes = append(eventTypes, pbToMovieEventType[t])
+	}
+
+	return &dto.CreateWebhookRequest{
+		URL:        in.GetUrl(),
+		Secret:     in.GetSecret(),
+		EventTypes: eventTypes,
+	}
+}
+
+func pbToListDeliveries(in *pb.ListDeliveriesRequest) *dto.ListDeliveriesRequest {
+	return &dto.ListDeliveriesRequest{
+		WebhookID: in.GetWebhookId(),
+		Status:    pbToDeliveryStatus[in.GetStatus()],
+		Page: dto.Page{
+			Page:     in.GetPage(),
+			PageSize: in.GetPageSize(),
+		},
+	}
+}
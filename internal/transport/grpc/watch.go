@@ -0,0 +1,52 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (srv *server) WatchMovies(in *pb.WatchMoviesRequest, stream pb.MovieService_WatchMoviesServer) error {
+	const op = "transport.grpc.WatchMovies"
+	ctx := stream.Context()
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	log.Debug("Got start of watching", slog.Any("AfterSequence", in.AfterSequence))
+
+	var sendErr error
+	send := func(event *model.MovieEvent) error {
+		sendErr = stream.Send(movieEventToPb(event))
+		return sendErr
+	}
+
+	log.Debug("Starting stream...")
+	err := srv.service.WatchMovies(ctx, in.AfterSequence, send)
+	switch {
+	case sendErr != nil:
+		log.Error("Error during streaming movie events", sl.Err(sendErr))
+		return sendErr
+	case errors.Is(err, context.Canceled):
+		log.Debug("Finished stream")
+		return status.Error(codes.Canceled, "watching cancelled")
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Debug("Finished stream")
+		return status.Error(codes.DeadlineExceeded, "watching deadline exceeded")
+	case errors.Is(err, repo.ErrEventsPurged):
+		log.Warn("Events to watch are purged", sl.Err(err))
+		return status.Error(codes.OutOfRange, "events after the sequence are purged")
+	default:
+		log.Error("Failed to watch movies", sl.Err(err))
+		return status.Error(codes.Internal, "failed to watch movies")
+	}
+}
@@ -0,0 +1,37 @@
+package moviegrpc
+
+import (
+	"context"
+	memoryrepo "movie-service/internal/repository/memory"
+	"movie-service/internal/service/movieservice"
+	"movie-service/pkg/pb"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWatchMoviesPurged(t *testing.T) {
+	ctx := context.Background()
+	repo := memoryrepo.New(memoryrepo.WithoutOutbox())
+	srv := newServer(movieservice.New(repo, repo.Notifier()))
+	for _, title := range []string{"Alien", "Aliens", "Alien 3"} {
+		createMovie(t, srv, title, "Sci-Fi", "Director", 1979)
+	}
+
+	if _, err := repo.PurgeMovieEvents(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("PurgeMovieEvents error = %v", err)
+	}
+
+	// Resuming from purged events fails instead of skipping them
+	afterSeq := uint64(1)
+	stream := &fakeStream[pb.MovieEvent]{ctx: ctx}
+	err := srv.WatchMovies(&pb.WatchMoviesRequest{AfterSequence: &afterSeq}, stream)
+	if got := status.Code(err); got != codes.OutOfRange {
+		t.Errorf("WatchMovies code = %v, want %v (error %v)", got, codes.OutOfRange, err)
+	}
+	if len(stream.sent) != 0 {
+		t.Errorf("sent %d events, want none", len(stream.sent))
+	}
+}
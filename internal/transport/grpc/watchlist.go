@@ -0,0 +1,225 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type WatchlistService interface {
+	AddToWatchlist(ctx context.Context, userID, movieID string) (bool, error)
+	RemoveFromWatchlist(ctx context.Context, userID, movieID string) (bool, error)
+	ListWatchlist(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchlistEntry, error)
+	RecordWatch(ctx context.Context, entry *model.WatchHistoryEntry) (*model.WatchHistoryEntry, error)
+	ListWatchHistory(ctx context.Context, userID string, limit, offset uint64) ([]model.WatchHistoryEntry, error)
+}
+
+type watchlistServer struct {
+	pb.UnimplementedWatchlistServiceServer
+	l        *slog.Logger
+	service  WatchlistService
+	validate *validator.Validate
+}
+
+func RegisterWatchlist(gRPCServer *grpc.Server, log *slog.Logger, service WatchlistService) {
+	pb.RegisterWatchlistServiceServer(gRPCServer, &watchlistServer{
+		l:        log,
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	})
+}
+
+func (srv *watchlistServer) AddToWatchlist(ctx context.Context, in *pb.AddToWatchlistRequest) (*pb.AddToWatchlistResponse, error) {
+	const op = "transport.grpc.AddToWatchlist"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.WatchlistRequest{UserID: in.GetUserId(), MovieID: in.GetMovieId()}
+
+	// Add request validation
+	log.Debug("Validating AddToWatchlistRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Add movie to watchlist through the service layer
+	log.Debug("Adding movie to watchlist")
+	added, err := srv.service.AddToWatchlist(ctx, req.UserID, req.MovieID)
+	if err != nil {
+		log.Error("Failed to add movie to watchlist", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to add movie to watchlist")
+	}
+
+	log.Debug("Successfully added movie to watchlist", slog.Bool("added", added))
+
+	return &pb.AddToWatchlistResponse{Added: added}, nil
+}
+
+func (srv *watchlistServer) RemoveFromWatchlist(ctx context.Context, in *pb.RemoveFromWatchlistRequest) (*pb.RemoveFromWatchlistResponse, error) {
+	const op = "transport.grpc.RemoveFromWatchlist"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.WatchlistRequest{UserID: in.GetUserId(), MovieID: in.GetMovieId()}
+
+	// Remove request validation
+	log.Debug("Validating RemoveFromWatchlistRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Remove movie from watchlist through the service layer
+	log.Debug("Removing movie from watchlist")
+	ok, err := srv.service.RemoveFromWatchlist(ctx, req.UserID, req.MovieID)
+	if err != nil {
+		log.Error("Failed to remove movie from watchlist", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to remove movie from watchlist")
+	}
+
+	log.Debug("Removed movie from watchlist", slog.Bool("removed", ok))
+
+	return &pb.RemoveFromWatchlistResponse{Success: ok}, nil
+}
+
+func (srv *watchlistServer) ListWatchlist(ctx context.Context, in *pb.ListWatchlistRequest) (*pb.ListWatchlistResponse, error) {
+	const op = "transport.grpc.ListWatchlist"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.ListByUserRequest{
+		UserID: in.GetUserId(),
+		Page:   dto.Page{Page: in.GetPage(), PageSize: in.GetPageSize()},
+	}
+
+	// List request validation
+	log.Debug("Validating ListWatchlistRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Get watchlist through the service layer
+	log.Debug("Getting page of watchlist")
+	entries, err := srv.service.ListWatchlist(ctx, req.UserID, req.Limit(), req.Offset())
+	if err != nil {
+		log.Error("Failed to get watchlist", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to get watchlist")
+	}
+
+	log.Debug("Successfully got watchlist", slog.Int("count", len(entries)))
+
+	resp := &pb.ListWatchlistResponse{Entries: make([]*pb.WatchlistEntry, 0, len(entries))}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, watchlistEntryToPb(&entry))
+	}
+
+	return resp, nil
+}
+
+func (srv *watchlistServer) RecordWatch(ctx context.Context, in *pb.RecordWatchRequest) (*pb.RecordWatchResponse, error) {
+	const op = "transport.grpc.RecordWatch"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := pbToRecordWatch(in)
+	log.Debug("Converted RecordWatchRequest to dto", slog.Any("Request", req))
+
+	// Record request validation
+	log.Debug("Validating RecordWatchRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Save watch history entry through the service layer
+	log.Debug("Recording watch")
+	entry, err := srv.service.RecordWatch(ctx, req.ToModel())
+	if err != nil {
+		log.Error("Failed to record watch", sl.Err(err))
+
+		if errors.Is(err, repo.ErrMovieNotExists) {
+			return nil, status.Error(codes.NotFound, "movie not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to record watch")
+	}
+
+	log.Debug("Successfully recorded watch", slog.String("entry_id", entry.ID))
+
+	return &pb.RecordWatchResponse{Entry: watchHistoryEntryToPb(entry)}, nil
+}
+
+func (srv *watchlistServer) ListWatchHistory(ctx context.Context, in *pb.ListWatchHistoryRequest) (*pb.ListWatchHistoryResponse, error) {
+	const op = "transport.grpc.ListWatchHistory"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.ListByUserRequest{
+		UserID: in.GetUserId(),
+		Page:   dto.Page{Page: in.GetPage(), PageSize: in.GetPageSize()},
+	}
+
+	// List request validation
+	log.Debug("Validating ListWatchHistoryRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Get watch history through the service layer
+	log.Debug("Getting page of watch history")
+	entries, err := srv.service.ListWatchHistory(ctx, req.UserID, req.Limit(), req.Offset())
+	if err != nil {
+		log.Error("Failed to get watch history", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to get watch history")
+	}
+
+	log.Debug("Successfully got watch history", slog.Int("count", len(entries)))
+
+	resp := &pb.ListWatchHistoryResponse{Entries: make([]*pb.WatchHistoryEntry, 0, len(entries))}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, watchHistoryEntryToPb(&entry))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,150 @@
+package moviegrpc
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	memoryrepo "movie-service/internal/repository/memory"
+	"movie-service/internal/service/movieservice"
+	"movie-service/internal/service/watchlistservice"
+	"movie-service/pkg/pb"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newWatchlistServer returns watchlist server and movie server sharing
+// in-memory repository
+func newWatchlistServer() (*watchlistServer, *server) {
+	repo := memoryrepo.New()
+	srv := &watchlistServer{
+		l:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		service:  watchlistservice.New(repo),
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+
+	return srv, newServer(movieservice.New(repo, repo.Notifier()))
+}
+
+func TestWatchlist(t *testing.T) {
+	ctx := context.Background()
+	srv, movies := newWatchlistServer()
+	alien := createMovie(t, movies, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	heat := createMovie(t, movies, "Heat", "Crime", "Michael Mann", 1995)
+
+	tests := []struct {
+		name      string
+		req       *pb.AddToWatchlistRequest
+		wantCode  codes.Code
+		wantAdded bool
+	}{
+		{name: "added", req: &pb.AddToWatchlistRequest{UserId: "alice", MovieId: alien}, wantAdded: true},
+		{name: "added twice", req: &pb.AddToWatchlistRequest{UserId: "alice", MovieId: alien}, wantAdded: false},
+		{name: "another movie", req: &pb.AddToWatchlistRequest{UserId: "alice", MovieId: heat}, wantAdded: true},
+		{name: "missing user", req: &pb.AddToWatchlistRequest{MovieId: alien}, wantCode: codes.InvalidArgument},
+		{name: "invalid movie id", req: &pb.AddToWatchlistRequest{UserId: "alice", MovieId: "alien"}, wantCode: codes.InvalidArgument},
+		{
+			name:     "unknown movie",
+			req:      &pb.AddToWatchlistRequest{UserId: "alice", MovieId: "00000000-0000-0000-0000-000000000000"},
+			wantCode: codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.AddToWatchlist(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("AddToWatchlist code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if resp.GetAdded() != tt.wantAdded {
+				t.Errorf("Added = %v, want %v", resp.GetAdded(), tt.wantAdded)
+			}
+		})
+	}
+
+	// Movie deleted from catalog stays in the list, marked as deleted
+	if _, err := movies.DeleteMovie(ctx, &pb.DeleteMovieRequest{Id: alien}); err != nil {
+		t.Fatalf("DeleteMovie error = %v", err)
+	}
+
+	list, err := srv.ListWatchlist(ctx, &pb.ListWatchlistRequest{UserId: "alice"})
+	if err != nil {
+		t.Fatalf("ListWatchlist error = %v", err)
+	}
+	deleted := make(map[string]bool)
+	for _, entry := range list.GetEntries() {
+		deleted[entry.GetMovieId()] = entry.GetMovieDeleted()
+		if entry.GetMovieDeleted() != (entry.GetMovie() == nil) {
+			t.Errorf("entry of %s has MovieDeleted %v and movie %v", entry.GetMovieId(), entry.GetMovieDeleted(), entry.GetMovie())
+		}
+	}
+	if len(deleted) != 2 || !deleted[alien] || deleted[heat] {
+		t.Errorf("ListWatchlist deleted movies = %v, want only %s of 2 movies", deleted, alien)
+	}
+
+	removed, err := srv.RemoveFromWatchlist(ctx, &pb.RemoveFromWatchlistRequest{UserId: "alice", MovieId: heat})
+	if err != nil || !removed.GetSuccess() {
+		t.Fatalf("RemoveFromWatchlist = %v, %v, want success", removed, err)
+	}
+	removed, err = srv.RemoveFromWatchlist(ctx, &pb.RemoveFromWatchlistRequest{UserId: "alice", MovieId: heat})
+	if err != nil || removed.GetSuccess() {
+		t.Fatalf("RemoveFromWatchlist of removed movie = %v, %v, want no success", removed, err)
+	}
+
+	if _, err := srv.ListWatchlist(ctx, &pb.ListWatchlistRequest{UserId: "alice", PageSize: 101}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListWatchlist with too large page code = %v, want %v", status.Code(err), codes.InvalidArgument)
+	}
+}
+
+func TestRecordWatch(t *testing.T) {
+	ctx := context.Background()
+	srv, movies := newWatchlistServer()
+	alien := createMovie(t, movies, "Alien", "Sci-Fi", "Ridley Scott", 1979)
+	watchedAt := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		req      *pb.RecordWatchRequest
+		wantCode codes.Code
+	}{
+		{name: "partly watched", req: &pb.RecordWatchRequest{UserId: "alice", MovieId: alien, WatchedAt: timestamppb.New(watchedAt), Progress: 40}},
+		{name: "watched to the end now", req: &pb.RecordWatchRequest{UserId: "alice", MovieId: alien, Progress: 100}},
+		{name: "progress above 100", req: &pb.RecordWatchRequest{UserId: "alice", MovieId: alien, Progress: 101}, wantCode: codes.InvalidArgument},
+		{name: "missing user", req: &pb.RecordWatchRequest{MovieId: alien}, wantCode: codes.InvalidArgument},
+		{
+			name:     "unknown movie",
+			req:      &pb.RecordWatchRequest{UserId: "alice", MovieId: "00000000-0000-0000-0000-000000000000"},
+			wantCode: codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.RecordWatch(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("RecordWatch code = %v, want %v (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			entry := resp.GetEntry()
+			if entry.GetProgress() != tt.req.GetProgress() || entry.GetMovie().GetTitle() != "Alien" {
+				t.Errorf("RecordWatch = %v, want entry of Alien with progress %d", entry, tt.req.GetProgress())
+			}
+			if tt.req.GetWatchedAt() != nil && !entry.GetWatchedAt().AsTime().Equal(watchedAt) {
+				t.Errorf("WatchedAt = %v, want %v", entry.GetWatchedAt().AsTime(), watchedAt)
+			}
+		})
+	}
+
+	history, err := srv.ListWatchHistory(ctx, &pb.ListWatchHistoryRequest{UserId: "alice"})
+	if err != nil {
+		t.Fatalf("ListWatchHistory error = %v", err)
+	}
+	if entries := history.GetEntries(); len(entries) != 2 || entries[0].GetProgress() != 100 || entries[1].GetProgress() != 40 {
+		t.Errorf("ListWatchHistory = %v, want 2 entries, most recent first", entries)
+	}
+}
@@ -0,0 +1,217 @@
+package moviegrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"movie-service/internal/model"
+	repo "movie-service/internal/repository"
+	"movie-service/internal/transport/dto"
+	"movie-service/pkg/pb"
+	"movie-service/pkg/sl"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) (bool, error)
+	ListDeliveries(ctx context.Context, webhookID string, status model.DeliveryStatus, limit, offset uint64) ([]model.WebhookDelivery, error)
+	RedeliverEvent(ctx context.Context, webhookID string, eventSeq uint64) (*model.WebhookDelivery, error)
+}
+
+type webhookServer struct {
+	pb.UnimplementedWebhookServiceServer
+	l        *slog.Logger
+	service  WebhookService
+	validate *validator.Validate
+}
+
+func RegisterWebhook(gRPCServer *grpc.Server, log *slog.Logger, service WebhookService) {
+	pb.RegisterWebhookServiceServer(gRPCServer, &webhookServer{
+		l:        log,
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	})
+}
+
+func (srv *webhookServer) CreateWebhook(ctx context.Context, in *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	const op = "transport.grpc.CreateWebhook"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := pbToCreateWebhook(in)
+	log.Debug("Converted CreateWebhookRequest to dto", slog.String("URL", req.URL), slog.Any("EventTypes", req.EventTypes))
+
+	// Add request validation
+	log.Debug("Validating CreateWebhookRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Register webhook through the service layer
+	log.Debug("Creating webhook")
+	webhook, err := srv.service.CreateWebhook(ctx, req.ToModel())
+	if err != nil {
+		log.Error("Failed to create webhook", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to create webhook")
+	}
+
+	log.Debug("Successfully created webhook", slog.String("ID", webhook.ID))
+
+	return &pb.CreateWebhookResponse{Webhook: webhookToPb(webhook)}, nil
+}
+
+func (srv *webhookServer) ListWebhooks(ctx context.Context, _ *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	const op = "transport.grpc.ListWebhooks"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	log.Debug("Getting webhooks")
+	webhooks, err := srv.service.ListWebhooks(ctx)
+	if err != nil {
+		log.Error("Failed to get webhooks", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to get webhooks")
+	}
+
+	log.Debug("Successfully got webhooks", slog.Int("count", len(webhooks)))
+
+	resp := &pb.ListWebhooksResponse{Webhooks: make([]*pb.Webhook, 0, len(webhooks))}
+	for _, webhook := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, webhookToPb(&webhook))
+	}
+
+	return resp, nil
+}
+
+func (srv *webhookServer) DeleteWebhook(ctx context.Context, in *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	const op = "transport.grpc.DeleteWebhook"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	id := in.GetId()
+	log.Debug("Got webhook ID", slog.String("ID", id))
+
+	// Check whether it's valid uuid
+	log.Debug("Validating webhook ID")
+	if err := srv.validate.Var(id, "uuid"); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Delete webhook through the service layer
+	log.Debug("Deleting webhook by ID")
+	ok, err := srv.service.DeleteWebhook(ctx, id)
+	if err != nil {
+		log.Error("Failed to delete webhook", sl.Err(err))
+
+		return nil, status.Error(codes.Internal, "failed to delete webhook")
+	}
+
+	if ok {
+		log.Debug("Successfully deleted webhook")
+	} else {
+		log.Debug("No webhook with this ID was found", slog.String("ID", id))
+	}
+
+	return &pb.DeleteWebhookResponse{Success: ok}, nil
+}
+
+func (srv *webhookServer) ListDeliveries(ctx context.Context, in *pb.ListDeliveriesRequest) (*pb.ListDeliveriesResponse, error) {
+	const op = "transport.grpc.ListDeliveries"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := pbToListDeliveries(in)
+	log.Debug("Converted ListDeliveriesRequest to dto", slog.Any("ListDeliveriesRequest", req))
+
+	// Add request validation
+	log.Debug("Validating ListDeliveriesRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Get page of deliveries through the service layer
+	log.Debug("Getting deliveries of webhook")
+	deliveries, err := srv.service.ListDeliveries(ctx, req.WebhookID, req.Status, req.Limit(), req.Offset())
+	if err != nil {
+		log.Error("Failed to get deliveries", sl.Err(err))
+
+		if errors.Is(err, repo.ErrWebhookNotExists) {
+			return nil, status.Error(codes.NotFound, "webhook not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to get deliveries")
+	}
+
+	log.Debug("Successfully got deliveries", slog.Int("count", len(deliveries)))
+
+	resp := &pb.ListDeliveriesResponse{Deliveries: make([]*pb.WebhookDelivery, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, deliveryToPb(&delivery))
+	}
+
+	return resp, nil
+}
+
+func (srv *webhookServer) RedeliverEvent(ctx context.Context, in *pb.RedeliverEventRequest) (*pb.RedeliverEventResponse, error) {
+	const op = "transport.grpc.RedeliverEvent"
+
+	log := srv.l.With(
+		slog.String("op", op),
+		slog.Any("request_id", ctx.Value(reqIDKey)),
+	)
+
+	req := &dto.RedeliverEventRequest{WebhookID: in.GetWebhookId(), EventSeq: in.GetEventSequence()}
+
+	// Add request validation
+	log.Debug("Validating RedeliverEventRequest")
+	if err := srv.validate.Struct(req); err != nil {
+		log.Error("Validation failed", sl.Err(err))
+
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	// Schedule delivery through the service layer
+	log.Debug("Scheduling redelivery of event")
+	delivery, err := srv.service.RedeliverEvent(ctx, req.WebhookID, req.EventSeq)
+	if err != nil {
+		log.Error("Failed to schedule redelivery", sl.Err(err))
+
+		if errors.Is(err, repo.ErrWebhookNotExists) {
+			return nil, status.Error(codes.NotFound, "webhook not found")
+		}
+		if errors.Is(err, repo.ErrEventNotExists) {
+			return nil, status.Error(codes.NotFound, "event not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to schedule redelivery")
+	}
+
+	log.Debug("Successfully scheduled redelivery", slog.String("ID", delivery.ID))
+
+	return &pb.RedeliverEventResponse{Delivery: deliveryToPb(delivery)}, nil
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS ratings;
+
+ALTER TABLE movies
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating_sum;
+
+ALTER TABLE movies DROP CONSTRAINT IF EXISTS movies_pkey;
\ No newline at end of file
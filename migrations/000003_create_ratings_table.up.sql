@@ -0,0 +1,14 @@
+ALTER TABLE movies ADD PRIMARY KEY (movie_id);
+
+ALTER TABLE movies
+    ADD COLUMN IF NOT EXISTS rating_sum BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS ratings(
+    movie_id uuid NOT NULL REFERENCES movies (movie_id) ON DELETE CASCADE,
+    user_id VARCHAR NOT NULL,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 1 AND 10),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (movie_id, user_id)
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reviews;
+
+ALTER TABLE movies DROP COLUMN IF EXISTS review_count;
\ No newline at end of file
@@ -0,0 +1,13 @@
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS review_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS reviews(
+    review_id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    movie_id uuid NOT NULL REFERENCES movies (movie_id) ON DELETE CASCADE,
+    user_id VARCHAR NOT NULL,
+    text VARCHAR NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_movie_id_status_created_at ON reviews (movie_id, status, created_at DESC);
\ No newline at end of file
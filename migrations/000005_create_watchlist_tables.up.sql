@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS watchlist(
+    user_id VARCHAR NOT NULL,
+    movie_id uuid NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, movie_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_user_id_added_at ON watchlist (user_id, added_at DESC);
+
+CREATE TABLE IF NOT EXISTS watch_history(
+    entry_id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id VARCHAR NOT NULL,
+    movie_id uuid NOT NULL,
+    watched_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    progress SMALLINT NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watch_history_user_id_watched_at ON watch_history (user_id, watched_at DESC);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS collection_movies;
+
+DROP TABLE IF EXISTS collections;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS collections(
+    collection_id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR NOT NULL,
+    description VARCHAR NOT NULL DEFAULT '',
+    kind VARCHAR NOT NULL CHECK (kind IN ('franchise', 'curated')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS collection_movies(
+    collection_id uuid NOT NULL REFERENCES collections (collection_id) ON DELETE CASCADE,
+    movie_id uuid NOT NULL REFERENCES movies (movie_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (collection_id, movie_id),
+    UNIQUE (collection_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_movies_movie_id ON collection_movies (movie_id);
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS tags(
+    name VARCHAR PRIMARY KEY,
+    usage_count INTEGER NOT NULL DEFAULT 0 CHECK (usage_count >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS movie_tags(
+    movie_id uuid NOT NULL REFERENCES movies (movie_id) ON DELETE CASCADE,
+    tag VARCHAR NOT NULL REFERENCES tags (name) ON DELETE CASCADE,
+    PRIMARY KEY (movie_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_tags_tag ON movie_tags (tag);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_movies_lower_genre;
+
+DROP INDEX IF EXISTS idx_movies_title_trgm;
\ No newline at end of file
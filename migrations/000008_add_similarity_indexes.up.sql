@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_movies_title_trgm ON movies USING gin (title gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_movies_lower_genre ON movies (lower(genre));
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_movies_director_normalized_year;
+
+DROP FUNCTION IF EXISTS normalize_name(text);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent itself is not immutable, so it cannot be used in index expressions directly
+CREATE OR REPLACE FUNCTION normalize_name(name text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+AS $$
+    SELECT lower(btrim(regexp_replace(public.unaccent('public.unaccent'::regdictionary, name), '\s+', ' ', 'g')))
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_movies_director_normalized_year ON movies (normalize_name(director), year);
\ No newline at end of file
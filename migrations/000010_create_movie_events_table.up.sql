@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS movie_events(
+    seq BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR NOT NULL CHECK (event_type IN ('created', 'updated', 'deleted')),
+    movie_id uuid NOT NULL,
+    movie JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS outbox(
+    event_seq BIGINT PRIMARY KEY REFERENCES movie_events(seq) ON DELETE CASCADE,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox(next_attempt_at) WHERE delivered_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS webhooks(
+    webhook_id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    url VARCHAR NOT NULL,
+    secret VARCHAR NOT NULL,
+    event_types VARCHAR[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+    delivery_id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id uuid NOT NULL REFERENCES webhooks(webhook_id) ON DELETE CASCADE,
+    event_seq BIGINT NOT NULL REFERENCES movie_events(seq) ON DELETE CASCADE,
+    status VARCHAR NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    response_status INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
\ No newline at end of file
@@ -0,0 +1,23 @@
+DROP TRIGGER IF EXISTS movie_events_sequence ON movie_events;
+DROP FUNCTION IF EXISTS sequence_movie_events();
+
+ALTER TABLE outbox DROP CONSTRAINT outbox_event_id_fkey;
+ALTER TABLE webhook_deliveries DROP CONSTRAINT webhook_deliveries_event_id_fkey;
+
+ALTER TABLE outbox DROP CONSTRAINT outbox_pkey;
+UPDATE outbox o SET event_id = e.seq FROM movie_events e WHERE e.event_id = o.event_id;
+ALTER TABLE outbox RENAME COLUMN event_id TO event_seq;
+ALTER TABLE outbox ADD PRIMARY KEY (event_seq);
+
+UPDATE webhook_deliveries d SET event_id = e.seq FROM movie_events e WHERE e.event_id = d.event_id;
+ALTER TABLE webhook_deliveries RENAME COLUMN event_id TO event_seq;
+
+DROP INDEX IF EXISTS movie_events_unsequenced_idx;
+ALTER TABLE movie_events DROP CONSTRAINT movie_events_pkey;
+ALTER TABLE movie_events DROP CONSTRAINT movie_events_seq_key;
+ALTER TABLE movie_events DROP COLUMN event_id;
+ALTER TABLE movie_events ALTER COLUMN seq SET DEFAULT nextval('movie_events_seq_seq');
+ALTER TABLE movie_events ADD PRIMARY KEY (seq);
+
+ALTER TABLE outbox ADD FOREIGN KEY (event_seq) REFERENCES movie_events(seq) ON DELETE CASCADE;
+ALTER TABLE webhook_deliveries ADD FOREIGN KEY (event_seq) REFERENCES movie_events(seq) ON DELETE CASCADE;
\ No newline at end of file
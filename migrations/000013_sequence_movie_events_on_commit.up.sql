@@ -0,0 +1,62 @@
+-- Events are referred to by event_id assigned on insert, while seq is assigned
+-- on commit by sequence_movie_events, so writers don't wait for each other
+ALTER TABLE outbox DROP CONSTRAINT outbox_event_seq_fkey;
+ALTER TABLE webhook_deliveries DROP CONSTRAINT webhook_deliveries_event_seq_fkey;
+ALTER TABLE movie_events DROP CONSTRAINT movie_events_pkey;
+
+ALTER TABLE movie_events ADD COLUMN event_id BIGINT;
+UPDATE movie_events SET event_id = seq;
+CREATE SEQUENCE movie_events_event_id_seq OWNED BY movie_events.event_id;
+SELECT setval('movie_events_event_id_seq', COALESCE(max(event_id), 0) + 1, false) FROM movie_events;
+ALTER TABLE movie_events ALTER COLUMN event_id SET DEFAULT nextval('movie_events_event_id_seq');
+ALTER TABLE movie_events ALTER COLUMN event_id SET NOT NULL;
+ALTER TABLE movie_events ADD PRIMARY KEY (event_id);
+
+ALTER TABLE movie_events ALTER COLUMN seq DROP DEFAULT;
+ALTER TABLE movie_events ALTER COLUMN seq DROP NOT NULL;
+ALTER TABLE movie_events ADD UNIQUE (seq);
+CREATE INDEX IF NOT EXISTS movie_events_unsequenced_idx ON movie_events(event_id) WHERE seq IS NULL;
+
+ALTER TABLE outbox RENAME COLUMN event_seq TO event_id;
+ALTER TABLE outbox ADD FOREIGN KEY (event_id) REFERENCES movie_events(event_id) ON DELETE CASCADE;
+ALTER TABLE webhook_deliveries RENAME COLUMN event_seq TO event_id;
+ALTER TABLE webhook_deliveries ADD FOREIGN KEY (event_id) REFERENCES movie_events(event_id) ON DELETE CASCADE;
+
+-- Deferred trigger runs right before commit. The first one of the transaction
+-- numbers all its events under advisory lock held until commit, so events
+-- become visible strictly in order of seq, and the lock is held only while
+-- the events are numbered instead of for the whole transaction.
+CREATE OR REPLACE FUNCTION sequence_movie_events() RETURNS trigger
+    LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF current_setting('movie_events.sequenced', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+    PERFORM set_config('movie_events.sequenced', 'on', true);
+
+    PERFORM pg_advisory_xact_lock(7236001);
+
+    -- Events of other transactions without seq are not committed yet, so they
+    -- are not visible here. Events of the transaction keep order of insertion.
+    UPDATE movie_events e SET seq = numbered.seq
+    FROM (
+        SELECT event_id, nextval('movie_events_seq_seq') AS seq
+        FROM (
+            SELECT event_id FROM movie_events
+            WHERE seq IS NULL AND event_id >= NEW.event_id
+            ORDER BY event_id
+        ) unsequenced
+    ) numbered
+    WHERE e.event_id = numbered.event_id;
+
+    PERFORM pg_notify('movie_events', '');
+
+    RETURN NULL;
+END
+$$;
+
+CREATE CONSTRAINT TRIGGER movie_events_sequence
+    AFTER INSERT ON movie_events
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION sequence_movie_events();
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS import_progress(
+    source TEXT PRIMARY KEY,
+    loaded INT NOT NULL,
+    last_id TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
\ No newline at end of file
@@ -0,0 +1,15 @@
+// Package migrations embeds database migrations into the binary, so they
+// don't depend on working directory of the service.
+package migrations
+
+import "embed"
+
+// Postgres holds migrations of postgres storage
+//
+//go:embed *.sql
+var Postgres embed.FS
+
+// SQLite holds migrations of sqlite storage in sqlite directory
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
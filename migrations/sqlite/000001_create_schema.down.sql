@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS movie_events;
+DROP TABLE IF EXISTS movie_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS collection_movies;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS watch_history;
+DROP TABLE IF EXISTS watchlist;
+DROP TABLE IF EXISTS reviews;
+DROP TABLE IF EXISTS ratings;
+DROP TABLE IF EXISTS movies;
\ No newline at end of file
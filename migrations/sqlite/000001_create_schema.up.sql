@@ -0,0 +1,126 @@
+CREATE TABLE IF NOT EXISTS movies(
+    movie_id TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    genre TEXT NOT NULL,
+    director TEXT NOT NULL,
+    year INTEGER NOT NULL CHECK (year > 0),
+    rating_sum INTEGER NOT NULL DEFAULT 0,
+    rating_count INTEGER NOT NULL DEFAULT 0,
+    review_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_movies_title ON movies (title, movie_id);
+
+CREATE TABLE IF NOT EXISTS ratings(
+    movie_id TEXT NOT NULL REFERENCES movies (movie_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    score INTEGER NOT NULL CHECK (score BETWEEN 1 AND 10),
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (movie_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS reviews(
+    review_id TEXT PRIMARY KEY,
+    movie_id TEXT NOT NULL REFERENCES movies (movie_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    text TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_movie_id_status_created_at ON reviews (movie_id, status, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS watchlist(
+    user_id TEXT NOT NULL,
+    movie_id TEXT NOT NULL,
+    added_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, movie_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_user_id_added_at ON watchlist (user_id, added_at DESC);
+
+CREATE TABLE IF NOT EXISTS watch_history(
+    entry_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    movie_id TEXT NOT NULL,
+    watched_at DATETIME NOT NULL,
+    progress INTEGER NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watch_history_user_id_watched_at ON watch_history (user_id, watched_at DESC);
+
+CREATE TABLE IF NOT EXISTS collections(
+    collection_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL CHECK (kind IN ('franchise', 'curated')),
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS collection_movies(
+    collection_id TEXT NOT NULL REFERENCES collections (collection_id) ON DELETE CASCADE,
+    movie_id TEXT NOT NULL REFERENCES movies (movie_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (collection_id, movie_id),
+    UNIQUE (collection_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_movies_movie_id ON collection_movies (movie_id);
+
+CREATE TABLE IF NOT EXISTS tags(
+    name TEXT PRIMARY KEY,
+    usage_count INTEGER NOT NULL DEFAULT 0 CHECK (usage_count >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS movie_tags(
+    movie_id TEXT NOT NULL REFERENCES movies (movie_id) ON DELETE CASCADE,
+    tag TEXT NOT NULL REFERENCES tags (name) ON DELETE CASCADE,
+    PRIMARY KEY (movie_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_tags_tag ON movie_tags (tag);
+
+CREATE TABLE IF NOT EXISTS movie_events(
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type TEXT NOT NULL CHECK (event_type IN ('created', 'updated', 'deleted')),
+    movie_id TEXT NOT NULL,
+    movie TEXT NOT NULL,
+    occurred_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS outbox(
+    event_seq INTEGER PRIMARY KEY REFERENCES movie_events (seq) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at) WHERE delivered_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhooks(
+    webhook_id TEXT PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '[]',
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+    delivery_id TEXT PRIMARY KEY,
+    webhook_id TEXT NOT NULL REFERENCES webhooks (webhook_id) ON DELETE CASCADE,
+    event_seq INTEGER NOT NULL REFERENCES movie_events (seq) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    response_status INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    next_attempt_at DATETIME NOT NULL,
+    delivered_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS movie_events_purged;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- Single row holding sequence number of the latest purged movie event, so
+-- readers resuming from older events learn they have missed some
+CREATE TABLE IF NOT EXISTS movie_events_purged(
+    purged_seq INTEGER NOT NULL
+);
+
+INSERT INTO movie_events_purged (purged_seq) VALUES (0);
\ No newline at end of file
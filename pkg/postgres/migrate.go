@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"movie-service/internal/config"
+	"movie-service/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+)
+
+// NewMigrate creates migrate instance with migrations embedded into the binary.
+// Changes of schema hold advisory lock of postgres, so replicas of the service
+// starting together migrate the database one by one. They wait for the lock
+// at most cfg.MigrateLockTimeout. Returned instance must be closed.
+func NewMigrate(cfg config.Postgres) (*migrate.Migrate, error) {
+	const op = "postgres.NewMigrate"
+
+	src, err := iofs.New(migrations.Postgres, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read embedded migrations: %w", op, err)
+	}
+
+	// Migrations build indexes, which may take longer than statement timeout
+	// of the service, and waiting for the lock must not be cut by it either
+	cfg.StatementTimeout = 0
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, ConnString(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create migrate instance: %w", op, err)
+	}
+	m.LockTimeout = cfg.MigrateLockTimeout
+
+	return m, nil
+}
+
+// Migrate applies all migrations which are not applied yet
+func Migrate(cfg config.Postgres) error {
+	const op = "postgres.Migrate"
+
+	m, err := NewMigrate(cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("%s: failed to perform db migrations: %w", op, err)
+	}
+
+	return nil
+}
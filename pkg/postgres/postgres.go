@@ -1,54 +1,178 @@
 package postgres
 
 import (
-	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"maps"
 	"movie-service/internal/config"
+	"movie-service/pkg/sl"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/golang-migrate/migrate/v4"
 	"github.com/jmoiron/sqlx"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+)
+
+const (
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
 
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	// maxConnectBackoff caps pause between attempts to connect at startup
+	maxConnectBackoff = 30 * time.Second
 )
 
-func New(cfg config.Postgres) (*sqlx.DB, error) {
-	const op = "postgres.New"
+// ConnString forms connection string of postgres. Statement timeout and
+// application name are set for every connection as runtime parameters.
+func ConnString(cfg config.Postgres) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.Port))),
+		Path:     cfg.Database,
+		RawQuery: connParams(cfg).Encode(),
+	}
 
-	// Form connection string
-	connString := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Database,
-	)
+	return u.String()
+}
+
+// ReplicaConnString adds parameters which ConnString sets for the primary to
+// connection string of the replica, so replicas are connected to with the
+// same TLS settings, statement timeout and application name. Parameters set
+// in dsn itself are kept. Both URL and key=value connection strings are
+// supported.
+func ReplicaConnString(cfg config.Postgres, dsn string) (string, error) {
+	params := connParams(cfg)
+	keys := slices.Sorted(maps.Keys(params))
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse connection string of replica: %w", err)
+		}
 
-	// Connect to postgres
-	conn, err := sqlx.Connect("postgres", connString)
+		query := u.Query()
+		for _, key := range keys {
+			if !query.Has(key) {
+				query.Set(key, params.Get(key))
+			}
+		}
+		u.RawQuery = query.Encode()
+
+		return u.String(), nil
+	}
+
+	// Values are quoted, so they may contain spaces and quotes
+	var b strings.Builder
+	b.WriteString(dsn)
+	for _, key := range keys {
+		if hasConnParam(dsn, key) {
+			continue
+		}
+
+		value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(params.Get(key))
+		fmt.Fprintf(&b, " %s='%s'", key, value)
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// connParams returns parameters of connection string of the primary
+func connParams(cfg config.Postgres) url.Values {
+	params := url.Values{}
+	params.Set("sslmode", cfg.SSLMode)
+	if cfg.SSLRootCert != "" {
+		params.Set("sslrootcert", cfg.SSLRootCert)
+	}
+	if cfg.ApplicationName != "" {
+		params.Set("application_name", cfg.ApplicationName)
+	}
+	if cfg.StatementTimeout > 0 {
+		params.Set("statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10))
+	}
+
+	return params
+}
+
+// hasConnParam reports whether key=value connection string sets the key
+func hasConnParam(dsn, key string) bool {
+	return regexp.MustCompile(`(^|\s)` + regexp.QuoteMeta(key) + `\s*=`).MatchString(dsn)
+}
+
+func New(cfg config.Postgres, log *slog.Logger) (*sqlx.DB, error) {
+	const op = "postgres.New"
+
+	// Open pool of connections, it doesn't connect to postgres yet
+	conn, err := sqlx.Open("postgres", ConnString(cfg))
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to connect to postgres: %w", op, err)
+		return nil, fmt.Errorf("%s: failed to open connection to postgres: %w", op, err)
 	}
+	ConfigurePool(conn, cfg)
 
 	// Ping db in order to check connection because successful connection creation
 	// guarantees nothing
-	if err := conn.Ping(); err != nil {
+	if err := ping(conn, cfg, log); err != nil {
+		MustClose(conn)
 		return nil, fmt.Errorf("%s: failed to ping postgres: %w", op, err)
 	}
 
-	// Migrations
-	m, err := migrate.New(
-		"file://migrations",
-		connString,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("%s: failed to create migrate instance: %w", op, err)
+	// Migrations are applied on startup unless they are run separately with
+	// migrate command
+	if cfg.AutoMigrate {
+		if err := Migrate(cfg); err != nil {
+			MustClose(conn)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
 	}
 
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return nil, fmt.Errorf("%s: failed to perform db migrations: %w", op, err)
+	return conn, nil
+}
+
+// ConfigurePool applies limits of connection pool from config
+func ConfigurePool(db *sqlx.DB, cfg config.Postgres) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+// ping checks connection to postgres, retrying cfg.ConnectRetries times with
+// growing pauses, since postgres may come up after the service, e.g. when they
+// are started by docker compose together
+func ping(db *sqlx.DB, cfg config.Postgres, log *slog.Logger) error {
+	backoff := cfg.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		err := db.Ping()
+		if err == nil || attempt >= cfg.ConnectRetries {
+			return err
+		}
+
+		log.Warn("postgres is not available, retrying",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoff),
+			sl.Err(err),
+		)
+		time.Sleep(backoff)
+		backoff = min(2*backoff, maxConnectBackoff)
 	}
+}
 
-	return conn, nil
+// NewListener creates dedicated connection for LISTEN/NOTIFY. Listener
+// reconnects by itself if connection is lost.
+func NewListener(cfg config.Postgres, log *slog.Logger) *pq.Listener {
+	return pq.NewListener(ConnString(cfg), listenerMinReconnect, listenerMaxReconnect,
+		func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Warn("postgres listener connection problem", slog.Int("event", int(ev)), sl.Err(err))
+			}
+		},
+	)
 }
 
 func MustClose(db *sqlx.DB) {
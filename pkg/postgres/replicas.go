@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"movie-service/internal/config"
+	"movie-service/pkg/sl"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// defaultReplicaCheckInterval is used if interval of health checks is not positive
+const defaultReplicaCheckInterval = 5 * time.Second
+
+// Replicas are read replicas of the database. They are health checked in
+// background, Pick hands out healthy ones in turn.
+type Replicas struct {
+	log      *slog.Logger
+	replicas []*replica
+	next     atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type replica struct {
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// NewReplicas opens connections to replicas from config and checks their
+// health every cfg.ReplicaCheckInterval, or every 5 seconds if it's not
+// positive. Replica which is down at start is not an error: it's used as soon
+// as it passes health check.
+func NewReplicas(cfg config.Postgres, log *slog.Logger) (*Replicas, error) {
+	const op = "postgres.NewReplicas"
+
+	r := &Replicas{
+		log:  log,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	for _, dsn := range cfg.Replicas {
+		connString, err := ReplicaConnString(cfg, dsn)
+		if err != nil {
+			r.closeAll()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		db, err := sqlx.Open("postgres", connString)
+		if err != nil {
+			r.closeAll()
+			return nil, fmt.Errorf("%s: failed to open connection to replica: %w", op, err)
+		}
+
+		ConfigurePool(db, cfg)
+
+		r.replicas = append(r.replicas, &replica{db: db})
+	}
+
+	interval := cfg.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = defaultReplicaCheckInterval
+	}
+
+	r.check(interval)
+	go r.run(interval)
+
+	return r, nil
+}
+
+// Pick returns healthy replica, nil if there is none
+func (r *Replicas) Pick() *sqlx.DB {
+	n := uint64(len(r.replicas))
+	start := r.next.Add(1)
+	for i := range n {
+		if replica := r.replicas[(start+i)%n]; replica.healthy.Load() {
+			return replica.db
+		}
+	}
+
+	return nil
+}
+
+// Close stops health checks and closes connections to replicas
+func (r *Replicas) Close() error {
+	close(r.stop)
+	<-r.done
+
+	return r.closeAll()
+}
+
+func (r *Replicas) closeAll() error {
+	var errs []error
+	for _, replica := range r.replicas {
+		if err := replica.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Replicas) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.check(interval)
+		}
+	}
+}
+
+// check pings all replicas at once, replica which doesn't respond within
+// timeout is unhealthy until the next check
+func (r *Replicas) check(timeout time.Duration) {
+	var wg sync.WaitGroup
+	for i, replica := range r.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			err := replica.db.PingContext(ctx)
+			healthy := err == nil
+			if replica.healthy.Swap(healthy) == healthy {
+				return
+			}
+
+			// Connection strings hold passwords, so replicas are told apart by index
+			if healthy {
+				r.log.Info("postgres replica is up", slog.Int("replica", i))
+			} else {
+				r.log.Warn("postgres replica is down", slog.Int("replica", i), sl.Err(err))
+			}
+		}()
+	}
+	wg.Wait()
+}
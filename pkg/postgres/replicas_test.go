@@ -0,0 +1,42 @@
+package postgres_test
+
+import (
+	"io"
+	"log/slog"
+	"movie-service/internal/config"
+	"movie-service/pkg/postgres"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReplicasDown(t *testing.T) {
+	// Nobody listens on the port once listener is closed
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find free port: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	// Interval which is not positive falls back to the default
+	for _, interval := range []time.Duration{time.Second, 0, -time.Second} {
+		t.Run(interval.String(), func(t *testing.T) {
+			replicas, err := postgres.NewReplicas(config.Postgres{
+				Replicas:             []string{"postgres://user:password@" + addr + "/db?sslmode=disable"},
+				ReplicaCheckInterval: interval,
+			}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			if err != nil {
+				t.Fatalf("NewReplicas error = %v", err)
+			}
+
+			if db := replicas.Pick(); db != nil {
+				t.Fatal("Pick returned replica which is down")
+			}
+
+			if err := replicas.Close(); err != nil {
+				t.Fatalf("Close error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"movie-service/internal/config"
+	"movie-service/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// ConnString forms connection string of sqlite database file. Foreign keys are
+// enforced, readers don't block the writer and every transaction takes the
+// write lock at once, so concurrent writers wait for each other instead of
+// failing on lock upgrade. Times are stored in the format sqlite itself uses.
+func ConnString(cfg config.SQLite) string {
+	return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(%d)&_txlock=immediate&_time_format=sqlite",
+		cfg.Path, cfg.BusyTimeout.Milliseconds(),
+	)
+}
+
+func New(cfg config.SQLite) (*sqlx.DB, error) {
+	const op = "sqlite.New"
+
+	conn, err := sqlx.Connect("sqlite", ConnString(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to open sqlite database: %w", op, err)
+	}
+
+	// Migrations
+	driver, err := migratesqlite.WithInstance(conn.DB, &migratesqlite.Config{})
+	if err != nil {
+		MustClose(conn)
+		return nil, fmt.Errorf("%s: failed to create migrate driver: %w", op, err)
+	}
+
+	src, err := iofs.New(migrations.SQLite, "sqlite")
+	if err != nil {
+		MustClose(conn)
+		return nil, fmt.Errorf("%s: failed to read embedded migrations: %w", op, err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "sqlite", driver)
+	if err != nil {
+		MustClose(conn)
+		return nil, fmt.Errorf("%s: failed to create migrate instance: %w", op, err)
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		MustClose(conn)
+		return nil, fmt.Errorf("%s: failed to perform db migrations: %w", op, err)
+	}
+
+	return conn, nil
+}
+
+func MustClose(db *sqlx.DB) {
+	const op = "sqlite.MustClose"
+
+	if err := db.Close(); err != nil {
+		log.Fatalf("%s: failed to close sqlite database: %v", op, err)
+	}
+}